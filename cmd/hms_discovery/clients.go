@@ -1,6 +1,6 @@
 // MIT License
 //
-// (C) Copyright [2023,2025] Hewlett Packard Enterprise Development LP
+// (C) Copyright [2023,2025-2026] Hewlett Packard Enterprise Development LP
 //
 // Permission is hereby granted, free of charge, to any person obtaining a
 // copy of this software and associated documentation files (the "Software"),
@@ -23,187 +23,78 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
-	"strings"
 
 	base "github.com/Cray-HPE/hms-base/v2"
+	"github.com/Cray-HPE/hms-discovery/pkg/plan"
+	"github.com/Cray-HPE/hms-discovery/pkg/sls"
 	sls_common "github.com/Cray-HPE/hms-sls/v2/pkg/sls-common"
-	rf "github.com/Cray-HPE/hms-smd/v2/pkg/redfish"
-	"github.com/Cray-HPE/hms-xname/xnametypes"
-	"github.com/hashicorp/go-retryablehttp"
 )
 
-var ErrNotFound = fmt.Errorf("not found")
-
-func buildRequestURL(baseURL, path string, params map[string]string) string {
-	url := fmt.Sprintf("%s/%s", baseURL, path)
-	if len(params) > 0 {
-		paramStrings := []string{}
-		for key, value := range params {
-			paramStrings = append(paramStrings, fmt.Sprintf("%s=%s", key, value))
-		}
-
-		url = fmt.Sprintf("%s?%s", url, strings.Join(paramStrings, "&"))
-	}
-
-	return url
-}
-
 //
 // SLS
 //
 
-func getSLSSearchHardware(ctx context.Context, params map[string]string) (map[string]sls_common.GenericHardware, error) {
-	url := buildRequestURL(*slsURL, "v1/search/hardware", params)
-
-	req, err := retryablehttp.NewRequestWithContext(ctx, "GET", url, nil)
+// searchSLS finds the hardware matching query in the SLS snapshot for the current run.
+func searchSLS(ctx context.Context, query sls.Query) (map[string]sls_common.GenericHardware, error) {
+	snapshot, err := slsClient.Snapshot(ctx)
 	if err != nil {
-		return nil, errors.Join(fmt.Errorf("failed to build GET request"), err)
+		return nil, errors.Join(fmt.Errorf("failed to load hardware from SLS"), err)
 	}
-	// base.SetHTTPUserAgent(req, insta)
 
-	response, err := httpClient.Do(req)
-	defer base.DrainAndCloseResponseBody(response)
+	return hardwareByXname(snapshot.Search(query)), nil
+}
+
+// getSLSSwitchConnectorsForNodeNic finds the switch connectors a NIC is cabled to in the SLS snapshot for the
+// current run.
+func getSLSSwitchConnectorsForNodeNic(ctx context.Context, xname string) (map[string]sls_common.GenericHardware,
+	error) {
+	snapshot, err := slsClient.Snapshot(ctx)
 	if err != nil {
-		return nil, errors.Join(fmt.Errorf("failed to perform GET request against SLS"), err)
+		return nil, errors.Join(fmt.Errorf("failed to load hardware from SLS"), err)
 	}
 
-	if response.StatusCode != 200 {
-		return nil, fmt.Errorf("unexpected status code %d expected 200", response.StatusCode)
-	}
+	return hardwareByXname(snapshot.SwitchConnectorsForNodeNic(xname)), nil
+}
 
-	var result []sls_common.GenericHardware
-	if err := json.NewDecoder(response.Body).Decode(&result); err != nil {
-		return nil, err
+// Convert to a map for ease of use
+func hardwareByXname(hardware []sls_common.GenericHardware) map[string]sls_common.GenericHardware {
+	hardwareMap := map[string]sls_common.GenericHardware{}
+	for _, item := range hardware {
+		hardwareMap[item.Xname] = item
 	}
 
-	// Convert to a map for ease of use
-	resultMap := map[string]sls_common.GenericHardware{}
-	for _, hardware := range result {
-		resultMap[hardware.Xname] = hardware
+	return hardwareMap
+}
+
+// getSLSFingerprint identifies the contents of SLS the current run is working from, so a plan can tell if it has
+// changed since the plan was made.
+func getSLSFingerprint(ctx context.Context) (string, error) {
+	snapshot, err := slsClient.Snapshot(ctx)
+	if err != nil {
+		return "", err
 	}
-	return resultMap, nil
+
+	return plan.Fingerprint(snapshot.All())
 }
 
 //
 // HSM
 //
 
+// getHSMStateComponents returns the components matching params keyed by xname.
 func getHSMStateComponents(ctx context.Context, params map[string]string) (map[string]base.Component, error) {
-	url := buildRequestURL(*hsmURL, "State/Components", params)
-
-	req, err := retryablehttp.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		return nil, errors.Join(fmt.Errorf("failed to build GET request"), err)
-	}
-	// base.SetHTTPUserAgent(req, insta)
-
-	response, err := httpClient.Do(req)
-	defer base.DrainAndCloseResponseBody(response)
+	components, err := hsmClient.GetComponents(ctx, params)
 	if err != nil {
-		return nil, errors.Join(fmt.Errorf("failed to perform GET request against HSM"), err)
-	}
-
-	if response.StatusCode != 200 {
-		return nil, fmt.Errorf("unexpected status code %d expected 200", response.StatusCode)
-	}
-
-	var result base.ComponentArray
-	if err := json.NewDecoder(response.Body).Decode(&result); err != nil {
 		return nil, err
 	}
 
 	// Convert to a map for ease of use
 	resultMap := map[string]base.Component{}
-	for _, component := range result.Components {
-		resultMap[component.ID] = *component
+	for _, component := range components {
+		resultMap[component.ID] = component
 	}
 
 	return resultMap, nil
 }
-
-func postHSMStateComponent(ctx context.Context, component base.Component) error {
-	if !xnametypes.IsHMSCompIDValid(component.ID) {
-		return fmt.Errorf("invalid component ID (%s)", component.ID)
-	}
-
-	payload := base.ComponentArray{
-		Components: []*base.Component{&component},
-	}
-
-	rawRequestBody, err := json.Marshal(payload)
-	if err != nil {
-		return errors.Join(fmt.Errorf("failed to marshal component object to json"), err)
-	}
-
-	url := fmt.Sprintf("%s/State/Components", *hsmURL)
-	req, err := retryablehttp.NewRequestWithContext(ctx, "POST", url, rawRequestBody)
-	if err != nil {
-		return errors.Join(fmt.Errorf("failed to build GET request"), err)
-	}
-	// base.SetHTTPUserAgent(request, sc.instanceName)
-
-	response, err := httpClient.Do(req)
-	defer base.DrainAndCloseResponseBody(response)
-	if err != nil {
-		return errors.Join(fmt.Errorf("failed to perform POST request against HSM"), err)
-	}
-
-	// If HSM sends back a response, then we should read the contents of the body so the Istio sidecar doesn't fill up
-	var responseString string
-	if response.Body != nil {
-		responseRaw, _ := io.ReadAll(response.Body)
-		responseString = string(responseRaw)
-	}
-
-	if response.StatusCode != 204 {
-		return fmt.Errorf("unexpected status code %d expected 204: %s", response.StatusCode, responseString)
-	}
-
-	return nil
-}
-
-func getHSMInventoryRedfishEndpoint(ctx context.Context, xname string) (rf.RedfishEPDescription, error) {
-	if !xnametypes.IsHMSCompIDValid(xname) {
-		return rf.RedfishEPDescription{}, fmt.Errorf("invalid component ID (%s)", xname)
-	}
-
-	url := fmt.Sprintf("%s/Inventory/RedfishEndpoints/%s", *hsmURL, xname)
-	req, err := retryablehttp.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		return rf.RedfishEPDescription{}, errors.Join(fmt.Errorf("failed to build GET request"), err)
-	}
-
-	// base.SetHTTPUserAgent(request, sc.instanceName)
-
-	response, err := httpClient.Do(req)
-	defer base.DrainAndCloseResponseBody(response)
-	if err != nil {
-		return rf.RedfishEPDescription{}, errors.Join(fmt.Errorf("failed to perform GET request against HSM"), err)
-	}
-
-	if response.StatusCode != 200 {
-		// If HSM sends back a response, then we should read the contents of the body so the Istio sidecar doesn't fill up
-		var responseString string
-		if response.Body != nil {
-			responseRaw, _ := io.ReadAll(response.Body)
-			responseString = string(responseRaw)
-		}
-
-		if response.StatusCode == 404 {
-			return rf.RedfishEPDescription{}, ErrNotFound
-		} else if response.StatusCode != 200 {
-			return rf.RedfishEPDescription{}, fmt.Errorf("unexpected status code %d expected 200: %s", response.StatusCode, responseString)
-		}
-	}
-
-	var result rf.RedfishEPDescription
-	if err := json.NewDecoder(response.Body).Decode(&result); err != nil {
-		return rf.RedfishEPDescription{}, err
-	}
-
-	return result, nil
-}
@@ -0,0 +1,161 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Cray-HPE/hms-discovery/pkg/config"
+	"github.com/Cray-HPE/hms-discovery/pkg/snmp_utilities"
+	"github.com/namsral/flag"
+)
+
+// applyConfigFile sets every flag that wasn't given on the command line or in the environment from the config file.
+func applyConfigFile() error {
+	if *configFile == "" {
+		return nil
+	}
+
+	fileConfig, err := config.Load(*configFile)
+	if err != nil {
+		return err
+	}
+
+	// Visit only covers flags that have been set, which includes those set from the environment.
+	alreadySet := map[string]bool{}
+	flag.Visit(func(f *flag.Flag) {
+		alreadySet[f.Name] = true
+	})
+
+	for name, value := range fileConfig.FlagValues() {
+		if alreadySet[name] {
+			continue
+		}
+
+		if err := flag.Set(name, value); err != nil {
+			return fmt.Errorf("failed to apply %s from config file: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// effectiveConfig is what discovery is actually running with, wherever each setting came from.
+func effectiveConfig() (config.Config, error) {
+	return config.FromFlags(func(name string) string {
+		if f := flag.Lookup(name); f != nil {
+			return f.Value.String()
+		}
+
+		return ""
+	})
+}
+
+// setupConfig merges the config file into the flags and makes sure the result is usable.
+func setupConfig() error {
+	if err := applyConfigFile(); err != nil {
+		return err
+	}
+
+	effective, err := effectiveConfig()
+	if err != nil {
+		return err
+	}
+	if err := effective.Validate(); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	snmp_utilities.Retries = uint(*snmpRetries)
+	snmp_utilities.MaxRepetitions = *snmpMaxRepetitions
+	snmp_utilities.Timeout = *snmpTimeout
+	if err := snmp_utilities.SetPortNamePrefixes(*snmpPortNamePrefixes); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	return nil
+}
+
+// runConfigCommand handles the config commands, which only look at configuration so need nothing else set up:
+//
+//	hms_discovery config validate [config file]
+//	hms_discovery config show [--effective] [-o yaml|json]
+func runConfigCommand(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "Usage: hms_discovery config validate|show")
+		return 2
+	}
+
+	switch args[0] {
+	case "validate":
+		validateFlags := flag.NewFlagSetWithEnvPrefix("config validate", "CONFIG_VALIDATE", flag.ExitOnError)
+		validateFlags.Parse(args[1:])
+
+		if validateFlags.NArg() > 0 {
+			*configFile = validateFlags.Arg(0)
+		}
+
+		if err := setupConfig(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+
+		fmt.Println("Configuration is valid.")
+		return 0
+
+	case "show":
+		showFlags := flag.NewFlagSetWithEnvPrefix("config show", "CONFIG_SHOW", flag.ExitOnError)
+		effective := showFlags.Bool("effective", false,
+			"Show the configuration in effect after applying flags, environment variables and defaults")
+		format := showFlags.String("o", "yaml", "Output format: yaml or json")
+		showFlags.Parse(args[1:])
+
+		var shown config.Config
+		var err error
+		if *effective {
+			if err = setupConfig(); err == nil {
+				shown, err = effectiveConfig()
+			}
+		} else if *configFile == "" {
+			err = fmt.Errorf("no config file given, set config_file or use --effective")
+		} else {
+			shown, err = config.Load(*configFile)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+
+		rendered, err := shown.Render(*format)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+
+		fmt.Println(string(rendered))
+		return 0
+	}
+
+	fmt.Fprintf(os.Stderr, "Unknown config command: %s\n", args[0])
+	return 2
+}
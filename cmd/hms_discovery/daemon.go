@@ -0,0 +1,288 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/Cray-HPE/hms-discovery/pkg/discovery"
+	"go.uber.org/zap"
+)
+
+// phaseSchedule is how a built in phase is switched on and off, how often it runs in daemon mode and how long it is
+// allowed to take.
+type phaseSchedule struct {
+	enabled  *bool
+	interval *time.Duration
+	timeout  *time.Duration
+}
+
+var phaseSchedules = map[string]phaseSchedule{
+	"management_virtual_nodes": {
+		enabled:  discoverManagementVirtualNodes,
+		interval: managementVirtualNodeDiscoveryInterval,
+		timeout:  managementVirtualNodeDiscoveryTimeout,
+	},
+	"management_nodes": {
+		enabled:  discoverManagementNodes,
+		interval: managementNodeDiscoveryInterval,
+		timeout:  managementNodeDiscoveryTimeout,
+	},
+	"management_switch_credentials": {
+		enabled:  populateManagementSwitchCredentials,
+		interval: managementSwitchCredentialsInterval,
+		timeout:  managementSwitchCredentialsTimeout,
+	},
+	"river": {
+		enabled:  discoverRiver,
+		interval: riverDiscoveryInterval,
+		timeout:  riverDiscoveryTimeout,
+	},
+	"mountain": {
+		enabled:  discoverMountain,
+		interval: mountainDiscoveryInterval,
+		timeout:  mountainDiscoveryTimeout,
+	},
+	"rediscover_failed_redfish_endpoints": {
+		enabled:  rediscoverFailedRedfishEndpoints,
+		interval: rediscoveryInterval,
+		timeout:  rediscoveryTimeout,
+	},
+}
+
+// The built in phases are registered in the order they have always been run in. Site specific phases can be added by
+// registering them from an init function in their own file, they are always enabled and use phase_interval and
+// phase_timeout.
+func init() {
+	// River discovery walks the switches with the credentials populated for them.
+	// Rediscovery goes after every phase that creates Redfish endpoints so it doesn't retry ones that are brand new.
+	builtinPhases := []discovery.Discoverer{
+		discovery.NewDiscoverer("management_virtual_nodes", nil, doManagementVirtualNodeDiscovery),
+		discovery.NewDiscoverer("management_nodes", nil, doManagementNodeDiscovery),
+		discovery.NewDiscoverer("management_switch_credentials", nil, doManagementSwitchCredentials),
+		discovery.NewDiscoverer("river", []string{"management_switch_credentials"}, doRiverDiscovery),
+		discovery.NewDiscoverer("mountain", nil, doMountainDiscovery),
+		discovery.NewDiscoverer("rediscover_failed_redfish_endpoints",
+			[]string{"management_nodes", "river", "mountain"}, doRediscoverFailedRedfishEndpoints),
+	}
+
+	for _, phase := range builtinPhases {
+		discovery.MustRegister(phase)
+	}
+}
+
+func phaseEnabled(name string) bool {
+	if schedule, found := phaseSchedules[name]; found {
+		return *schedule.enabled
+	}
+
+	return true
+}
+
+func phaseIntervalFor(name string) time.Duration {
+	if schedule, found := phaseSchedules[name]; found {
+		return *schedule.interval
+	}
+
+	return *phaseInterval
+}
+
+// enabledPhases returns every registered phase that is enabled, in the order they were registered.
+func enabledPhases() []discovery.Discoverer {
+	var phases []discovery.Discoverer
+	for _, phase := range discovery.DefaultRegistry.All() {
+		if phaseEnabled(phase.Name()) {
+			phases = append(phases, phase)
+		}
+	}
+
+	return phases
+}
+
+func newPhaseRunner() discovery.Runner {
+	timeouts := map[string]time.Duration{}
+	for name, schedule := range phaseSchedules {
+		timeouts[name] = *schedule.timeout
+	}
+
+	return discovery.Runner{
+		DefaultTimeout: *phaseTimeout,
+		Timeouts:       timeouts,
+		OnStart:        phaseStarted,
+		OnFinish:       phaseFinished,
+	}
+}
+
+func phaseStarted(name string, start time.Time) {
+	logger.Debug("Starting discovery phase.", zap.String("phase", name))
+	recordPhaseStart(name, start)
+}
+
+func phaseFinished(result discovery.Result) {
+	phaseLogger := logger.With(zap.String("phase", result.Name))
+
+	recordPhaseEnd(result.Name, result.Finished, result.Err)
+	runReport.AddPhase(result.Name, result.Started, result.Finished, result.Err)
+
+	switch {
+	case result.Skipped:
+		phaseLogger.Warn("Skipped discovery phase.", zap.Error(result.Err))
+		phaseRuns.WithLabelValues(result.Name, "skipped").Inc()
+		return
+	case result.Failed():
+		phaseLogger.Error("Discovery phase failed!", zap.Error(result.Err))
+		phaseRuns.WithLabelValues(result.Name, "failure").Inc()
+	default:
+		phaseRuns.WithLabelValues(result.Name, "success").Inc()
+		phaseLastSuccess.WithLabelValues(result.Name).Set(float64(result.Finished.Unix()))
+	}
+
+	phaseDuration.WithLabelValues(result.Name).Observe(result.Duration().Seconds())
+	phaseLogger.Debug("Finished discovery phase.", zap.Duration("duration", result.Duration()))
+}
+
+// runPhases runs the given phases as part of the current run, phases that don't depend on each other are run at the
+// same time. When detach is set, cancelling ctx only stops phases that haven't started yet.
+func runPhases(ctx context.Context, phases []discovery.Discoverer, detach bool) {
+	runner := newPhaseRunner()
+	runner.Detach = detach
+
+	if _, err := runner.Run(runContext(ctx), phases); err != nil {
+		logger.Error("Unable to run discovery phases!", zap.Error(err))
+		runReport.AddPhase("runner", time.Now(), time.Now(), err)
+	}
+}
+
+// nextRunTime computes when a phase should run next, adding up to scheduleJitter of random delay so phases with the
+// same interval don't all fire at once and so multiple replicas don't hammer SLS/HSM in lock step.
+func nextRunTime(name string) time.Time {
+	delay := phaseIntervalFor(name)
+	if *scheduleJitter > 0 {
+		delay += time.Duration(rand.Int63n(int64(*scheduleJitter)))
+	}
+
+	return time.Now().Add(delay)
+}
+
+// runDaemon runs every enabled phase on its own interval until ctx is cancelled. Phases that are due at the same time
+// are run together, and phases that are in progress when ctx is cancelled are allowed to finish before returning. Runs
+// requested through the control API are run as soon as the current phases are done and push back the next scheduled
+// run of those phases.
+func runDaemon(ctx context.Context) {
+	var scheduledPhases []discovery.Discoverer
+	nextRun := map[string]time.Time{}
+	for _, phase := range enabledPhases() {
+		if phaseIntervalFor(phase.Name()) <= 0 {
+			logger.Warn("Phase has a non-positive interval, not scheduling it.",
+				zap.String("phase", phase.Name()), zap.Duration("interval", phaseIntervalFor(phase.Name())))
+			continue
+		}
+
+		scheduledPhases = append(scheduledPhases, phase)
+		nextRun[phase.Name()] = time.Now()
+	}
+
+	if len(scheduledPhases) == 0 {
+		logger.Warn("No discovery phases are enabled, nothing to schedule.")
+		return
+	}
+
+	logger.Info("Running in daemon mode.", zap.Duration("scheduleJitter", *scheduleJitter))
+
+	for {
+		next := nextRun[scheduledPhases[0].Name()]
+		for _, phase := range scheduledPhases[1:] {
+			if nextRun[phase.Name()].Before(next) {
+				next = nextRun[phase.Name()]
+			}
+		}
+
+		var toRun []discovery.Discoverer
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			logger.Info("Received shutdown signal, no longer scheduling discovery phases.")
+			return
+		case request := <-runRequests:
+			timer.Stop()
+			toRun = requestedPhases(scheduledPhases, request)
+			logger.Info("Running discovery phases on request.", zap.Strings("phases", request.phases))
+		case <-timer.C:
+			// Everything that is due goes together so phases that depend on each other run in order.
+			now := time.Now()
+			for _, phase := range scheduledPhases {
+				if !nextRun[phase.Name()].After(now) {
+					toRun = append(toRun, phase)
+				}
+			}
+		}
+
+		startRun()
+		// Phases in progress when the shutdown signal comes in get to finish cleanly rather than leaving HSM or Vault
+		// half updated, anything that hasn't started yet is skipped.
+		runPhases(ctx, toRun, true)
+		for _, phase := range toRun {
+			if _, scheduled := nextRun[phase.Name()]; scheduled {
+				nextRun[phase.Name()] = nextRunTime(phase.Name())
+				logger.Debug("Scheduled next run of discovery phase.",
+					zap.String("phase", phase.Name()), zap.Time("nextRun", nextRun[phase.Name()]))
+			}
+		}
+		finishRun()
+
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// requestedPhases resolves a run request into the phases to run. Asking for every phase only runs the scheduled ones,
+// asking for a phase by name runs it even when it is not enabled for scheduled runs.
+func requestedPhases(scheduledPhases []discovery.Discoverer, request runRequest) []discovery.Discoverer {
+	if request.phases == nil {
+		return scheduledPhases
+	}
+
+	var toRun []discovery.Discoverer
+	for _, name := range request.phases {
+		if phase, found := discovery.DefaultRegistry.Get(name); found {
+			toRun = append(toRun, phase)
+		}
+	}
+
+	return toRun
+}
+
+// validatePhases makes sure the registered phases can be run, which is only worth checking once at startup.
+func validatePhases() error {
+	if err := discovery.DefaultRegistry.Validate(); err != nil {
+		return fmt.Errorf("invalid discovery phases: %w", err)
+	}
+
+	return nil
+}
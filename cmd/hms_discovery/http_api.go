@@ -0,0 +1,149 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Cray-HPE/hms-discovery/pkg/discovery"
+	"go.uber.org/zap"
+)
+
+/*
+The control API is only served in daemon mode. It lets operators kick off a run without waiting for the next
+interval, see what the last runs did without digging through pod logs, and turn up logging on a live pod.
+
+	GET  /v1/liveness
+	GET  /v1/readiness
+	GET  /v1/status
+	POST /v1/run
+	POST /v1/run/{phase}
+	GET  /v1/loglevel
+	PUT  /v1/loglevel
+	GET  /metrics
+*/
+
+// runRequest asks the daemon to run the named phases right away. A nil list means every enabled phase.
+type runRequest struct {
+	phases []string
+}
+
+// Room for a handful of queued requests, beyond that the caller is told to try again later.
+var runRequests = make(chan runRequest, 8)
+
+type apiError struct {
+	Error string `json:"Error"`
+}
+
+func sendJSON(w http.ResponseWriter, statusCode int, payload interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	if err := json.NewEncoder(w).Encode(payload); err != nil {
+		logger.Error("Failed to encode HTTP response!", zap.Error(err))
+	}
+}
+
+func livenessHandler(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func readinessHandler(w http.ResponseWriter, r *http.Request) {
+	if !isReady() {
+		sendJSON(w, http.StatusServiceUnavailable, apiError{Error: "not ready"})
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func statusHandler(w http.ResponseWriter, r *http.Request) {
+	sendJSON(w, http.StatusOK, getStatus())
+}
+
+func queueRunRequest(w http.ResponseWriter, request runRequest) {
+	select {
+	case runRequests <- request:
+		sendJSON(w, http.StatusAccepted, request.phases)
+	default:
+		sendJSON(w, http.StatusTooManyRequests, apiError{Error: "too many queued run requests"})
+	}
+}
+
+func runAllHandler(w http.ResponseWriter, r *http.Request) {
+	queueRunRequest(w, runRequest{})
+}
+
+func runPhaseHandler(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("phase")
+	if _, found := discovery.DefaultRegistry.Get(name); !found {
+		sendJSON(w, http.StatusNotFound, apiError{Error: "unknown phase: " + name})
+		return
+	}
+
+	queueRunRequest(w, runRequest{phases: []string{name}})
+}
+
+func newAPIHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/liveness", livenessHandler)
+	mux.HandleFunc("GET /v1/readiness", readinessHandler)
+	mux.HandleFunc("GET /v1/status", statusHandler)
+	mux.HandleFunc("POST /v1/run", runAllHandler)
+	mux.HandleFunc("POST /v1/run/{phase}", runPhaseHandler)
+	mux.Handle("/v1/loglevel", atomicLevel)
+	mux.Handle("GET /metrics", metricsHandler())
+
+	return mux
+}
+
+// startAPIServer serves the control API until ctx is cancelled.
+func startAPIServer(ctx context.Context, listenAddress string) {
+	server := &http.Server{
+		Addr:              listenAddress,
+		Handler:           newAPIHandler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		logger.Info("Starting control API.", zap.String("listenAddress", listenAddress))
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("Control API stopped unexpectedly!", zap.Error(err))
+		}
+	}()
+
+	go func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			logger.Error("Failed to shutdown control API cleanly!", zap.Error(err))
+		}
+	}()
+}
@@ -1,6 +1,6 @@
 // MIT License
 //
-// (C) Copyright [2020-2022,2025] Hewlett Packard Enterprise Development LP
+// (C) Copyright [2020-2022,2025-2026] Hewlett Packard Enterprise Development LP
 //
 // Permission is hereby granted, free of charge, to any person obtaining a
 // copy of this software and associated documentation files (the "Software"),
@@ -23,24 +23,27 @@
 package main
 
 import (
-	"bytes"
 	"context"
 	"crypto/tls"
-	"encoding/json"
 	"fmt"
-	"io/ioutil"
 	"net/http"
 	"os"
+	"os/signal"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 
-	base "github.com/Cray-HPE/hms-base/v2"
 	compcredentials "github.com/Cray-HPE/hms-compcredentials"
 	"github.com/Cray-HPE/hms-discovery/internal/http_logger"
+	"github.com/Cray-HPE/hms-discovery/pkg/discovery"
+	"github.com/Cray-HPE/hms-discovery/pkg/hsm"
+	"github.com/Cray-HPE/hms-discovery/pkg/journal"
 	"github.com/Cray-HPE/hms-discovery/pkg/pdu_credential_store"
+	"github.com/Cray-HPE/hms-discovery/pkg/plan"
+	"github.com/Cray-HPE/hms-discovery/pkg/report"
+	"github.com/Cray-HPE/hms-discovery/pkg/sls"
 	"github.com/Cray-HPE/hms-discovery/pkg/switches"
-	dns_dhcp "github.com/Cray-HPE/hms-dns-dhcp/pkg"
 	securestorage "github.com/Cray-HPE/hms-securestorage"
 	rf "github.com/Cray-HPE/hms-smd/v2/pkg/redfish"
 	"github.com/Cray-HPE/hms-smd/v2/pkg/sm"
@@ -58,17 +61,127 @@ var (
 	capmcURL = flag.String("capmc_url", "http://cray-capmc",
 		"CAPMC URL")
 
+	configFile = flag.String("config_file", "",
+		"YAML or JSON file to read settings from, flags and environment variables take precedence over it")
+	logLevel = flag.String("log_level", "INFO",
+		"Log level: DEBUG, INFO, WARN, ERROR, FATAL or PANIC")
+
+	httpRetryMax = flag.Int("http_retry_max", 2,
+		"Number of times to retry HTTP requests")
+	httpRetryWaitMax = flag.Duration("http_retry_wait_max", 2*time.Second,
+		"Longest to wait between HTTP request retries")
+
+	vaultBasePath = flag.String("vault_base_path", "",
+		"Vault path all secrets are kept under, leave empty for the secure storage default")
+	compCredentialsVaultPath = flag.String("comp_credentials_vault_path", "hms-creds",
+		"Vault path BMC and switch credentials are stored under")
+	redsCredentialsVaultPath = flag.String("reds_credentials_vault_path", "reds-creds",
+		"Vault path default BMC and switch credentials are read from")
+	pduCredentialsVaultPath = flag.String("pdu_credentials_vault_path", "pdu-creds",
+		"Vault path PDU credentials are stored under")
+
+	snmpMode = flag.String("snmp_mode", "",
+		"Set to mock to use canned switch data instead of walking the management switches, record to walk them and "+
+			"save the walks to snmp_fixture_dir, or replay to play back the walks saved there")
+	snmpMockDir = flag.String("snmp_mock_dir", "configs",
+		"Directory the canned switch data used in mock SNMP mode is read from")
+	snmpFixtureDir = flag.String("snmp_fixture_dir", "snmp_fixtures",
+		"Directory a fixture of the SNMP walks of each switch is saved to in record mode and read from in replay mode")
+	snmpRetries = flag.Int("snmp_retries", 5,
+		"Number of times to retry SNMP requests to management switches")
+	snmpConcurrency = flag.Int("snmp_concurrency", 10,
+		"Number of management switches to collect MAC address tables from at the same time")
+	snmpMaxRepetitions = flag.Int("snmp_max_repetitions", 10,
+		"Number of table rows to ask a management switch for in each request of a bulk walk")
+	snmpTimeout = flag.Duration("snmp_timeout", 5*time.Second,
+		"Longest to wait for a management switch to respond to each SNMP request")
+	snmpPortNamePrefixes = flag.String("snmp_port_name_prefixes", "",
+		"Interface type prefixes to ignore when matching switch port names with SLS, per vendor profile, such as "+
+			"dell-os10=ethernet,eth;cisco-nxos=ethernet,eth,e. Profiles not listed keep their built in prefixes")
+	snmpSwitches = flag.String("snmp_switches",
+		"comptype_mgmt_switch,comptype_hl_switch,comptype_cdu_mgmt_switch",
+		"SLS types of the management switches River discovery collects MAC address tables from, optionally "+
+			"limited to a class such as comptype_mgmt_switch:River,comptype_cdu_mgmt_switch:Mountain")
+	snmpARP = flag.Bool("snmp_arp", false,
+		"Also collect the ARP tables of the management switches, so devices with an IP address on an edge port that "+
+			"HSM has no EthernetInterface for, such as BMCs with a static IP address, are discovered too")
+
+	offlineMode = flag.Bool("offline", false,
+		"Run discovery against offline_sls_file, offline_hsm_file and the switch data in snmp_mock_dir (or "+
+			"snmp_fixture_dir with snmp_mode=replay) instead of the live services, implies dry_run")
+	offlineSLSFile = flag.String("offline_sls_file", "",
+		"SLS dump to read SLS from in offline mode")
+	offlineHSMFile = flag.String("offline_hsm_file", "",
+		"HSM snapshot of Components, RedfishEndpoints and EthernetInterfaces to read HSM from in offline mode")
+
 	discoverRiver = flag.Bool("discover_river", true, "Discover River nodes?")
 
 	discoverMountain        = flag.Bool("discover_mountain", true, "Discover Mountain nodes?")
 	mountainDiscoveryScript = flag.String("mountain_discovery_script", "mountain_discovery.py",
 		"Location of the script to give Python to run for Mountain discovery.")
+	mountainSleepLength = flag.Duration("mountain_sleep_length", 30*time.Second,
+		"How long the Mountain discovery script waits between its steps, in whole seconds")
 
 	discoverManagementVirtualNodes      = flag.Bool("discover_management_virtual_nodes", true, "Discover Management Virtual nodes")
 	discoverManagementNodes             = flag.Bool("discover_management_nodes", true, "Discover Management Nodes")
 	rediscoverFailedRedfishEndpoints    = flag.Bool("rediscover_failed_redfish_endpoints", true, "Rediscover Failed Redfish Endpoints")
 	populateManagementSwitchCredentials = flag.Bool("populate_management_switch_credentials", true, "Populate management switch credentials")
 
+	daemonMode = flag.Bool("daemon", false,
+		"Stay resident and run each discovery phase on its own interval instead of once")
+	httpListen = flag.String("http_listen", ":8080",
+		"Address the control API listens on in daemon mode, leave empty to disable it")
+	pushgatewayURL = flag.String("pushgateway_url", "",
+		"Pushgateway compatible URL to push metrics to at the end of a one-shot run")
+
+	reportPath = flag.String("report_path", "",
+		"File to write the report for each run to, leave empty to not write one")
+	reportFormat = flag.String("report_format", report.FormatJSON,
+		"Format of the report written to report_path: json, yaml or csv")
+	reportURL = flag.String("report_url", "",
+		"URL to POST the JSON report for each run to, leave empty to not send one")
+
+	dryRun = flag.Bool("dry_run", false,
+		"Work out what would be changed in HSM and Vault and print it instead of making the changes")
+	planPath = flag.String("plan_path", "",
+		"File to write the JSON plan of each dry run to, leave empty to not write one")
+	journalPath = flag.String("journal_path", "hms-discovery-journal",
+		"Vault path every change made to HSM and Vault is journaled under so a run can be rolled back")
+	journalRetention = flag.Duration("journal_retention", 30*24*time.Hour,
+		"How long the journal of a run is kept before it is pruned, 0 to keep every run")
+
+	phaseTimeout = flag.Duration("phase_timeout", 0,
+		"Longest any discovery phase is allowed to run for unless it has its own timeout, 0 for no limit")
+	phaseInterval = flag.Duration("phase_interval", 5*time.Minute,
+		"Interval between runs in daemon mode of any phase that doesn't have its own interval")
+
+	managementVirtualNodeDiscoveryInterval = flag.Duration("management_virtual_node_discovery_interval", 5*time.Minute,
+		"Interval between Management Virtual node discovery runs in daemon mode")
+	managementNodeDiscoveryInterval = flag.Duration("management_node_discovery_interval", 5*time.Minute,
+		"Interval between Management node discovery runs in daemon mode")
+	managementSwitchCredentialsInterval = flag.Duration("management_switch_credentials_interval", 15*time.Minute,
+		"Interval between Management switch credential population runs in daemon mode")
+	riverDiscoveryInterval = flag.Duration("river_discovery_interval", 3*time.Minute,
+		"Interval between River discovery runs in daemon mode")
+	mountainDiscoveryInterval = flag.Duration("mountain_discovery_interval", 3*time.Minute,
+		"Interval between Mountain discovery runs in daemon mode")
+	rediscoveryInterval = flag.Duration("rediscovery_interval", 3*time.Minute,
+		"Interval between rediscovery of failed Redfish endpoints in daemon mode")
+	managementVirtualNodeDiscoveryTimeout = flag.Duration("management_virtual_node_discovery_timeout", 0,
+		"Longest Management Virtual node discovery is allowed to run for, 0 to use phase_timeout")
+	managementNodeDiscoveryTimeout = flag.Duration("management_node_discovery_timeout", 0,
+		"Longest Management node discovery is allowed to run for, 0 to use phase_timeout")
+	managementSwitchCredentialsTimeout = flag.Duration("management_switch_credentials_timeout", 0,
+		"Longest Management switch credential population is allowed to run for, 0 to use phase_timeout")
+	riverDiscoveryTimeout = flag.Duration("river_discovery_timeout", 0,
+		"Longest River discovery is allowed to run for, 0 to use phase_timeout")
+	mountainDiscoveryTimeout = flag.Duration("mountain_discovery_timeout", 0,
+		"Longest Mountain discovery is allowed to run for, 0 to use phase_timeout")
+	rediscoveryTimeout = flag.Duration("rediscovery_timeout", 0,
+		"Longest rediscovery of failed Redfish endpoints is allowed to run for, 0 to use phase_timeout")
+	scheduleJitter = flag.Duration("schedule_jitter", 30*time.Second,
+		"Maximum random delay added to each phase interval in daemon mode")
+
 	httpClient *retryablehttp.Client
 
 	atomicLevel zap.AtomicLevel
@@ -79,39 +192,49 @@ var (
 	redsCredentialStore *switches.RedsCredStore
 	pduCredentialStore  *pdu_credential_store.PDUCredentialStore
 
-	dhcpdnsClient dns_dhcp.DNSDHCPHelper
+	slsClient       *sls.Client
+	hsmClient       *hsm.Client
+	discoveryClient *discovery.Discovery
 )
 
-type RedfishEndpointArray struct {
-	RedfishEndpoints []rf.RedfishEPDescription `json:"RedfishEndpoints"`
-}
-
 func setupVault() error {
-	var err error
-	secureStorage, err = securestorage.NewVaultAdapter(os.Getenv("VAULT_BASE_PATH"))
+	vaultAdapter, err := securestorage.NewVaultAdapter(*vaultBasePath)
 	if err != nil {
 		return err
 	}
-
-	hsmCredentialStore = compcredentials.NewCompCredStore("hms-creds", secureStorage)
-	redsCredentialStore = switches.NewRedsCredStore("reds-creds", secureStorage)
-	pduCredentialStore = pdu_credential_store.NewPDUCredStore("pdu-creds", secureStorage)
+	setupCredentialStores(instrumentedSecureStorage{vaultAdapter})
 
 	return nil
 }
 
+// setupCredentialStores builds every credential store, and the journal, on top of storage.
+func setupCredentialStores(storage securestorage.SecureStorage) {
+	secureStorage = storage
+
+	hsmCredentialStore = compcredentials.NewCompCredStore(*compCredentialsVaultPath, secureStorage)
+	redsCredentialStore = switches.NewRedsCredStore(*redsCredentialsVaultPath, secureStorage)
+	pduCredentialStore = pdu_credential_store.NewPDUCredStore(*pduCredentialsVaultPath, secureStorage)
+	mutationJournal = journal.NewJournal(*journalPath, secureStorage)
+}
+
 func setupLogging() {
-	logLevel := os.Getenv("LOG_LEVEL")
-	logLevel = strings.ToUpper(logLevel)
+	logLevel := strings.ToUpper(*logLevel)
 
 	atomicLevel = zap.NewAtomicLevel()
 
+	// A daemon has to outlive any one phase, so a Fatal log panics instead of exiting and the runner fails the phase
+	// it was logged in.
+	var options []zap.Option
+	if *daemonMode {
+		options = append(options, zap.WithFatalHook(zapcore.WriteThenPanic))
+	}
+
 	encoderCfg := zap.NewProductionEncoderConfig()
 	logger = zap.New(zapcore.NewCore(
 		zapcore.NewJSONEncoder(encoderCfg),
 		zapcore.Lock(os.Stdout),
 		atomicLevel,
-	))
+	), options...)
 
 	switch logLevel {
 	case "DEBUG":
@@ -131,37 +254,17 @@ func setupLogging() {
 	}
 }
 
-func getNotDiscoveredOKEndpointFromHSM() (notDiscoveredEndpoints []rf.RedfishEPDescription) {
-	url := fmt.Sprintf("%s/Inventory/RedfishEndpoints", *hsmURL)
-
-	response, err := httpClient.Get(url)
-	defer base.DrainAndCloseResponseBody(response)
-	if err != nil {
-		logger.Error("Failed to get RedfishEndpoints from HSM!", zap.Error(err))
-		return
-	}
-
-	if response.StatusCode != http.StatusOK {
-		logger.Error("Unexpected status code from HSM!", zap.Int("response.StatusCode", response.StatusCode))
-		return
-	}
-
-	jsonBytes, err := ioutil.ReadAll(response.Body)
-	if err != nil {
-		logger.Error("Failed to read body!", zap.Error(err))
-		return
-	}
-
-	var allEndpoints RedfishEndpointArray
-	err = json.Unmarshal(jsonBytes, &allEndpoints)
+func getNotDiscoveredOKEndpointFromHSM(ctx context.Context) (notDiscoveredEndpoints []rf.RedfishEPDescription,
+	err error) {
+	allEndpoints, err := hsmClient.GetRedfishEndpoints(ctx, nil)
 	if err != nil {
-		logger.Error("Failed to unmarshal HSM Redfish endpoints JSON!", zap.Error(err))
+		err = fmt.Errorf("failed to get RedfishEndpoints from HSM: %w", err)
 		return
 	}
 
 	// Now we have all the endpoints, loop through them all looking only for those that are not DiscoveredOK
 	// (or actively being discovered).
-	for _, endpoint := range allEndpoints.RedfishEndpoints {
+	for _, endpoint := range allEndpoints {
 		if endpoint.DiscInfo.LastStatus != rf.DiscoverOK &&
 			endpoint.DiscInfo.LastStatus != rf.DiscoveryStarted {
 			logger.Debug("Found Redfish endpoint that was not DiscoveredOK/DiscoveryStarted.", zap.Any("endpoint", endpoint))
@@ -175,50 +278,155 @@ func getNotDiscoveredOKEndpointFromHSM() (notDiscoveredEndpoints []rf.RedfishEPD
 	return
 }
 
-func reDiscoverEndpoints(endpoints []string) {
+func reDiscoverEndpoints(ctx context.Context, endpoints []string) error {
+	if *dryRun {
+		for _, endpoint := range endpoints {
+			err := recordMutation("rediscover_failed_redfish_endpoints", plan.OpRediscover, endpoint, nil,
+				sm.DiscoverIn{XNames: []string{endpoint}}, nil)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
 	// Cool, some endpoints appear reachable that aren't yet discovered, let's have HSM give them a go.
-	discover := sm.DiscoverIn{
-		XNames: endpoints,
-		Force:  false,
+	potentialLogger := logger.With(zap.Strings("potentiallyDiscoverableEndpoints", endpoints))
+	if err := hsmClient.Discover(ctx, endpoints, false); err != nil {
+		rediscoveryAttempts.WithLabelValues("failed").Add(float64(len(endpoints)))
+		potentialLogger.Error("Failed to have HSM re-discover previously failed endpoints!", zap.Error(err))
+		return err
 	}
 
-	payloadBytes, marshalErr := json.Marshal(discover)
-	if marshalErr != nil {
-		logger.Error("Failed to marshal HSM discover object!", zap.Error(marshalErr))
-		return
+	rediscoveryAttempts.WithLabelValues("requested").Add(float64(len(endpoints)))
+	potentialLogger.Info("Told HSM to re-discover previously failed endpoints.")
+
+	return nil
+}
+
+func doRediscoverFailedRedfishEndpoints(ctx context.Context) error {
+	// At this point we should take advantage of the fact that we know all this information about the system and try
+	// to fix any discovery attempts that have gone poorly.
+	var potentiallyDiscoverableEndpoints []string
+	var potentiallyDiscoverableLock sync.Mutex
+
+	notDiscoveredOKEndpoints, err := getNotDiscoveredOKEndpointFromHSM(ctx)
+	if err != nil {
+		return err
 	}
+	logger.Debug("Endpoints with last discovery status not equal to DiscoverOK.",
+		zap.Any("notDiscoveredOKEndpoints", notDiscoveredOKEndpoints))
+
+	var notDiscoveredXnames []string
+	var endpointWaitGroup sync.WaitGroup
+
+	for _, endpoint := range notDiscoveredOKEndpoints {
+		notDiscoveredXnames = append(notDiscoveredXnames, endpoint.ID)
+
+		endpointWaitGroup.Add(1)
+
+		go func(endpoint rf.RedfishEPDescription) {
+			defer endpointWaitGroup.Done()
+
+			// Check to see if it's Redfish is endpoint is reachable.
+			reachableErr := discoveryClient.CheckBMCRedfish(endpoint.ID, endpoint.FQDN)
+			if reachableErr != nil {
+				logger.Warn("BMC is not reachable, ignoring for now.",
+					zap.Error(reachableErr),
+					zap.String("xname", endpoint.ID),
+					zap.String("fqdn", endpoint.FQDN))
+
+				runReport.AddDevice(report.Device{
+					Phase:        "rediscover_failed_redfish_endpoints",
+					Xname:        endpoint.ID,
+					MACAddress:   endpoint.MACAddr,
+					IPAddress:    endpoint.IPAddr,
+					RedfishProbe: reachableErr.Error(),
+					Action:       report.ActionRedfishUnreachable,
+				})
+			} else {
+				logger.Info("BMC is reachable and Redfish is responsive.",
+					zap.String("xname", endpoint.ID),
+					zap.String("fqdn", endpoint.FQDN))
 
-	hsmURL := fmt.Sprintf("%s/Inventory/Discover", *hsmURL)
-	request, requestErr := retryablehttp.NewRequest("POST", hsmURL, bytes.NewBuffer(payloadBytes))
-	if requestErr != nil {
-		logger.Error("Failed to construct request!", zap.Error(requestErr))
-		return
+				potentiallyDiscoverableLock.Lock()
+				potentiallyDiscoverableEndpoints = append(potentiallyDiscoverableEndpoints, endpoint.ID)
+				potentiallyDiscoverableLock.Unlock()
+			}
+		}(endpoint)
 	}
-	request.Header.Set("Content-Type", "application/json")
 
-	response, doErr := httpClient.Do(request)
-	defer base.DrainAndCloseResponseBody(response)
-	if doErr != nil {
-		logger.Error("Failed to execute POST request!", zap.Error(doErr))
-		return
+	endpointWaitGroup.Wait()
+
+	// Checking every BMC can take a while, don't ask HSM to do anything if the phase has timed out in the meantime.
+	if ctx.Err() != nil {
+		return fmt.Errorf("rediscovery interrupted before HSM was told about reachable endpoints: %w", ctx.Err())
 	}
 
-	potentialLogger := logger.With(zap.Strings("potentiallyDiscoverableEndpoints", endpoints))
-	if response.StatusCode == http.StatusOK {
-		potentialLogger.Info("Told HSM to re-discover previously failed endpoints.")
+	if len(potentiallyDiscoverableEndpoints) > 0 {
+		rediscoverErr := reDiscoverEndpoints(ctx, potentiallyDiscoverableEndpoints)
+		for _, xname := range potentiallyDiscoverableEndpoints {
+			device := report.Device{
+				Phase:        "rediscover_failed_redfish_endpoints",
+				Xname:        xname,
+				RedfishProbe: "reachable",
+				Action:       report.ActionRediscovery,
+			}
+			if rediscoverErr != nil {
+				device = device.Failure(rediscoverErr)
+			}
+
+			runReport.AddDevice(device)
+		}
 	} else {
-		potentialLogger.Error("Got unexpected status code from re-discover attempt from HSM!",
-			zap.Int("response.StatusCode", response.StatusCode))
+		if len(notDiscoveredOKEndpoints) > 0 {
+			logger.Info("HSM contains undiscovered Redfish endpoints, however none are reachable.",
+				zap.Strings("notDiscoveredOKEndpoints", notDiscoveredXnames))
+		} else {
+			logger.Info("All Redfish endpoints in HSM are already discovered.")
+		}
 	}
+
+	return nil
 }
 
 func main() {
 	// Parse the arguments.
 	flag.Parse()
 
+	// Anything after the flags is a command, with no command doing discovery the way it always has.
+	command := flag.Arg(0)
+	switch command {
+	case "", "plan", "apply", "rollback", "switches":
+	case "config":
+		os.Exit(runConfigCommand(flag.Args()[1:]))
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown command: %s\n", command)
+		os.Exit(2)
+	}
+
+	if err := setupConfig(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	if err := validatePhases(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	if *offlineMode {
+		if err := prepareOffline(command); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+	}
+
 	*hsmURL = *hsmURL + "/hsm/v2"
 
 	setupLogging()
+	setupMetrics()
 
 	// For performance reasons we'll keep the client that was created for this base request and reuse it later.
 	httpClient = retryablehttp.NewClient()
@@ -227,15 +435,16 @@ func main() {
 	}
 	httpClient.HTTPClient.Transport = transport
 
-	httpClient.RetryMax = 2
-	httpClient.RetryWaitMax = time.Second * 2
+	httpClient.RetryMax = *httpRetryMax
+	httpClient.RetryWaitMax = *httpRetryWaitMax
 
 	// Also, since we're using Zap logger it make sense to set the logger to use the one we've already setup.
 	httpLogger := http_logger.NewHTTPLogger(logger)
 	httpClient.Logger = httpLogger
+	httpClient.ResponseLogHook = observeHTTPResponse
 
-	// Setup the DHCP/DNS client.
-	dhcpdnsClient = dns_dhcp.NewDHCPDNSHelper(*hsmURL, httpClient)
+	slsClient = sls.NewClient(*slsURL, httpClient)
+	hsmClient = hsm.NewClient(*hsmURL, httpClient)
 
 	logger.Info("Beginning HMS discovery process.",
 		zap.String("slsURL", *slsURL),
@@ -246,98 +455,82 @@ func main() {
 		zap.Bool("discoverManagementNodes", *discoverManagementNodes),
 		zap.Bool("managementSwitchCredentials", *populateManagementSwitchCredentials),
 		zap.Bool("rediscoverFailedRedfishEndpoints", *rediscoverFailedRedfishEndpoints),
+		zap.Strings("phases", discovery.DefaultRegistry.Names()),
+		zap.Bool("daemon", *daemonMode),
+		zap.Bool("dryRun", *dryRun),
+		zap.Bool("offline", *offlineMode),
 		zap.String("atomicLevel", atomicLevel.String()),
 	)
 
-	// Loop waiting for the connection to Vault to work.
-	var err error
-	for {
-		err = setupVault()
-		if err != nil {
-			logger.Error("Unable to setup Vault!", zap.Error(err))
-			time.Sleep(time.Second * 1)
-		} else {
-			break
-		}
-	}
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stop()
 
-	if *discoverManagementVirtualNodes {
-		if err := doManagementVirtualNodeDiscovery(context.Background()); err != nil {
-			logger.With(zap.Error(err)).Error("Failed to discover Management VirtualNodes")
-		}
+	// Start the control API before waiting on Vault so liveness checks pass while we wait.
+	if command == "" && *daemonMode && *httpListen != "" {
+		startAPIServer(ctx, *httpListen)
 	}
 
-	if *discoverManagementNodes {
-		if err := doManagementNodeDiscovery(context.Background()); err != nil {
-			logger.With(zap.Error(err)).Error("Failed to discover Management Nodes")
+	if *offlineMode {
+		if err := setupOffline(); err != nil {
+			logger.Error("Unable to setup offline mode!", zap.Error(err))
+			os.Exit(1)
 		}
-	}
-
-	if *populateManagementSwitchCredentials {
-		if err := doManagementSwitchCredentials(context.Background()); err != nil {
-			logger.With(zap.Error(err)).Error("Failed to populate Management Switch credentials")
+	} else {
+		// Loop waiting for the connection to Vault to work.
+		for {
+			err := setupVault()
+			if err != nil {
+				logger.Error("Unable to setup Vault!", zap.Error(err))
+				time.Sleep(time.Second * 1)
+			} else {
+				break
+			}
 		}
 	}
 
-	if *discoverRiver {
-		doRiverDiscovery()
+	discoveryClient = &discovery.Discovery{
+		SLS:                slsClient,
+		HSM:                hsmClient,
+		HTTPClient:         httpClient,
+		Logger:             logger,
+		Credentials:        plannedCredentialStore{},
+		DefaultCredentials: redsCredentialStore,
+		SwitchCommunities:  plannedCredentialStore{},
 	}
 
-	if *discoverMountain {
-		doMountainDiscovery()
+	setReady()
+
+	switch command {
+	case "plan":
+		os.Exit(runPlanCommand(ctx, flag.Args()[1:]))
+	case "apply":
+		os.Exit(runApplyCommand(ctx, flag.Args()[1:]))
+	case "rollback":
+		os.Exit(runRollbackCommand(ctx, flag.Args()[1:]))
+	case "switches":
+		os.Exit(runSwitchesCommand(ctx, flag.Args()[1:]))
 	}
 
-	if *rediscoverFailedRedfishEndpoints {
-
-		// At this point we should take advantage of the fact that we know all this information about the system and try
-		// to fix any discovery attempts that have gone poorly.
-		var potentiallyDiscoverableEndpoints []string
-
-		notDiscoveredOKEndpoints := getNotDiscoveredOKEndpointFromHSM()
-		logger.Debug("Endpoints with last discovery status not equal to DiscoverOK.",
-			zap.Any("notDiscoveredOKEndpoints", notDiscoveredOKEndpoints))
-
-		var notDiscoveredXnames []string
-		var endpointWaitGroup sync.WaitGroup
-
-		for _, endpoint := range notDiscoveredOKEndpoints {
-			notDiscoveredXnames = append(notDiscoveredXnames, endpoint.ID)
-
-			endpointWaitGroup.Add(1)
-
-			go func(endpoint rf.RedfishEPDescription) {
-				defer endpointWaitGroup.Done()
+	if *daemonMode {
+		runDaemon(ctx)
 
-				// Check to see if it's Redfish is endpoint is reachable.
-				reachableErr := checkBMCRedfish(endpoint.ID, endpoint.FQDN)
-				if reachableErr != nil {
-					logger.Warn("BMC is not reachable, ignoring for now.",
-						zap.Error(reachableErr),
-						zap.String("xname", endpoint.ID),
-						zap.String("fqdn", endpoint.FQDN))
-				} else {
-					logger.Info("BMC is reachable and Redfish is responsive.",
-						zap.String("xname", endpoint.ID),
-						zap.String("fqdn", endpoint.FQDN))
-
-					potentiallyDiscoverableEndpoints = append(potentiallyDiscoverableEndpoints, endpoint.ID)
-				}
-			}(endpoint)
-		}
+		logger.Info("HMS Discovery daemon stopped.")
+		return
+	}
 
-		endpointWaitGroup.Wait()
+	startRun()
+	runPhases(ctx, enabledPhases(), false)
+	runResult := finishRun()
 
-		if len(potentiallyDiscoverableEndpoints) > 0 {
-			reDiscoverEndpoints(potentiallyDiscoverableEndpoints)
-		} else {
-			if len(notDiscoveredOKEndpoints) > 0 {
-				logger.Info("HSM contains undiscovered Redfish endpoints, however none are reachable.",
-					zap.Strings("notDiscoveredOKEndpoints", notDiscoveredXnames))
-			} else {
-				logger.Info("All Redfish endpoints in HSM are already discovered.")
-			}
-		}
+	if *pushgatewayURL != "" {
+		pushMetrics(*pushgatewayURL)
+	}
 
+	if runResult.Failed() {
+		logger.Error("HMS Discovery process complete, however one or more phases or devices failed.",
+			zap.String("runID", runResult.RunID))
+		os.Exit(1)
 	}
-	logger.Info("HMS Discovery process complete.")
+
+	logger.Info("HMS Discovery process complete.", zap.String("runID", runResult.RunID))
 }
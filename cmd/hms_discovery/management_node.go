@@ -1,6 +1,6 @@
 // MIT License
 //
-// (C) Copyright [2023,2025] Hewlett Packard Enterprise Development LP
+// (C) Copyright [2023,2025-2026] Hewlett Packard Enterprise Development LP
 //
 // Permission is hereby granted, free of charge, to any person obtaining a
 // copy of this software and associated documentation files (the "Software"),
@@ -29,6 +29,9 @@ import (
 
 	base "github.com/Cray-HPE/hms-base/v2"
 	compcredentials "github.com/Cray-HPE/hms-compcredentials"
+	"github.com/Cray-HPE/hms-discovery/pkg/hsm"
+	"github.com/Cray-HPE/hms-discovery/pkg/report"
+	"github.com/Cray-HPE/hms-discovery/pkg/sls"
 	sls_common "github.com/Cray-HPE/hms-sls/v2/pkg/sls-common"
 	"github.com/Cray-HPE/hms-xname/xnametypes"
 	"github.com/mitchellh/mapstructure"
@@ -42,9 +45,9 @@ func doManagementNodeDiscovery(ctx context.Context) error {
 
 	// Query SLS for Management Nodes
 	logger.Info("Querying SLS for Management Nodes")
-	slsNodes, err := getSLSSearchHardware(ctx, map[string]string{
-		"type":                  sls_common.Node.String(),
-		"extra_properties.Role": base.RoleManagement.String(),
+	slsNodes, err := searchSLS(ctx, sls.Query{
+		Type:            sls_common.Node,
+		ExtraProperties: map[string]string{"Role": base.RoleManagement.String()},
 	})
 	if err != nil {
 		return errors.Join(fmt.Errorf("failed to retrieve Management Nodes from SLS"), err)
@@ -100,30 +103,38 @@ func doManagementNodeDiscovery(ctx context.Context) error {
 		bmcXname := xnametypes.GetHMSCompParent(nodeXname)
 		subLogger := logger.With(zap.String("nodeXname", nodeXname), zap.String("bmcXname", bmcXname))
 
+		device := report.Device{
+			Phase: "management_nodes",
+			Xname: bmcXname,
+		}
+
 		slsNode := slsNodes[nodeXname]
 		var slsExtraProperties sls_common.ComptypeNode
 		if err := mapstructure.Decode(slsNode.ExtraPropertiesRaw, &slsExtraProperties); err != nil {
 			subLogger.With(zap.Any("slsNode", slsNode), zap.Error(err)).Error("Failed to decode node extra properties")
+			runReport.AddDevice(device.Failure(err))
 			continue
 		}
 
 		// Check to see if redfish endpoint exists
-		if _, err := getHSMInventoryRedfishEndpoint(ctx, bmcXname); err == nil {
+		if _, err := hsmClient.GetRedfishEndpoint(ctx, bmcXname); err == nil {
 			// Redfish endpoint exists in HSM skip it, no work to do.
 			subLogger.Info("Management Node BMC exists in Redfish Endpoints, but node not in State Components. Waiting for rediscovery")
+			device.Action = report.ActionSkipped
+			runReport.AddDevice(device)
 			continue
-		} else if !errors.Is(err, ErrNotFound) {
+		} else if !errors.Is(err, hsm.ErrNotFound) {
 			subLogger.With(zap.Error(err)).Error("Failed to query HSM for Redfish Endpoint")
+			runReport.AddDevice(device.Failure(err))
 			continue
 		}
 
 		// It does not exist, need to create information in HSM
 
-		mgmtSwitchConnectors, err := getSLSSearchHardware(ctx, map[string]string{
-			"node_nics": bmcXname,
-		})
+		mgmtSwitchConnectors, err := getSLSSwitchConnectorsForNodeNic(ctx, bmcXname)
 		if err != nil {
 			subLogger.With(zap.Error(err)).Error("Failed to query SLS for BMC's MgmtSwitchConnector")
+			runReport.AddDevice(device.Failure(err))
 			continue
 		}
 
@@ -134,37 +145,44 @@ func doManagementNodeDiscovery(ctx context.Context) error {
 		subLogger.Debug("Creating redfish endpoint for Management Node BMC")
 		// First check to see if there are credentials in Vault for this xname. If there are we won't
 		// re-set them in case they've been changed from the defaults.
-		credentials, err := hsmCredentialStore.GetCompCred(bmcXname)
+		credentials, err := getCompCred(bmcXname)
 		if err != nil {
 			subLogger.With(zap.Error(err)).
 				Error("Unable to check Vault for BMC credentials, not creating RedfishEndpoint in HSM")
+			runReport.AddDevice(device.Failure(err))
 			continue
 		}
 
 		if credentials.Username == "" || credentials.Password == "" {
+			device.CredentialSource = report.CredentialSourceDefault
 			credentials := compcredentials.CompCredentials{
 				Xname:    bmcXname,
 				Username: defaultCreds["Cray"].Username,
 				Password: defaultCreds["Cray"].Password,
 			}
 
-			err = hsmCredentialStore.StoreCompCred(credentials)
+			err = storeCompCred("management_nodes", credentials,
+				map[string]string{"password": secretDefaultBMCPassword()})
 			if err != nil {
 				subLogger.With(zap.Error(err)).
 					Error("Unable to set credentials, not creating RedfishEndpoint in HSM")
+				runReport.AddDevice(device.Failure(err))
 				continue
 			}
 			subLogger.Debug("Set BMC credentials in Vault")
 		} else {
+			device.CredentialSource = report.CredentialSourceVault
 			subLogger.Debug("BMC credentials already exist in Vault")
 		}
 
-		if err := informHSM(bmcXname, bmcXname, ""); err != nil {
+		if err := informHSM(ctx, "management_nodes", bmcXname, bmcXname, ""); err != nil {
 			subLogger.With(zap.Error(err)).Error("Failed to inform HSM about Management Node BMC")
+			device = device.Failure(err)
+		} else {
+			device.Action = report.ActionCreatedEndpoint
+			subLogger.Info("Created RedfishEndpoint in HSM for Management Node BMC")
 		}
 
-		subLogger.Info("Created RedfishEndpoint in HSM for Management Node BMC")
-
 		if len(mgmtSwitchConnectors) == 0 && slsExtraProperties.SubRole == "Master" {
 			subLogger.Debug("Management Node BMC has no connection to HMN, creating component in HSM")
 
@@ -180,13 +198,21 @@ func doManagementNodeDiscovery(ctx context.Context) error {
 
 			subLogger.With(zap.Any("component", component)).Debug("Component to be created")
 
-			if err := postHSMStateComponent(ctx, component); err != nil {
+			if err := createComponent(ctx, "management_nodes", component); err != nil {
 				subLogger.With(zap.Any("slsVirtualNode", slsNode), zap.Error(err)).Error("Failed to create State component for Management VirtualNode")
+				runReport.AddDevice(device.Failure(err))
 				continue
 			}
 
 			subLogger.Info("Created Component in HSM for Management Node")
+			runReport.AddDevice(report.Device{
+				Phase:  "management_nodes",
+				Xname:  nodeXname,
+				Action: report.ActionCreatedComponent,
+			})
 		}
+
+		runReport.AddDevice(device)
 	}
 
 	return nil
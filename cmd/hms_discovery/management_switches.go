@@ -1,6 +1,6 @@
 // MIT License
 //
-// (C) Copyright [2023] Hewlett Packard Enterprise Development LP
+// (C) Copyright [2023,2026] Hewlett Packard Enterprise Development LP
 //
 // Permission is hereby granted, free of charge, to any person obtaining a
 // copy of this software and associated documentation files (the "Software"),
@@ -27,11 +27,22 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/Cray-HPE/hms-discovery/pkg/discovery"
+	"github.com/Cray-HPE/hms-discovery/pkg/report"
+	"github.com/Cray-HPE/hms-discovery/pkg/sls"
+	"github.com/Cray-HPE/hms-discovery/pkg/snmp_utilities"
+	"github.com/Cray-HPE/hms-discovery/pkg/switches"
 	sls_common "github.com/Cray-HPE/hms-sls/v2/pkg/sls-common"
-	"github.com/mitchellh/mapstructure"
 	"go.uber.org/zap"
 )
 
+// managementSwitchTypes are the SLS types of every management switch.
+var managementSwitchTypes = []sls_common.HMSStringType{
+	sls_common.MgmtSwitch,
+	sls_common.MgmtHLSwitch,
+	sls_common.CDUMgmtSwitch,
+}
+
 func doManagementSwitchCredentials(ctx context.Context) error {
 	logger.Info("Starting Management Switch credential population in vault")
 
@@ -39,11 +50,11 @@ func doManagementSwitchCredentials(ctx context.Context) error {
 	// Find management switches in SLS
 	//
 	allManagementSwitches := map[string]sls_common.GenericHardware{}
-	for _, switchType := range []sls_common.HMSStringType{sls_common.MgmtSwitch, sls_common.MgmtHLSwitch, sls_common.CDUMgmtSwitch} {
+	for _, switchType := range managementSwitchTypes {
 		logger.Sugar().Debugf("Querying SLS for %s Management Switches", switchType)
 
-		foundSwitches, err := getSLSSearchHardware(ctx, map[string]string{
-			"type": string(switchType),
+		foundSwitches, err := searchSLS(ctx, sls.Query{
+			Type: switchType,
 		})
 		if err != nil {
 			return errors.Join(fmt.Errorf("failed to search SLS for %s hardware", switchType), err)
@@ -70,14 +81,40 @@ func doManagementSwitchCredentials(ctx context.Context) error {
 
 	for xname, slsSwitch := range allManagementSwitches {
 		subLogger := logger.With(zap.String("xname", xname))
+		device := report.Device{
+			Phase: "management_switch_credentials",
+			Xname: xname,
+		}
 
 		//
 		// Parse switch extra properties
 		//
 
-		var slsExtraProperties sls_common.ComptypeMgmtSwitch
-		if err := mapstructure.Decode(slsSwitch.ExtraPropertiesRaw, &slsExtraProperties); err != nil {
+		var slsExtraProperties switches.SwitchProperties
+		if err := sls.DecodeExtraProperties(slsSwitch, &slsExtraProperties); err != nil {
 			subLogger.With(zap.Any("slsSwitch", slsSwitch), zap.Error(err)).Error("Failed to decode switch extra properties")
+			runReport.AddDevice(device.Failure(err))
+			continue
+		}
+
+		snmpVersion, err := switches.ParseSNMPVersion(slsExtraProperties.SNMPVersion)
+		if err != nil {
+			subLogger.With(zap.Error(err)).Error("Failed to determine SNMP version of switch")
+			runReport.AddDevice(device.Failure(err))
+			continue
+		}
+
+		// An SNMPv2c switch only needs its community.
+		if snmpVersion == switches.SNMPVersion2c {
+			device = populateSwitchCommunity(subLogger, xname, slsExtraProperties, defaultCreds, device)
+			runReport.AddDevice(device)
+
+			switch device.Action {
+			case report.ActionAlreadyPresent:
+				alreadyPopulated = append(alreadyPopulated, xname)
+			case report.ActionStoredCredentials:
+				populatedCredentails = append(populatedCredentails, xname)
+			}
 			continue
 		}
 
@@ -86,15 +123,19 @@ func doManagementSwitchCredentials(ctx context.Context) error {
 		//
 
 		// Check to see if credentials exist
-		switchCred, err := hsmCredentialStore.GetCompCred(xname)
+		switchCred, err := getCompCred(xname)
 		if err != nil {
 			subLogger.With(zap.Error(err)).Error("failed to query vault for switch credentials")
+			runReport.AddDevice(device.Failure(err))
 			continue
 		}
 
 		if switchCred.SNMPAuthPass != "" && switchCred.SNMPPrivPass != "" && switchCred.Username != "" {
 			subLogger.Debug("Found populated switch creds in vault")
 			alreadyPopulated = append(alreadyPopulated, xname)
+			device.Action = report.ActionAlreadyPresent
+			device.CredentialSource = report.CredentialSourceVault
+			runReport.AddDevice(device)
 
 			continue
 		}
@@ -102,17 +143,24 @@ func doManagementSwitchCredentials(ctx context.Context) error {
 		// If we get nothing back from Vault then we need to push something in.
 		switchCred.Xname = xname
 
+		secrets := map[string]string{}
+
 		vaultURIPrefix := "vault://"
 		if slsExtraProperties.SNMPAuthPassword != "" && !strings.HasPrefix(slsExtraProperties.SNMPAuthPassword, vaultURIPrefix) {
 			switchCred.SNMPAuthPass = slsExtraProperties.SNMPAuthPassword
+			device.CredentialSource = report.CredentialSourceSLS
+			secrets["SNMPAuthPass"] = secretFromSLS(xname, "SNMPAuthPassword")
 		} else {
 			switchCred.SNMPAuthPass = defaultCreds.SNMPAuthPassword
+			secrets["SNMPAuthPass"] = secretDefaultSNMPAuthPass()
 		}
 
 		if slsExtraProperties.SNMPPrivPassword != "" && !strings.HasPrefix(slsExtraProperties.SNMPPrivPassword, vaultURIPrefix) {
 			switchCred.SNMPPrivPass = slsExtraProperties.SNMPPrivPassword
+			secrets["SNMPPrivPass"] = secretFromSLS(xname, "SNMPPrivPassword")
 		} else {
 			switchCred.SNMPPrivPass = defaultCreds.SNMPPrivPassword
+			secrets["SNMPPrivPass"] = secretDefaultSNMPPrivPass()
 		}
 
 		if slsExtraProperties.SNMPUsername != "" {
@@ -121,13 +169,19 @@ func doManagementSwitchCredentials(ctx context.Context) error {
 			switchCred.Username = defaultCreds.SNMPUsername
 		}
 
-		err = hsmCredentialStore.StoreCompCred(switchCred)
+		err = storeCompCred("management_switch_credentials", switchCred, secrets)
 		if err != nil {
 			subLogger.With(zap.Error(err)).Error("Unable to store credentials for switch")
+			runReport.AddDevice(device.Failure(err))
 			continue
 		}
 
 		subLogger.Info("Stored credential for switch")
+		if device.CredentialSource == "" {
+			device.CredentialSource = report.CredentialSourceDefault
+		}
+		device.Action = report.ActionStoredCredentials
+		runReport.AddDevice(device)
 		populatedCredentails = append(populatedCredentails, xname)
 	}
 
@@ -136,3 +190,50 @@ func doManagementSwitchCredentials(ctx context.Context) error {
 
 	return nil
 }
+
+// populateSwitchCommunity stores the SNMPv2c community for a switch in Vault, taken from SLS or the default switch
+// credentials, unless it already has one there.
+func populateSwitchCommunity(subLogger *zap.Logger, xname string, slsExtraProperties switches.SwitchProperties,
+	defaultCreds switches.SwitchCredentials, device report.Device) report.Device {
+	community, err := getSwitchCommunity(xname)
+	if err != nil {
+		subLogger.With(zap.Error(err)).Error("failed to query vault for switch community")
+		return device.Failure(err)
+	}
+
+	if community.SNMPCommunity != "" {
+		subLogger.Debug("Found populated switch community in vault")
+		device.Action = report.ActionAlreadyPresent
+		device.CredentialSource = report.CredentialSourceVault
+		return device
+	}
+
+	community.Xname = xname
+
+	secrets := map[string]string{}
+	if slsExtraProperties.SNMPCommunity != "" &&
+		!strings.HasPrefix(slsExtraProperties.SNMPCommunity, discovery.VaultPrefix) {
+		community.SNMPCommunity = slsExtraProperties.SNMPCommunity
+		device.CredentialSource = report.CredentialSourceSLS
+		secrets["SNMPCommunity"] = secretFromSLS(xname, "SNMPCommunity")
+	} else if defaultCreds.SNMPCommunity != "" {
+		community.SNMPCommunity = defaultCreds.SNMPCommunity
+		device.CredentialSource = report.CredentialSourceDefault
+		secrets["SNMPCommunity"] = secretDefaultSNMPCommunity()
+	} else {
+		// Storing an empty community would only have the switch fail later on with a timeout.
+		err := fmt.Errorf("%s: no SNMPv2c community for %s in SLS or the default switch credentials",
+			snmp_utilities.ErrorClassConfiguration, xname)
+		subLogger.With(zap.Error(err)).Error("No community for SNMPv2c switch")
+		return device.Failure(err)
+	}
+
+	if err := storeSwitchCommunity("management_switch_credentials", community, secrets); err != nil {
+		subLogger.With(zap.Error(err)).Error("Unable to store community for switch")
+		return device.Failure(err)
+	}
+
+	subLogger.Info("Stored community for switch")
+	device.Action = report.ActionStoredCredentials
+	return device
+}
@@ -0,0 +1,116 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Cray-HPE/hms-discovery/pkg/report"
+	"github.com/Cray-HPE/hms-discovery/pkg/switches"
+)
+
+func TestPopulateSwitchCommunity(t *testing.T) {
+	defaultCreds := switches.SwitchCredentials{
+		SNMPUsername:     "testuser",
+		SNMPAuthPassword: "auth",
+		SNMPPrivPassword: "priv",
+		SNMPCommunity:    "default-community",
+	}
+
+	tests := []struct {
+		name         string
+		stored       string
+		slsCommunity string
+		defaultCreds switches.SwitchCredentials
+		wantAction   string
+		wantSource   string
+		wantStored   string
+	}{
+		{
+			name:         "community already in Vault is kept",
+			stored:       "vault-community",
+			slsCommunity: "sls-community",
+			defaultCreds: defaultCreds,
+			wantAction:   report.ActionAlreadyPresent,
+			wantSource:   report.CredentialSourceVault,
+			wantStored:   "vault-community",
+		},
+		{
+			name:         "community from SLS",
+			slsCommunity: "sls-community",
+			defaultCreds: defaultCreds,
+			wantAction:   report.ActionStoredCredentials,
+			wantSource:   report.CredentialSourceSLS,
+			wantStored:   "sls-community",
+		},
+		{
+			name:         "community kept in Vault by SLS falls back on the default",
+			slsCommunity: "vault://switch-community",
+			defaultCreds: defaultCreds,
+			wantAction:   report.ActionStoredCredentials,
+			wantSource:   report.CredentialSourceDefault,
+			wantStored:   "default-community",
+		},
+		{
+			name:         "no community anywhere",
+			defaultCreds: switches.SwitchCredentials{SNMPUsername: "testuser"},
+			wantAction:   report.ActionFailed,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			setupTestRun(t, false)
+			if test.stored != "" {
+				storeTestSwitchCommunity(t, test.stored)
+			}
+
+			properties := switches.SwitchProperties{SNMPVersion: "v2c", SNMPCommunity: test.slsCommunity}
+			device := populateSwitchCommunity(logger, testSwitch, properties, test.defaultCreds,
+				report.Device{Phase: "management_switch_credentials", Xname: testSwitch})
+
+			if device.Action != test.wantAction {
+				t.Errorf("populateSwitchCommunity() Action = %q (%s), want %q", device.Action, device.Error,
+					test.wantAction)
+			}
+			if device.CredentialSource != test.wantSource {
+				t.Errorf("populateSwitchCommunity() CredentialSource = %q, want %q", device.CredentialSource,
+					test.wantSource)
+			}
+			if test.wantAction == report.ActionFailed && !strings.HasPrefix(device.Error, "configuration: ") {
+				t.Errorf("populateSwitchCommunity() Error = %q, want a configuration error", device.Error)
+			}
+			checkTestSwitchCommunity(t, test.wantStored)
+
+			// The community is never kept with the component credentials of the switch.
+			cred, err := hsmCredentialStore.GetCompCred(testSwitch)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cred.Xname != "" {
+				t.Errorf("stored component credentials for %s, want none", cred.Xname)
+			}
+		})
+	}
+}
@@ -1,6 +1,6 @@
 // MIT License
 //
-// (C) Copyright [2023,2025] Hewlett Packard Enterprise Development LP
+// (C) Copyright [2023,2025-2026] Hewlett Packard Enterprise Development LP
 //
 // Permission is hereby granted, free of charge, to any person obtaining a
 // copy of this software and associated documentation files (the "Software"),
@@ -29,6 +29,8 @@ import (
 	"sort"
 
 	base "github.com/Cray-HPE/hms-base/v2"
+	"github.com/Cray-HPE/hms-discovery/pkg/report"
+	"github.com/Cray-HPE/hms-discovery/pkg/sls"
 	sls_common "github.com/Cray-HPE/hms-sls/v2/pkg/sls-common"
 	"github.com/Cray-HPE/hms-xname/xnametypes"
 	"github.com/mitchellh/mapstructure"
@@ -64,9 +66,9 @@ func doManagementVirtualNodeDiscovery(ctx context.Context) error {
 
 	// Query SLS for Management Virtual Nodes
 	logger.Info("Querying SLS for Management VirtualNodes")
-	slsVirtualNodes, err := getSLSSearchHardware(ctx, map[string]string{
-		"type":                  sls_common.VirtualNode.String(),
-		"extra_properties.Role": base.RoleManagement.String(),
+	slsVirtualNodes, err := searchSLS(ctx, sls.Query{
+		Type:            sls_common.VirtualNode,
+		ExtraProperties: map[string]string{"Role": base.RoleManagement.String()},
 	})
 	if err != nil {
 		return errors.Join(fmt.Errorf("failed to retrieve Management VirtualNodes from SLS"), err)
@@ -116,9 +118,15 @@ func doManagementVirtualNodeDiscovery(ctx context.Context) error {
 		slsVirtualNode := slsVirtualNodes[xname]
 		subLogger.With(zap.Any("slsVirtualNode", slsVirtualNode)).Debug("Processing SLS Virtual Node")
 
+		device := report.Device{
+			Phase: "management_virtual_nodes",
+			Xname: xname,
+		}
+
 		var slsExtraProperties sls_common.ComptypeVirtualNode
 		if err := mapstructure.Decode(slsVirtualNode.ExtraPropertiesRaw, &slsExtraProperties); err != nil {
 			subLogger.With(zap.Any("slsVirtualNode", slsVirtualNode), zap.Error(err)).Error("Failed to decode virtual node extra properties")
+			runReport.AddDevice(device.Failure(err))
 			continue
 		}
 
@@ -132,12 +140,15 @@ func doManagementVirtualNodeDiscovery(ctx context.Context) error {
 			Arch:    base.ArchX86.String(),
 		}
 
-		if err := postHSMStateComponent(ctx, component); err != nil {
+		if err := createComponent(ctx, "management_virtual_nodes", component); err != nil {
 			subLogger.With(zap.Any("slsVirtualNode", slsVirtualNode), zap.Error(err)).Error("Failed to create State component for Management VirtualNode")
+			runReport.AddDevice(device.Failure(err))
 			continue
 		}
 
 		subLogger.Info("Created Virtual Node in HSM State Components")
+		device.Action = report.ActionCreatedComponent
+		runReport.AddDevice(device)
 	}
 
 	return nil
@@ -0,0 +1,205 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	securestorage "github.com/Cray-HPE/hms-securestorage"
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/client_golang/prometheus/push"
+	"go.uber.org/zap"
+)
+
+const metricsNamespace = "hms_discovery"
+
+var (
+	metricsRegistry = prometheus.NewRegistry()
+
+	phaseDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "phase_duration_seconds",
+		Help:      "How long each discovery phase took to run.",
+		Buckets:   []float64{1, 5, 15, 30, 60, 120, 300, 600, 1200},
+	}, []string{"phase"})
+	phaseRuns = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "phase_runs_total",
+		Help:      "Number of discovery phase runs by result.",
+	}, []string{"phase", "result"})
+	phaseLastSuccess = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "phase_last_success_timestamp_seconds",
+		Help:      "Unix time the discovery phase last finished without error.",
+	}, []string{"phase"})
+
+	unknownComponentsFound = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "unknown_components",
+		Help:      "Number of unknown components found in HSM EthernetInterfaces by the last River discovery.",
+	})
+	riverMACs = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "river_macs_total",
+		Help:      "Number of unknown component MACs River discovery tried to resolve, by result.",
+	}, []string{"result"})
+	riverLastMACResolved = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "river_last_mac_resolved_timestamp_seconds",
+		Help:      "Unix time River discovery last resolved a MAC address to an xname.",
+	})
+
+	snmpWalks = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "snmp_switch_walks_total",
+		Help:      "Number of attempts to collect the MAC address tables from a management switch, by result.",
+	}, []string{"switch", "result"})
+	snmpWalkDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "snmp_switch_walk_duration_seconds",
+		Help:      "How long it took to collect the MAC address tables from a management switch.",
+		Buckets:   []float64{0.5, 1, 2.5, 5, 10, 30, 60, 120},
+	}, []string{"switch"})
+	snmpSwitchErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "snmp_switch_errors_total",
+		Help:      "Number of failed attempts to walk a management switch, by the class of error.",
+	}, []string{"switch", "class"})
+
+	vaultErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "vault_errors_total",
+		Help:      "Number of failed Vault operations.",
+	}, []string{"operation"})
+
+	httpResponses = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "http_responses_total",
+		Help:      "Number of HTTP responses received from HMS services, by service and status code.",
+	}, []string{"service", "code"})
+
+	rediscoveryAttempts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "rediscovery_attempts_total",
+		Help:      "Number of Redfish endpoints HSM was asked to rediscover, by result.",
+	}, []string{"result"})
+)
+
+func setupMetrics() {
+	metricsRegistry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		phaseDuration,
+		phaseRuns,
+		phaseLastSuccess,
+		unknownComponentsFound,
+		riverMACs,
+		riverLastMACResolved,
+		snmpWalks,
+		snmpWalkDuration,
+		snmpSwitchErrors,
+		vaultErrors,
+		httpResponses,
+		rediscoveryAttempts,
+	)
+}
+
+func metricsHandler() http.Handler {
+	return promhttp.HandlerFor(metricsRegistry, promhttp.HandlerOpts{})
+}
+
+// pushMetrics sends everything gathered during a one-shot run to a Pushgateway compatible endpoint, as there is
+// nothing around afterwards for Prometheus to scrape.
+func pushMetrics(pushgatewayURL string) {
+	err := push.New(pushgatewayURL, metricsNamespace).
+		Gatherer(metricsRegistry).
+		Push()
+	if err != nil {
+		logger.Error("Failed to push metrics!", zap.Error(err), zap.String("pushgatewayURL", pushgatewayURL))
+		return
+	}
+
+	logger.Debug("Pushed metrics.", zap.String("pushgatewayURL", pushgatewayURL))
+}
+
+// observeHTTPResponse is used as the response hook on the shared HTTP client to count responses from HSM and SLS.
+func observeHTTPResponse(_ retryablehttp.Logger, response *http.Response) {
+	if response == nil || response.Request == nil {
+		return
+	}
+
+	requestURL := response.Request.URL.String()
+
+	var service string
+	switch {
+	case strings.HasPrefix(requestURL, *hsmURL):
+		service = "hsm"
+	case strings.HasPrefix(requestURL, *slsURL):
+		service = "sls"
+	default:
+		// Everything else is a BMC or PDU, which would make for a lot of noise with very little value.
+		return
+	}
+
+	httpResponses.WithLabelValues(service, strconv.Itoa(response.StatusCode)).Inc()
+}
+
+// instrumentedSecureStorage counts errors from the wrapped secure storage so that all of the credential stores built
+// on top of it are covered.
+type instrumentedSecureStorage struct {
+	securestorage.SecureStorage
+}
+
+func countVaultError(operation string, err error) error {
+	if err != nil {
+		vaultErrors.WithLabelValues(operation).Inc()
+	}
+
+	return err
+}
+
+func (ss instrumentedSecureStorage) Store(key string, value interface{}) error {
+	return countVaultError("write", ss.SecureStorage.Store(key, value))
+}
+
+func (ss instrumentedSecureStorage) StoreWithData(key string, value interface{}, output interface{}) error {
+	return countVaultError("write", ss.SecureStorage.StoreWithData(key, value, output))
+}
+
+func (ss instrumentedSecureStorage) Lookup(key string, output interface{}) error {
+	return countVaultError("read", ss.SecureStorage.Lookup(key, output))
+}
+
+func (ss instrumentedSecureStorage) Delete(key string) error {
+	return countVaultError("delete", ss.SecureStorage.Delete(key))
+}
+
+func (ss instrumentedSecureStorage) LookupKeys(keyPath string) ([]string, error) {
+	keys, err := ss.SecureStorage.LookupKeys(keyPath)
+	return keys, countVaultError("read", err)
+}
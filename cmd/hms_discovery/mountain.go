@@ -1,6 +1,6 @@
 // MIT License
 //
-// (C) Copyright [2021-2022] Hewlett Packard Enterprise Development LP
+// (C) Copyright [2021-2022,2026] Hewlett Packard Enterprise Development LP
 //
 // Permission is hereby granted, free of charge, to any person obtaining a
 // copy of this software and associated documentation files (the "Software"),
@@ -23,7 +23,9 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"math"
 	"net/url"
 	"os"
 	"os/exec"
@@ -44,20 +46,26 @@ development setting I would recommend checking out that repo and referencing tha
 
 var mountainLoggingRegex = regexp.MustCompile(`.+-([A-Z]+)-(.+)`)
 
-func doMountainDiscovery() {
+func doMountainDiscovery(ctx context.Context) error {
+	if *dryRun {
+		// The Python script talks to HSM and CAPMC itself, so there is no way to only plan what it would do.
+		logger.Warn("Not running Mountain discovery in dry run mode.")
+		return nil
+	}
+
 	hsmURLParsed, err := url.Parse(*hsmURL)
 	if err != nil {
-		logger.Fatal("Failed to parse HSM URL", zap.Stringp("hsmURL", hsmURL), zap.Error(err))
+		return fmt.Errorf("failed to parse HSM URL %q: %w", *hsmURL, err)
 	}
 
 	slsURLParsed, err := url.Parse(*slsURL)
 	if err != nil {
-		logger.Fatal("Failed to parse SLS URL", zap.Stringp("slsURL", slsURL), zap.Error(err))
+		return fmt.Errorf("failed to parse SLS URL %q: %w", *slsURL, err)
 	}
 
 	capmcURLParsed, err := url.Parse(*capmcURL)
 	if err != nil {
-		logger.Fatal("Failed to parse CAPMC URL", zap.Stringp("capmcURL", capmcURL), zap.Error(err))
+		return fmt.Errorf("failed to parse CAPMC URL %q: %w", *capmcURL, err)
 	}
 
 	configEnvVariables := []string{
@@ -69,13 +77,15 @@ func doMountainDiscovery() {
 		fmt.Sprintf("CAPMC_PROTOCOL=%s://", capmcURLParsed.Scheme),
 		fmt.Sprintf("CAPMC_HOST_WITH_PORT=%s", capmcURLParsed.Host),
 		"CAPMC_BASE_PATH=/capmc/v1",
-		"SLEEP_LENGTH=30",
+		// The script only takes whole seconds, a fraction is rounded up rather than cut short.
+		fmt.Sprintf("SLEEP_LENGTH=%d", int(math.Ceil(mountainSleepLength.Seconds()))),
 		"FEATURE_FLAG_SLS=False",
 	}
 
 	logger.Debug("Configuration environment variables being supplied to mountain_discovery.py", zap.Strings("configEnvVariables", configEnvVariables))
 
-	command := exec.Command("python3", *mountainDiscoveryScript)
+	// The script is killed if the phase runs past its timeout.
+	command := exec.CommandContext(ctx, "python3", *mountainDiscoveryScript)
 	command.Env = append(os.Environ(), configEnvVariables...)
 
 	output, err := command.CombinedOutput()
@@ -104,8 +114,9 @@ func doMountainDiscovery() {
 	}
 
 	if err != nil {
-		logger.Error("Mountain discovery script failed to exec!", zap.Error(err))
-	} else {
-		logger.Info("Mountain discovery finished.")
+		return fmt.Errorf("mountain discovery script failed to exec: %w", err)
 	}
+
+	logger.Info("Mountain discovery finished.")
+	return nil
 }
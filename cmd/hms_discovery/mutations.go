@@ -0,0 +1,535 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+	compcredentials "github.com/Cray-HPE/hms-compcredentials"
+	"github.com/Cray-HPE/hms-discovery/pkg/hsm"
+	"github.com/Cray-HPE/hms-discovery/pkg/journal"
+	"github.com/Cray-HPE/hms-discovery/pkg/pdu_credential_store"
+	"github.com/Cray-HPE/hms-discovery/pkg/plan"
+	"github.com/Cray-HPE/hms-discovery/pkg/switches"
+	rf "github.com/Cray-HPE/hms-smd/v2/pkg/redfish"
+	"github.com/Cray-HPE/hms-smd/v2/pkg/sm"
+	"go.uber.org/zap"
+)
+
+/*
+Every write discovery makes to HSM or Vault goes through the functions in this file, with the exception of asking HSM
+to rediscover endpoints as that doesn't change anything that could be rolled back. Writes are journaled along with what
+they replaced before they are made. In dry-run mode the write is recorded in the plan instead of being made, and
+credentials that would have been stored are remembered so the rest of the run behaves as if they had been.
+*/
+
+// The secretDefault functions say where the default credentials discovery stores are read from when applying a plan.
+func secretDefaultBMCPassword() string {
+	return "vault:" + *redsCredentialsVaultPath + "/defaults#Cray.password"
+}
+
+func secretDefaultSNMPAuthPass() string {
+	return "vault:" + *redsCredentialsVaultPath + "/switch_defaults#SNMPAuthPassword"
+}
+
+func secretDefaultSNMPPrivPass() string {
+	return "vault:" + *redsCredentialsVaultPath + "/switch_defaults#SNMPPrivPassword"
+}
+
+func secretDefaultSNMPCommunity() string {
+	return "vault:" + *redsCredentialsVaultPath + "/switch_defaults#SNMPCommunity"
+}
+
+func secretDefaultPDUPassword() string {
+	return "vault:" + *pduCredentialsVaultPath + "/" + pdu_credential_store.CredentialsGlobalKey + "#password"
+}
+
+// secretFromSLS is where a secret kept in the ExtraProperties of an SLS hardware object comes from.
+func secretFromSLS(xname, property string) string {
+	return "sls:" + xname + "#" + property
+}
+
+var (
+	mutationJournal *journal.Journal
+
+	planRecorder = plan.NewRecorder("")
+
+	plannedCompCredsLock     sync.Mutex
+	plannedCompCreds         = map[string]compcredentials.CompCredentials{}
+	plannedSwitchCommunities = map[string]switches.SwitchCommunity{}
+)
+
+func startPlan(runID string) {
+	planRecorder = plan.NewRecorder(runID)
+
+	// Taken before anything is planned so that a change made to SLS part way through is still caught on apply.
+	slsFingerprint, err := getSLSFingerprint(context.Background())
+	if err != nil {
+		logger.Error("Failed to fingerprint SLS, plan will not be able to be applied!", zap.Error(err))
+	}
+	planRecorder.SetSLSFingerprint(slsFingerprint)
+
+	plannedCompCredsLock.Lock()
+	defer plannedCompCredsLock.Unlock()
+	plannedCompCreds = map[string]compcredentials.CompCredentials{}
+	plannedSwitchCommunities = map[string]switches.SwitchCommunity{}
+}
+
+// finishPlan shows the operator what the dry run would have done and saves the plan if asked to.
+func finishPlan() plan.Plan {
+	finishedPlan := planRecorder.Plan()
+
+	fmt.Fprint(os.Stdout, finishedPlan.Diff())
+
+	if *planPath != "" {
+		if err := finishedPlan.WriteFile(*planPath); err != nil {
+			logger.Error("Failed to write plan!", zap.Error(err), zap.String("planPath", *planPath))
+		} else {
+			logger.Info("Wrote plan.", zap.String("planPath", *planPath))
+		}
+	}
+
+	return finishedPlan
+}
+
+// recordMutation adds a mutation to the plan. beforeSecrets are the values redacted from before, which are only kept
+// as a digest.
+func recordMutation(phase, operation, xname string, before, after interface{}, secrets map[string]string,
+	beforeSecrets ...string) error {
+	mutation, err := plan.NewMutation(phase, operation, xname, before, after, secrets)
+	if err != nil {
+		return err
+	}
+	if len(beforeSecrets) != 0 {
+		mutation.BeforeSecretsDigest = plan.SecretsDigest(planRecorder.RunID(), beforeSecrets...)
+	}
+
+	logger.Info("Dry run, not making change.",
+		zap.String("operation", operation), zap.String("xname", xname), zap.String("phase", phase))
+	planRecorder.Add(mutation)
+
+	return nil
+}
+
+func redactCompCred(cred compcredentials.CompCredentials) compcredentials.CompCredentials {
+	if cred.Password != "" {
+		cred.Password = plan.Redacted
+	}
+	if cred.SNMPAuthPass != "" {
+		cred.SNMPAuthPass = plan.Redacted
+	}
+	if cred.SNMPPrivPass != "" {
+		cred.SNMPPrivPass = plan.Redacted
+	}
+
+	return cred
+}
+
+// redactTarget redacts value when it is a credential, as currentTarget returns them.
+func redactTarget(value interface{}) interface{} {
+	switch target := value.(type) {
+	case compcredentials.CompCredentials:
+		return redactCompCred(target)
+	case pdu_credential_store.Device:
+		return redactPDUCredentials(target)
+	case switches.SwitchCommunity:
+		return redactSwitchCommunity(target)
+	}
+
+	return value
+}
+
+// compCredSecrets are the values redactCompCred hides.
+func compCredSecrets(cred compcredentials.CompCredentials) []string {
+	return []string{cred.Password, cred.SNMPAuthPass, cred.SNMPPrivPass}
+}
+
+// getCompCred reads the credentials for an xname, taking into account credentials a dry run would have stored.
+func getCompCred(xname string) (compcredentials.CompCredentials, error) {
+	plannedCompCredsLock.Lock()
+	cred, planned := plannedCompCreds[xname]
+	plannedCompCredsLock.Unlock()
+
+	if planned {
+		return cred, nil
+	}
+
+	return hsmCredentialStore.GetCompCred(xname)
+}
+
+// plannedCredentialStore hands out credentials through getCompCred so that the shared discovery logic sees the
+// credentials a dry run would have stored.
+type plannedCredentialStore struct{}
+
+func (plannedCredentialStore) GetCompCred(xname string) (compcredentials.CompCredentials, error) {
+	return getCompCred(xname)
+}
+
+func (plannedCredentialStore) GetSwitchCommunity(xname string) (switches.SwitchCommunity, error) {
+	return getSwitchCommunity(xname)
+}
+
+// makeWrite journals a write and then makes it, the write is never made if it can't be journaled as there would be no
+// way to roll it back.
+func makeWrite(phase, operation, xname string, before, after interface{}, write func() error) error {
+	entry, err := mutationJournal.Append(runReport.RunID(), phase, operation, xname, before, after)
+	if err != nil {
+		return fmt.Errorf("failed to journal %s of %s, not making it: %w", operation, xname, err)
+	}
+
+	if err := write(); err != nil {
+		if discardErr := mutationJournal.Discard(entry); discardErr != nil {
+			logger.Error("Failed to remove journal entry for failed write!", zap.Error(discardErr),
+				zap.String("operation", operation), zap.String("xname", xname))
+		}
+		return err
+	}
+
+	return nil
+}
+
+// currentTarget gets what a write would replace, in the same form the write is recorded in, or nil if there is
+// nothing there. The EthernetInterfaces are passed in as they can only be looked up efficiently in bulk.
+func currentTarget(ctx context.Context, operation, xname string, after json.RawMessage,
+	ethernetInterfacesByMAC map[string]sm.CompEthInterfaceV2) (interface{}, error) {
+	switch operation {
+	case plan.OpStoreCompCredentials:
+		cred, err := hsmCredentialStore.GetCompCred(xname)
+		if err != nil || cred.Xname == "" {
+			return nil, err
+		}
+		return cred, nil
+
+	case plan.OpStorePDUCredentials:
+		device, err := pduCredentialStore.GetPDUCredentails(xname)
+		if err != nil || device.Xname == "" {
+			return nil, err
+		}
+		return device, nil
+
+	case plan.OpStoreSwitchCommunity:
+		community, err := redsCredentialStore.GetSwitchCommunity(xname)
+		if err != nil || community.Xname == "" {
+			return nil, err
+		}
+		return community, nil
+
+	case plan.OpAddEthernetInterface:
+		var planned sm.CompEthInterfaceV2
+		if err := json.Unmarshal(after, &planned); err != nil {
+			return nil, err
+		}
+
+		if current, found := ethernetInterfacesByMAC[planned.MACAddr]; found {
+			return current, nil
+		}
+		return nil, nil
+
+	case plan.OpCreateRedfishEndpoint:
+		endpoint, err := hsmClient.GetRedfishEndpoint(ctx, xname)
+		if errors.Is(err, hsm.ErrNotFound) {
+			return nil, nil
+		} else if err != nil {
+			return nil, err
+		}
+		return newPlannedRedfishEndpoint(endpoint), nil
+
+	case plan.OpCreateComponent:
+		component, err := hsmClient.GetComponent(ctx, xname)
+		if errors.Is(err, hsm.ErrNotFound) {
+			return nil, nil
+		} else if err != nil {
+			return nil, err
+		}
+		return component, nil
+
+	case plan.OpRediscover:
+		// Asking HSM to have another go at an endpoint doesn't replace anything.
+		return nil, nil
+	}
+
+	return nil, fmt.Errorf("unknown operation: %s", operation)
+}
+
+// ignoredFields are those HSM updates on its own when comparing what a write replaced, or will replace, with what is
+// there now. A difference in them doesn't mean anything discovery relies on has changed.
+func ignoredFields(operation string) []string {
+	switch operation {
+	case plan.OpAddEthernetInterface:
+		return []string{"LastUpdate"}
+	case plan.OpCreateComponent:
+		return []string{"Type", "State", "Flag"}
+	}
+
+	return nil
+}
+
+// storeCompCred puts credentials for an xname into Vault. The secrets map says where each secret came from, keyed by
+// the JSON field name in compcredentials.CompCredentials.
+func storeCompCred(phase string, cred compcredentials.CompCredentials, secrets map[string]string) error {
+	existing, err := hsmCredentialStore.GetCompCred(cred.Xname)
+	if err != nil {
+		return fmt.Errorf("failed to get current credentials: %w", err)
+	}
+
+	if !*dryRun {
+		var before interface{}
+		if existing.Xname != "" {
+			before = existing
+		}
+
+		return makeWrite(phase, plan.OpStoreCompCredentials, cred.Xname, before, cred, func() error {
+			return hsmCredentialStore.StoreCompCred(cred)
+		})
+	}
+
+	var (
+		before        interface{}
+		beforeSecrets []string
+	)
+	if existing.Xname != "" {
+		before = redactCompCred(existing)
+		beforeSecrets = compCredSecrets(existing)
+	}
+
+	if err := recordMutation(phase, plan.OpStoreCompCredentials, cred.Xname, before, redactCompCred(cred),
+		secrets, beforeSecrets...); err != nil {
+		return err
+	}
+
+	plannedCompCredsLock.Lock()
+	defer plannedCompCredsLock.Unlock()
+	plannedCompCreds[cred.Xname] = cred
+
+	return nil
+}
+
+func redactPDUCredentials(device pdu_credential_store.Device) pdu_credential_store.Device {
+	if device.Password != "" {
+		device.Password = plan.Redacted
+	}
+
+	return device
+}
+
+// pduCredentialSecrets are the values redactPDUCredentials hides.
+func pduCredentialSecrets(device pdu_credential_store.Device) []string {
+	return []string{device.Password}
+}
+
+func storePDUCredentials(phase string, device pdu_credential_store.Device) error {
+	existing, err := pduCredentialStore.GetPDUCredentails(device.Xname)
+	if err != nil {
+		return fmt.Errorf("failed to get current PDU credentials: %w", err)
+	}
+
+	if !*dryRun {
+		var before interface{}
+		if existing.Xname != "" {
+			before = existing
+		}
+
+		return makeWrite(phase, plan.OpStorePDUCredentials, device.Xname, before, device, func() error {
+			return pduCredentialStore.StorePDUCredentails(device)
+		})
+	}
+
+	var (
+		before        interface{}
+		beforeSecrets []string
+	)
+	if existing.Xname != "" {
+		before = redactPDUCredentials(existing)
+		beforeSecrets = pduCredentialSecrets(existing)
+	}
+
+	return recordMutation(phase, plan.OpStorePDUCredentials, device.Xname, before, redactPDUCredentials(device),
+		map[string]string{"password": secretDefaultPDUPassword()}, beforeSecrets...)
+}
+
+func redactSwitchCommunity(community switches.SwitchCommunity) switches.SwitchCommunity {
+	if community.SNMPCommunity != "" {
+		community.SNMPCommunity = plan.Redacted
+	}
+
+	return community
+}
+
+// switchCommunitySecrets are the values redactSwitchCommunity hides.
+func switchCommunitySecrets(community switches.SwitchCommunity) []string {
+	return []string{community.SNMPCommunity}
+}
+
+// getSwitchCommunity reads the SNMPv2c community of a switch, taking into account communities a dry run would have
+// stored.
+func getSwitchCommunity(xname string) (switches.SwitchCommunity, error) {
+	plannedCompCredsLock.Lock()
+	community, planned := plannedSwitchCommunities[xname]
+	plannedCompCredsLock.Unlock()
+
+	if planned {
+		return community, nil
+	}
+
+	return redsCredentialStore.GetSwitchCommunity(xname)
+}
+
+// storeSwitchCommunity puts the SNMPv2c community of a switch into Vault. The secrets map says where the community
+// came from, keyed by its JSON field name in switches.SwitchCommunity.
+func storeSwitchCommunity(phase string, community switches.SwitchCommunity, secrets map[string]string) error {
+	existing, err := redsCredentialStore.GetSwitchCommunity(community.Xname)
+	if err != nil {
+		return fmt.Errorf("failed to get current switch community: %w", err)
+	}
+
+	if !*dryRun {
+		var before interface{}
+		if existing.Xname != "" {
+			before = existing
+		}
+
+		return makeWrite(phase, plan.OpStoreSwitchCommunity, community.Xname, before, community, func() error {
+			return redsCredentialStore.StoreSwitchCommunity(community)
+		})
+	}
+
+	var (
+		before        interface{}
+		beforeSecrets []string
+	)
+	if existing.Xname != "" {
+		before = redactSwitchCommunity(existing)
+		beforeSecrets = switchCommunitySecrets(existing)
+	}
+
+	if err := recordMutation(phase, plan.OpStoreSwitchCommunity, community.Xname, before,
+		redactSwitchCommunity(community), secrets, beforeSecrets...); err != nil {
+		return err
+	}
+
+	plannedCompCredsLock.Lock()
+	defer plannedCompCredsLock.Unlock()
+	plannedSwitchCommunities[community.Xname] = community
+
+	return nil
+}
+
+// addNewEthernetInterface assigns an unknown component in HSM EthernetInterfaces to its xname, adding it to HSM when
+// it was found some other way, such as in the ARP table of a switch.
+func addNewEthernetInterface(ctx context.Context, phase string, ethernetInterface sm.CompEthInterfaceV2) error {
+	var before interface{}
+	current, err := hsmClient.GetEthernetInterface(ctx, ethernetInterface.MACAddr)
+	if err == nil {
+		before = current
+	} else if !errors.Is(err, hsm.ErrNotFound) {
+		return fmt.Errorf("failed to get current EthernetInterface: %w", err)
+	}
+
+	if !*dryRun {
+		return makeWrite(phase, plan.OpAddEthernetInterface, ethernetInterface.CompID, before, ethernetInterface,
+			func() error {
+				return hsmClient.UpsertEthernetInterface(ctx, ethernetInterface)
+			})
+	}
+
+	return recordMutation(phase, plan.OpAddEthernetInterface, ethernetInterface.CompID, before, ethernetInterface,
+		nil)
+}
+
+// plannedRedfishEndpoint is the part of a RedfishEndpoint discovery sets. The JSON matches rf.RedfishEPDescription.
+type plannedRedfishEndpoint struct {
+	ID             string `json:"ID"`
+	FQDN           string `json:"FQDN"`
+	MACAddr        string `json:"MACAddr,omitempty"`
+	RediscOnUpdate bool   `json:"RediscoverOnUpdate"`
+	Enabled        bool   `json:"Enabled"`
+}
+
+func newPlannedRedfishEndpoint(endpoint rf.RedfishEPDescription) plannedRedfishEndpoint {
+	return plannedRedfishEndpoint{
+		ID:             endpoint.ID,
+		FQDN:           endpoint.FQDN,
+		MACAddr:        endpoint.MACAddr,
+		RediscOnUpdate: endpoint.RediscOnUpdate,
+		Enabled:        endpoint.Enabled,
+	}
+}
+
+// patch changes only the fields discovery sets on a RedfishEndpoint.
+func (endpoint plannedRedfishEndpoint) patch() sm.RedfishEndpointPatch {
+	return sm.RedfishEndpointPatch{
+		FQDN:           &endpoint.FQDN,
+		MACAddr:        &endpoint.MACAddr,
+		RediscOnUpdate: &endpoint.RediscOnUpdate,
+		Enabled:        &endpoint.Enabled,
+	}
+}
+
+func (endpoint plannedRedfishEndpoint) redfishEndpoint() rf.RedfishEPDescription {
+	return rf.RedfishEPDescription{
+		ID:             endpoint.ID,
+		FQDN:           endpoint.FQDN,
+		MACAddr:        endpoint.MACAddr,
+		RediscOnUpdate: endpoint.RediscOnUpdate,
+		Enabled:        endpoint.Enabled,
+	}
+}
+
+// createRedfishEndpoint tells HSM about a BMC so it will go and discover it.
+func createRedfishEndpoint(ctx context.Context, phase string, endpoint rf.RedfishEPDescription) error {
+	before, err := currentTarget(ctx, plan.OpCreateRedfishEndpoint, endpoint.ID, nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get current RedfishEndpoint: %w", err)
+	}
+
+	if !*dryRun {
+		return makeWrite(phase, plan.OpCreateRedfishEndpoint, endpoint.ID, before,
+			newPlannedRedfishEndpoint(endpoint), func() error {
+				return hsmClient.UpsertRedfishEndpoint(ctx, endpoint)
+			})
+	}
+
+	return recordMutation(phase, plan.OpCreateRedfishEndpoint, endpoint.ID, before,
+		newPlannedRedfishEndpoint(endpoint), nil)
+}
+
+func createComponent(ctx context.Context, phase string, component base.Component) error {
+	before, err := currentTarget(ctx, plan.OpCreateComponent, component.ID, nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get current component: %w", err)
+	}
+
+	if !*dryRun {
+		return makeWrite(phase, plan.OpCreateComponent, component.ID, before, component, func() error {
+			return hsmClient.CreateComponent(ctx, component)
+		})
+	}
+
+	return recordMutation(phase, plan.OpCreateComponent, component.ID, before, component, nil)
+}
@@ -0,0 +1,126 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Cray-HPE/hms-discovery/pkg/offline"
+	"github.com/Cray-HPE/hms-discovery/pkg/pdu_credential_store"
+	"github.com/Cray-HPE/hms-discovery/pkg/switches"
+	"go.uber.org/zap"
+)
+
+/*
+Offline mode reproduces a run from files captured on a system, without SLS, HSM, Vault, BMCs or switches:
+
+	hms_discovery --offline \
+		--offline_sls_file sls_dump.json \
+		--offline_hsm_file hsm_snapshot.json \
+		--snmp_mock_dir switches/ \
+		plan -o plan.json
+
+SLS and HSM are read from the files, the MAC address tables of the switches from the canned switch data in
+snmp_mock_dir or, with snmp_mode=replay, from the SNMP walks recorded in snmp_fixture_dir, and every BMC is treated as reachable with Redfish. Vault starts out empty other than placeholder default
+credentials. It is always a dry run, so the result is the plan and the run report.
+*/
+
+// Placeholders so the phases that need default credentials have some, they only ever end up redacted in the plan.
+const (
+	offlineUsername = "offline"
+	offlinePassword = "offline-password"
+)
+
+// prepareOffline checks that offline mode can be used with command and sets everything it implies.
+func prepareOffline(command string) error {
+	switch {
+	case command == "apply" || command == "rollback":
+		return fmt.Errorf("%s can't be used in offline mode as it changes HSM and Vault", command)
+	case *daemonMode:
+		return fmt.Errorf("daemon mode can't be used in offline mode")
+	}
+
+	*dryRun = true
+	if !strings.EqualFold(*snmpMode, "replay") {
+		*snmpMode = "mock"
+	}
+
+	return nil
+}
+
+// setupOffline points discovery at the offline files instead of the live services.
+func setupOffline() error {
+	slsState, err := offline.LoadSLS(*offlineSLSFile)
+	if err != nil {
+		return fmt.Errorf("failed to load SLS dump: %w", err)
+	}
+
+	hsmSnapshot, err := offline.LoadHSM(*offlineHSMFile)
+	if err != nil {
+		return fmt.Errorf("failed to load HSM snapshot: %w", err)
+	}
+
+	httpClient.HTTPClient.Transport = &offline.Transport{
+		SLS: slsState,
+		HSM: hsmSnapshot,
+	}
+
+	setupCredentialStores(offline.NewStorage())
+
+	err = redsCredentialStore.StoreDefaultCredentials(map[string]switches.RedsCredentials{
+		"Cray": {Username: offlineUsername, Password: offlinePassword},
+	})
+	if err != nil {
+		return err
+	}
+
+	err = redsCredentialStore.StoreDefaultSwitchCredentials(switches.SwitchCredentials{
+		SNMPUsername:     offlineUsername,
+		SNMPAuthPassword: offlinePassword,
+		SNMPPrivPassword: offlinePassword,
+		SNMPCommunity:    offlinePassword,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = pduCredentialStore.StoreDefaultPDUCredentails(pdu_credential_store.DefaultCredential{
+		Username: offlineUsername,
+		Password: offlinePassword,
+	})
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Running offline.",
+		zap.String("offlineSLSFile", *offlineSLSFile),
+		zap.String("offlineHSMFile", *offlineHSMFile),
+		zap.String("snmpMockDir", *snmpMockDir),
+		zap.Int("slsHardware", len(slsState.Hardware)),
+		zap.Int("hsmComponents", len(hsmSnapshot.Components)),
+		zap.Int("hsmRedfishEndpoints", len(hsmSnapshot.RedfishEndpoints)),
+		zap.Int("hsmEthernetInterfaces", len(hsmSnapshot.EthernetInterfaces)))
+
+	return nil
+}
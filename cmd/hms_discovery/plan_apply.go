@@ -0,0 +1,311 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+	compcredentials "github.com/Cray-HPE/hms-compcredentials"
+	"github.com/Cray-HPE/hms-discovery/pkg/pdu_credential_store"
+	"github.com/Cray-HPE/hms-discovery/pkg/plan"
+	"github.com/Cray-HPE/hms-discovery/pkg/switches"
+	rf "github.com/Cray-HPE/hms-smd/v2/pkg/redfish"
+	"github.com/Cray-HPE/hms-smd/v2/pkg/sm"
+	"github.com/namsral/flag"
+	"go.uber.org/zap"
+)
+
+/*
+The plan and apply commands split a run in two so the changes can be reviewed before they are made:
+
+	hms_discovery plan -o plan.json
+	hms_discovery apply plan.json
+
+Planning is a dry run of every enabled phase. Applying makes exactly the changes in the plan, reading the secrets left
+out of it from where they were found when planning, and refuses to do anything if SLS has changed at all or if
+anything the plan touches in HSM or Vault no longer looks like it did when the plan was made.
+*/
+
+func runPlanCommand(ctx context.Context, args []string) int {
+	planFlags := flag.NewFlagSetWithEnvPrefix("plan", "PLAN", flag.ExitOnError)
+	output := planFlags.String("o", "plan.json", "File to write the plan to")
+	planFlags.Parse(args)
+
+	*dryRun = true
+	// The plan is written below so a failure to write it can be reported.
+	*planPath = ""
+
+	startRun()
+	runPhases(ctx, enabledPhases(), false)
+	runResult := finishRun()
+
+	finishedPlan := planRecorder.Plan()
+	if finishedPlan.SLSFingerprint == "" {
+		logger.Error("Plan has no SLS fingerprint, not writing it.")
+		return 1
+	}
+
+	if err := finishedPlan.WriteFile(*output); err != nil {
+		logger.Error("Failed to write plan!", zap.Error(err), zap.String("output", *output))
+		return 1
+	}
+	logger.Info("Wrote plan.", zap.String("output", *output), zap.Int("mutations", len(finishedPlan.Mutations)))
+
+	if runResult.Failed() {
+		logger.Error("One or more phases or devices failed while planning, the plan may be incomplete.",
+			zap.String("runID", runResult.RunID))
+		return 1
+	}
+
+	return 0
+}
+
+func runApplyCommand(ctx context.Context, args []string) int {
+	applyFlags := flag.NewFlagSetWithEnvPrefix("apply", "APPLY", flag.ExitOnError)
+	applyFlags.Parse(args)
+
+	if applyFlags.NArg() != 1 {
+		logger.Error("Usage: hms_discovery apply <plan file>")
+		return 2
+	}
+	planFile := applyFlags.Arg(0)
+
+	appliedPlan, err := plan.ReadFile(planFile)
+	if err != nil {
+		logger.Error("Failed to read plan!", zap.Error(err), zap.String("planFile", planFile))
+		return 1
+	}
+
+	fmt.Fprint(os.Stdout, appliedPlan.Diff())
+
+	if err := verifyPlan(ctx, appliedPlan); err != nil {
+		logger.Error("Refusing to apply plan!", zap.Error(err), zap.String("planFile", planFile))
+		return 1
+	}
+
+	startRun()
+	start := time.Now()
+	err = applyPlan(runContext(ctx), appliedPlan)
+	runReport.AddPhase("apply", start, time.Now(), err)
+	runResult := finishRun()
+
+	if err != nil {
+		logger.Error("Failed to apply plan!", zap.Error(err),
+			zap.String("planFile", planFile), zap.String("runID", runResult.RunID))
+		return 1
+	}
+
+	logger.Info("Applied plan.", zap.String("planFile", planFile), zap.String("planRunID", appliedPlan.RunID),
+		zap.Int("mutations", len(appliedPlan.Mutations)))
+	return 0
+}
+
+// verifyPlan checks that the state the plan was built from is still there.
+func verifyPlan(ctx context.Context, appliedPlan plan.Plan) error {
+	if appliedPlan.SLSFingerprint == "" {
+		return errors.New("plan does not record the SLS state it was built from")
+	}
+
+	// The secrets a rollback puts back only live in the journal, so a rollback is made by running it again.
+	for _, mutation := range appliedPlan.Mutations {
+		if mutation.Phase == rollbackPhase {
+			return errors.New("plan is of a rollback, run the rollback again without --dry_run instead")
+		}
+	}
+
+	slsFingerprint, err := getSLSFingerprint(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fingerprint SLS: %w", err)
+	}
+	if slsFingerprint != appliedPlan.SLSFingerprint {
+		return errors.New("SLS has changed since the plan was made")
+	}
+
+	// Every EthernetInterface is fetched once rather than once per mutation.
+	ethernetInterfaces, err := hsmClient.GetEthernetInterfaces(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get EthernetInterfaces from HSM: %w", err)
+	}
+	ethernetInterfacesByMAC := map[string]sm.CompEthInterfaceV2{}
+	for _, ethernetInterface := range ethernetInterfaces {
+		ethernetInterfacesByMAC[ethernetInterface.MACAddr] = ethernetInterface
+	}
+
+	var changed []string
+	for _, mutation := range appliedPlan.Mutations {
+		current, secretsDigest, err := currentMutationTarget(ctx, appliedPlan.RunID, mutation,
+			ethernetInterfacesByMAC)
+		if err != nil {
+			return fmt.Errorf("failed to get current state for %s of %s: %w", mutation.Operation, mutation.Xname, err)
+		}
+
+		unchanged, err := mutation.Unchanged(current, ignoredFields(mutation.Operation)...)
+		if err != nil {
+			return fmt.Errorf("failed to compare current state for %s of %s: %w",
+				mutation.Operation, mutation.Xname, err)
+		}
+		if !unchanged || secretsDigest != mutation.BeforeSecretsDigest {
+			changed = append(changed, mutation.Operation+" "+mutation.Xname)
+		}
+	}
+
+	if len(changed) != 0 {
+		return fmt.Errorf("HSM or Vault has changed since the plan was made: %s", strings.Join(changed, ", "))
+	}
+
+	return nil
+}
+
+// currentMutationTarget gets what is there now for a mutation in the same form as its Before, along with the digest of
+// any secrets redacted from it, salted with the ID of the run that made the plan.
+func currentMutationTarget(ctx context.Context, planRunID string, mutation plan.Mutation,
+	ethernetInterfacesByMAC map[string]sm.CompEthInterfaceV2) (interface{}, string, error) {
+	current, err := currentTarget(ctx, mutation.Operation, mutation.Xname, mutation.After, ethernetInterfacesByMAC)
+	if err != nil {
+		return nil, "", err
+	}
+
+	switch target := current.(type) {
+	case compcredentials.CompCredentials:
+		return redactCompCred(target), plan.SecretsDigest(planRunID, compCredSecrets(target)...), nil
+	case pdu_credential_store.Device:
+		return redactPDUCredentials(target), plan.SecretsDigest(planRunID, pduCredentialSecrets(target)...), nil
+	case switches.SwitchCommunity:
+		return redactSwitchCommunity(target), plan.SecretsDigest(planRunID, switchCommunitySecrets(target)...), nil
+	}
+
+	return current, "", nil
+}
+
+// applyPlan makes each change in the plan in order, stopping at the first failure as later changes depend on earlier
+// ones, such as a RedfishEndpoint needing its credentials to be in Vault.
+func applyPlan(ctx context.Context, appliedPlan plan.Plan) error {
+	for _, mutation := range appliedPlan.Mutations {
+		mutationLogger := logger.With(zap.String("operation", mutation.Operation),
+			zap.String("xname", mutation.Xname), zap.String("phase", mutation.Phase))
+
+		if err := applyMutation(ctx, mutation); err != nil {
+			return fmt.Errorf("failed to apply %s to %s: %w", mutation.Operation, mutation.Xname, err)
+		}
+
+		mutationLogger.Info("Applied change.")
+	}
+
+	return nil
+}
+
+func applyMutation(ctx context.Context, mutation plan.Mutation) error {
+	after, err := mutation.ResolveSecrets(resolveSecret)
+	if err != nil {
+		return err
+	}
+
+	switch mutation.Operation {
+	case plan.OpStoreCompCredentials:
+		var cred compcredentials.CompCredentials
+		if err := json.Unmarshal(after, &cred); err != nil {
+			return err
+		}
+		return storeCompCred(mutation.Phase, cred, mutation.Secrets)
+
+	case plan.OpStorePDUCredentials:
+		var device pdu_credential_store.Device
+		if err := json.Unmarshal(after, &device); err != nil {
+			return err
+		}
+		return storePDUCredentials(mutation.Phase, device)
+
+	case plan.OpStoreSwitchCommunity:
+		var community switches.SwitchCommunity
+		if err := json.Unmarshal(after, &community); err != nil {
+			return err
+		}
+		return storeSwitchCommunity(mutation.Phase, community, mutation.Secrets)
+
+	case plan.OpAddEthernetInterface:
+		var ethernetInterface sm.CompEthInterfaceV2
+		if err := json.Unmarshal(after, &ethernetInterface); err != nil {
+			return err
+		}
+		return addNewEthernetInterface(ctx, mutation.Phase, ethernetInterface)
+
+	case plan.OpCreateRedfishEndpoint:
+		var endpoint rf.RedfishEPDescription
+		if err := json.Unmarshal(after, &endpoint); err != nil {
+			return err
+		}
+		return informHSM(ctx, mutation.Phase, endpoint.ID, endpoint.FQDN, endpoint.MACAddr)
+
+	case plan.OpCreateComponent:
+		var component base.Component
+		if err := json.Unmarshal(after, &component); err != nil {
+			return err
+		}
+		return createComponent(ctx, mutation.Phase, component)
+
+	case plan.OpRediscover:
+		return reDiscoverEndpoints(ctx, []string{mutation.Xname})
+	}
+
+	return fmt.Errorf("unknown operation: %s", mutation.Operation)
+}
+
+// resolveSecret reads a secret left out of a plan from where it was found when planning.
+func resolveSecret(ref plan.SecretRef) (string, error) {
+	object := map[string]interface{}{}
+
+	switch ref.Source {
+	case plan.SecretSourceVault:
+		if err := secureStorage.Lookup(ref.Location, &object); err != nil {
+			return "", err
+		}
+
+	case plan.SecretSourceSLS:
+		snapshot, err := slsClient.Snapshot(context.Background())
+		if err != nil {
+			return "", err
+		}
+
+		slsHardware, found := snapshot.Get(ref.Location)
+		if !found {
+			return "", fmt.Errorf("%s not found in SLS", ref.Location)
+		}
+
+		extraPropertiesBytes, err := json.Marshal(slsHardware.ExtraPropertiesRaw)
+		if err != nil {
+			return "", err
+		}
+		if err := json.Unmarshal(extraPropertiesBytes, &object); err != nil {
+			return "", err
+		}
+	}
+
+	return ref.Lookup(object)
+}
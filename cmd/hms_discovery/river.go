@@ -1,6 +1,6 @@
 // MIT License
 //
-// (C) Copyright [2020-2022,2024-2025] Hewlett Packard Enterprise Development LP
+// (C) Copyright [2020-2022,2024-2026] Hewlett Packard Enterprise Development LP
 //
 // Permission is hereby granted, free of charge, to any person obtaining a
 // copy of this software and associated documentation files (the "Software"),
@@ -23,40 +23,38 @@
 package main
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"fmt"
-	"io/ioutil"
-	"net/http"
-	"os"
+	"io"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
-	base "github.com/Cray-HPE/hms-base/v2"
 	compcredentials "github.com/Cray-HPE/hms-compcredentials"
+	"github.com/Cray-HPE/hms-discovery/pkg/discovery"
+	"github.com/Cray-HPE/hms-discovery/pkg/report"
+	"github.com/Cray-HPE/hms-discovery/pkg/sls"
 	"github.com/Cray-HPE/hms-discovery/pkg/snmp_utilities"
 	"github.com/Cray-HPE/hms-discovery/pkg/switches"
-	sls_common "github.com/Cray-HPE/hms-sls/v2/pkg/sls-common"
-	rf "github.com/Cray-HPE/hms-smd/v2/pkg/redfish"
 	"github.com/Cray-HPE/hms-smd/v2/pkg/sm"
 	"github.com/Cray-HPE/hms-xname/xnametypes"
-	"github.com/hashicorp/go-retryablehttp"
-	"github.com/mitchellh/mapstructure"
 	"go.uber.org/zap"
 )
 
-const VaultPrefix = "vault://"
-
-func doRiverDiscovery() {
+func doRiverDiscovery(ctx context.Context) error {
 	// Get the unknown components from HSM.
-	unknownComponents, getErr := dhcpdnsClient.GetUnknownComponents()
+	unknownComponents, getErr := discoveryClient.HSM.GetUnknownEthernetInterfaces(ctx)
 	if getErr != nil {
-		logger.Error("Unable to get unknown components!", zap.Error(getErr))
+		return fmt.Errorf("unable to get unknown components: %w", getErr)
 	}
+	unknownComponentsFound.Set(float64(len(unknownComponents)))
 
-	// Nothing to do here?
-	if len(unknownComponents) == 0 {
+	// Nothing to do here? Unless the ARP tables of the switches might turn up something HSM doesn't know about.
+	if len(unknownComponents) == 0 && !*snmpARP {
 		logger.Info("No unknown components to discover.")
-		return
+		recordRiverDiscoveryStatus(nil, nil, nil)
+		return nil
 	}
 
 	logger.Debug("Found undiscovered components, attempting to identify.",
@@ -65,101 +63,57 @@ func doRiverDiscovery() {
 	// Gather the default credentials for the BMCs in case we need them.
 	defaultCredentials, credsErr := redsCredentialStore.GetDefaultCredentials()
 	if credsErr != nil {
-		// Unfortunately this has to be fatal as we can do all the rest of the work but if we can't authentication
-		// it's for nothing.
-		logger.Fatal("Failed to get default BMC credentials!", zap.Error(credsErr))
+		// We could do all the rest of the work but if we can't authenticate it's for nothing.
+		return fmt.Errorf("failed to get default BMC credentials: %w", credsErr)
 	}
 
 	// Make sure we actually got something.
 	if defaultCredentials["Cray"].Username == "" || defaultCredentials["Cray"].Password == "" {
-		logger.Fatal("Default Cray credentials blank for either username or password!")
+		return fmt.Errorf("default Cray credentials blank for either username or password")
 	}
 
 	// Ah crap, somebody expects us to work I guess. Ok, let's get the info we need from the switches.
-	managementSwitches, switchErr := getSwitches()
+	switchQueries, queryErr := sls.ParseQueries(*snmpSwitches)
+	if queryErr != nil {
+		return fmt.Errorf("invalid snmp_switches: %w", queryErr)
+	}
+	// Switches SLS has broken entries for are logged and left out.
+	managementSwitches, _, switchErr := discoveryClient.GetSwitches(ctx, switchQueries...)
 	if switchErr != nil {
-		logger.Error("Unable to get switches!", zap.Error(switchErr))
+		return fmt.Errorf("unable to get switches: %w", switchErr)
 	}
 
 	// What we need is a mapping of all the switches by their name and their port mappings,
 	// then we can process the unknown hardware.
-	switchPortMapping := make(map[string]map[string]string)
-
-	// For each of the switches get their port mappings. If any part fails for a given switch we won't call the
-	// whole thing a failure and instead try to move on to the next switch.
-	for _, managementSwitch := range managementSwitches {
-		switchLogger := logger.With(
-			zap.String("managementSwitchXname", managementSwitch.Xname),
-			zap.Strings("managementSwitchAliases", managementSwitch.Aliases))
-
-		snmp, snmpErr := snmp_utilities.GetSNMPOjbect(managementSwitch)
-		if snmpErr != nil {
-			switchLogger.Warn("Unable to get SNMP object for management switch!",
-				zap.Error(snmpErr),
-			)
-
-			continue
-		}
-
-		switchLogger.Debug("Generated SNMP object for switch.", zap.Any("snmp", snmp))
-
-		// Setup an instance of an inteface to use to get the rest of the data.
-		var snmpInterface snmp_utilities.SNMPInterface
-		snmpMode := os.Getenv("SNMP_MODE")
-		if snmpMode == "MOCK" {
-			snmpInterface = snmp_utilities.MockSNMP{
-				SwitchXname: managementSwitch.Xname,
-			}
-			switchLogger.Debug("Using mock SNMP interface.")
-		} else {
-			snmpInterface = snmp_utilities.RealSNMP{
-				SNMP: snmp,
-			}
-			switchLogger.Debug("Using production SNMP interface.")
-		}
-
-		// Get a mapping of interface indexes to names.
-		portMap, portMapErr := snmpInterface.GetPortMap()
-		if portMapErr != nil {
-			switchLogger.Warn("Failed to get port map for management switch!",
-				zap.Error(portMapErr),
-			)
+	walkedSwitches, walkErr := walkSwitches(ctx, managementSwitches)
+	if walkErr != nil {
+		return walkErr
+	}
 
-			continue
+	// Devices that never ask DHCP for an address, such as BMCs with a static IP address, never make it into HSM on
+	// their own. The switches they talk through are the only place to find them.
+	arpComponents := map[string]bool{}
+	if *snmpARP {
+		candidates, candidateErr := discoveryClient.ARPCandidates(ctx, walkedSwitches)
+		if candidateErr != nil {
+			logger.Error("Unable to find unknown components in the ARP tables of the switches!",
+				zap.Error(candidateErr))
 		}
 
-		switchLogger.Debug("Got port map from switch.", zap.Any("portMap", portMap))
-
-		// Next get a mapping of interface indexes to numbers.
-		portNumberMap, portNumberMapErr := snmpInterface.GetPortNumberMap()
-		if portNumberMapErr != nil {
-			switchLogger.Fatal("Failed to get port number map for management switch!",
-				zap.Error(portNumberMapErr),
-			)
-
-			continue
+		for _, candidate := range candidates {
+			arpComponents[candidate.ID] = true
 		}
+		unknownComponents = append(unknownComponents, candidates...)
 
-		switchLogger.Debug("Got port number map from switch.", zap.Any("portNumberMap", portNumberMap))
-
-		// Reverse the keys and values
-		portNumberIfIndexMap := make(map[int]int)
-		for key, val := range portNumberMap {
-			portNumberIfIndexMap[val] = key
+		if len(candidates) > 0 {
+			logger.Info("Found components in the ARP tables of the switches that HSM has no EthernetInterface for.",
+				zap.Any("candidates", candidates))
 		}
-
-		// Now get the MAC addresses for all the ports on this switch.
-		macPortMap, macPortErr := snmpInterface.GetMACPortNameTable(portNumberIfIndexMap, portMap)
-		if macPortErr != nil {
-			switchLogger.Warn("Unable to get MAC to port mapping for switch!", zap.Error(macPortErr))
-
-			continue
+		if len(unknownComponents) == 0 {
+			logger.Info("No unknown components to discover.")
+			recordRiverDiscoveryStatus(nil, nil, nil)
+			return nil
 		}
-
-		switchLogger.Debug("Got MAC port map from switch.", zap.Any("macPortMap", macPortMap))
-
-		// Add this switch to the master map.
-		switchPortMapping[managementSwitch.Xname] = macPortMap
 	}
 
 	// Keep track of the xnames we successfully and unsuccessfully process.
@@ -168,18 +122,38 @@ func doRiverDiscovery() {
 	var remainingUnknownComponents []sm.CompEthInterfaceV2
 
 	// Finally we can process all of the unknown hardware.
+	var interruptErr error
 	for _, unknownComponent := range unknownComponents {
+		// Stop between components rather than part way through one so HSM and Vault are never left half updated.
+		if ctx.Err() != nil {
+			interruptErr = fmt.Errorf("river discovery interrupted with unknown components left to process: %w",
+				ctx.Err())
+			break
+		}
+
 		logger.Debug("Searching for unknown component.", zap.Any("unknownComponent", unknownComponent))
 
 		macWithoutPunctuation := strings.ReplaceAll(unknownComponent.MACAddr, ":", "")
 
+		device := report.Device{
+			Phase:      "river",
+			MACAddress: unknownComponent.MACAddr,
+			Action:     report.ActionNotFoundInSwitches,
+		}
+		if arpComponents[unknownComponent.ID] {
+			device.Source = report.SourceSwitchARP
+		}
+		if len(unknownComponent.IPAddrs) > 0 {
+			device.IPAddress = unknownComponent.IPAddrs[0].IPAddr
+		}
+
 		// Find the switch and port this MAC belongs to.
 		var port string
 		var switchFound bool
 		globallyFound := false
 
-		for managementSwitchXname, switchMacPortMap := range switchPortMapping {
-			port, switchFound = switchMacPortMap[macWithoutPunctuation]
+		for managementSwitchXname, walked := range walkedSwitches {
+			port, switchFound = walked.MACPortMap[macWithoutPunctuation]
 
 			// If this switch doesn't have this MAC continue to the next switch.
 			if !switchFound {
@@ -191,13 +165,18 @@ func doRiverDiscovery() {
 				zap.String("managementSwitchXname", managementSwitchXname),
 				zap.String("port", port),
 			)
+			device.SwitchXname = managementSwitchXname
+			device.SwitchPort = walked.VendorName(port)
+			device.Action = report.ActionUnresolved
 
 			// Great, we found it! Now do a reverse lookup with SLS to figure out the identity.
-			xname, slsErr := getXnameForSwitchPort(managementSwitchXname, port)
+			xname, connector, slsErr := discoveryClient.ResolveSwitchPort(ctx, walkedSwitches, managementSwitchXname,
+				port)
 			if slsErr != nil {
 				logger.Warn("Failed to lookup xname for switch/port combination.",
 					zap.String("managementSwitchXname", managementSwitchXname),
 					zap.String("port", port),
+					zap.Error(slsErr),
 				)
 
 				// If we fail that's not necessarily the end of the world. Since these are layer 2 networks it's
@@ -205,24 +184,35 @@ func doRiverDiscovery() {
 				// only one switch will have the correct port mapping that corresponds to what SLS has.
 				continue
 			}
-			logger.Info("msg", zap.String("Xname", xname))
+			logger.Debug("Resolved switch port to xname.", zap.String("xname", xname))
+			device.Xname = xname
+
+			// MAC addresses learned on a LAG are cabled to one of its members, possibly on the MLAG peer.
+			if _, isLAG := walked.Ports.LAGs[port]; isLAG {
+				device.SwitchLAG = port
+			}
+			managementSwitchXname, port = connector.SwitchXname, connector.Port
+			device.SwitchXname = managementSwitchXname
+			device.SwitchPort = port
 
 			// If we've made it here we know exactly what this BMC is. Therefore any failure from this point on will
 			// be treated as "fatal" for this device rather than just a continue.
 
 			if xnametypes.GetHMSType(xname) == xnametypes.CabinetPDUController {
 				pduType, _ := getPDUType(unknownComponent)
+				device.PDUType = pduTypeString(pduType)
 				switch pduType {
 				case pduRTS:
 					logger.Info("Found RTS PDU", zap.String("xname", xname))
 					// ServerTech PDUs are discovered differently then other types of hardware, as they do not talk native Redfish.
-					if informErr := informRTS(xname, xname, macWithoutPunctuation, unknownComponent); informErr != nil {
+					if informErr := informRTS(ctx, xname, xname, macWithoutPunctuation, unknownComponent); informErr != nil {
 						logger.Error("Failed to notify RTS about PDU!",
 							zap.Error(informErr),
 							zap.String("xname", xname),
 						)
 
 						failedXnames = append(failedXnames, xname)
+						device = device.Failure(informErr)
 						break
 					}
 
@@ -234,6 +224,7 @@ func doRiverDiscovery() {
 
 					globallyFound = true
 					discoveredXnames = append(discoveredXnames, xname)
+					device.Action = report.ActionInformedRTS
 				case pduRedfish:
 					logger.Info("Found Redfish PDU", zap.String("xname", xname))
 					// Redfish PDU continue with discovery
@@ -243,7 +234,7 @@ func doRiverDiscovery() {
 				}
 			}
 
-			creds, credsErr := hsmCredentialStore.GetCompCred(xname)
+			creds, credsErr := getCompCred(xname)
 			if credsErr != nil {
 				logger.Info("Using the default creds, because there was a failure reading the creds from vault",
 					zap.String("xname", xname),
@@ -251,24 +242,29 @@ func doRiverDiscovery() {
 			}
 
 			if (creds.Xname == "" && creds.Username == "") || credsErr != nil {
+				device.CredentialSource = report.CredentialSourceDefault
+
 				// Put the creds in Vault.
 				compCred := compcredentials.CompCredentials{
 					Xname:    xname,
 					Username: defaultCredentials["Cray"].Username,
 					Password: defaultCredentials["Cray"].Password,
 				}
-				compCredErr := hsmCredentialStore.StoreCompCred(compCred)
+				compCredErr := storeCompCred("river", compCred,
+					map[string]string{"password": secretDefaultBMCPassword()})
 				if compCredErr != nil {
-					logger.Fatal("Failed to store BMC credentials!",
+					logger.Error("Failed to store BMC credentials!",
 						zap.Error(compCredErr),
 						zap.String("xname", xname),
 					)
 
 					failedXnames = append(failedXnames, xname)
+					device = device.Failure(compCredErr)
 
 					break
 				}
 			} else {
+				device.CredentialSource = report.CredentialSourceVault
 				logger.Info("Not writing default creds, because existing creds were found in vault.",
 					zap.String("xname", xname),
 					zap.String("username", creds.Username))
@@ -279,22 +275,28 @@ func doRiverDiscovery() {
 
 			// Check to see if it's Redfish is endpoint is reachable.
 			// If Redfish is not reachable then the EthernetInterface in HSM will remain unchanged.
-			reachableErr := checkBMCRedfish(unknownComponent.CompID, unknownComponent.IPAddrs[0].IPAddr)
+			reachableErr := discoveryClient.CheckBMCRedfish(unknownComponent.CompID, unknownComponent.IPAddrs[0].IPAddr)
 			if reachableErr != nil {
 				logger.Warn("Redfish not reachable at IP address, not processing further!",
 					zap.Error(reachableErr),
 					zap.String("xname", unknownComponent.CompID),
 					zap.String("ipaddress", unknownComponent.IPAddrs[0].IPAddr),
 					zap.String("macAddress", unknownComponent.MACAddr))
+				device.RedfishProbe = reachableErr.Error()
+				if !device.Failed && device.Action != report.ActionInformedRTS {
+					device.Action = report.ActionRedfishUnreachable
+				}
 				break
 			}
+			device.RedfishProbe = "reachable"
 
 			// Add the new ethernet interface.
-			addErr := dhcpdnsClient.AddNewEthernetInterface(unknownComponent, true)
+			addErr := addNewEthernetInterface(ctx, "river", unknownComponent)
 
 			if addErr != nil {
 				logger.Error("Failed to add new ethernet interface to HSM, not processing further!",
 					zap.Error(addErr), zap.Any("unknownComponent", unknownComponent))
+				device = device.Failure(addErr)
 				break
 			} else {
 				logger.Info("Updated ethernet interface in HSM.",
@@ -302,7 +304,7 @@ func doRiverDiscovery() {
 			}
 
 			// ...and finally tell HSM to go discover.
-			informErr := informHSM(xname, xname, macWithoutPunctuation)
+			informErr := informHSM(ctx, "river", xname, xname, macWithoutPunctuation)
 			if informErr != nil {
 				logger.Error("Failed to notify HSM about endpoint!",
 					zap.Error(informErr),
@@ -310,6 +312,7 @@ func doRiverDiscovery() {
 				)
 
 				failedXnames = append(failedXnames, xname)
+				device = device.Failure(informErr)
 
 				break
 			}
@@ -322,12 +325,22 @@ func doRiverDiscovery() {
 
 			globallyFound = true
 			discoveredXnames = append(discoveredXnames, xname)
+			if !device.Failed {
+				device.Action = report.ActionDiscovered
+			}
 
 			break
 		}
 
+		runReport.AddDevice(device)
+
 		// Did we find this MAC in any switches successfully?
-		if !globallyFound {
+		if globallyFound {
+			riverMACs.WithLabelValues("resolved").Inc()
+			riverLastMACResolved.SetToCurrentTime()
+		} else {
+			riverMACs.WithLabelValues("unresolved").Inc()
+
 			logger.Error("MAC address in HSM not found in any switch!",
 				zap.Any("unknownComponent", unknownComponent),
 			)
@@ -341,251 +354,279 @@ func doRiverDiscovery() {
 		zap.Strings("discoveredXnames", discoveredXnames),
 		zap.Strings("failedXnames", failedXnames),
 		zap.Any("remainingUnknownComponents", remainingUnknownComponents))
+
+	recordRiverDiscoveryStatus(discoveredXnames, failedXnames, remainingUnknownComponents)
+
+	return interruptErr
 }
 
-func checkBMCRedfish(xname string, fqdn string) (err error) {
-	// Endpoint might require authentication, get what we need.
-	creds, credsErr := hsmCredentialStore.GetCompCred(xname)
-	if credsErr != nil {
-		// Credential error...log it but don't stop, might not need the authentication.
-		logger.Error("Failed to get credentials from Vault for xname!",
-			zap.String("xname", xname), zap.Error(credsErr))
-	}
+// walkSwitches gets the port mappings of every switch, up to snmp_concurrency of them at a time. If any part fails
+// for a given switch we won't call the whole thing a failure and instead leave that switch out.
+func walkSwitches(ctx context.Context,
+	managementSwitches []switches.ManagementSwitch) (map[string]discovery.WalkedSwitch, error) {
+	walkedSwitches := make(map[string]discovery.WalkedSwitch)
+	var walkedSwitchesLock sync.Mutex
 
-	var redfishURLs []string
-	redfishURLs = append(redfishURLs, fmt.Sprintf("https://%s/redfish/v1", fqdn))
-	redfishURLs = append(redfishURLs, fmt.Sprintf("https://%s/redfish/v1/", fqdn))
+	workers := make(chan struct{}, *snmpConcurrency)
+	var switchWaitGroup sync.WaitGroup
 
-	for _, redfishURL := range redfishURLs {
+	for _, managementSwitch := range managementSwitches {
+		switchWaitGroup.Add(1)
+
+		go func(managementSwitch switches.ManagementSwitch) {
+			defer switchWaitGroup.Done()
+
+			workers <- struct{}{}
+			defer func() { <-workers }()
+
+			// Whatever is wrong with one switch must not stop the others from being walked.
+			walkStart := time.Now()
+			defer func() {
+				if recovered := recover(); recovered != nil {
+					logger.Error("Walking management switch panicked, leaving it out!",
+						zap.String("managementSwitchXname", managementSwitch.Xname),
+						zap.Any("panic", recovered))
+					recordSwitchWalkFailure(report.Switch{
+						Xname:      managementSwitch.Xname,
+						ErrorClass: string(snmp_utilities.ErrorClassOther),
+					}, walkStart, fmt.Errorf("panic: %v", recovered))
+				}
+			}()
 
-		//req.SetBasicAuth(request.Auth.Username, request.Auth.Password)
-		request, requestErr := retryablehttp.NewRequest("GET", redfishURL, nil)
-		if requestErr != nil {
-			err = fmt.Errorf("failed to make request: %w", requestErr)
-			continue
-		}
-		request.SetBasicAuth(creds.Username, creds.Password)
+			// Switches still waiting for a worker when the phase is cancelled aren't started at all.
+			if ctx.Err() != nil {
+				return
+			}
 
-		response, doErr := httpClient.Do(request)
-		base.DrainAndCloseResponseBody(response)
-		if doErr != nil {
-			err = fmt.Errorf("failed to execute GET request: %w", doErr)
-			continue
-		}
+			walked, ok := walkSwitch(ctx, managementSwitch)
+			if ok {
+				walkedSwitchesLock.Lock()
+				walkedSwitches[managementSwitch.Xname] = walked
+				walkedSwitchesLock.Unlock()
+			}
+		}(managementSwitch)
+	}
 
-		if response.StatusCode != http.StatusOK {
-			err = fmt.Errorf("unexpected status code from Redfish: %d", response.StatusCode)
-			continue
-		} else {
-			return nil
-		}
+	switchWaitGroup.Wait()
+
+	if ctx.Err() != nil {
+		return nil, fmt.Errorf("river discovery interrupted while walking switches: %w", ctx.Err())
 	}
 
-	return
+	return walkedSwitches, nil
 }
 
-func informHSM(xname string, fqdn string, macAddr string) (err error) {
-	hsmEPDescritption := rf.RedfishEPDescription{
-		ID:             xname,
-		FQDN:           fqdn,
-		MACAddr:        macAddr,
-		RediscOnUpdate: true,
-		Enabled:        true,
+// walkSwitch gets the MAC to port mapping of a switch over a single SNMP session, returning false if it can't.
+func walkSwitch(ctx context.Context, managementSwitch switches.ManagementSwitch) (discovery.WalkedSwitch, bool) {
+	switchLogger := logger.With(
+		zap.String("managementSwitchXname", managementSwitch.Xname),
+		zap.Strings("managementSwitchAliases", managementSwitch.Aliases))
+
+	walkStart := time.Now()
+	switchReport := report.Switch{
+		Xname:    managementSwitch.Xname,
+		SLSBrand: managementSwitch.Brand,
+		SLSModel: managementSwitch.Model,
 	}
 
-	payloadBytes, marshalErr := json.Marshal(hsmEPDescritption)
-	if marshalErr != nil {
-		err = fmt.Errorf("failed to marshal HSM endpoint description: %w", marshalErr)
-		return
+	snmpInterface, snmpErr := newSNMPInterface(managementSwitch, switchLogger)
+	if snmpErr != nil {
+		switchLogger.Error("Unable to get SNMP object for management switch!",
+			zap.Error(snmpErr),
+			zap.String("errorClass", string(snmp_utilities.ErrorClassConfiguration)),
+		)
+		switchReport.ErrorClass = string(snmp_utilities.ErrorClassConfiguration)
+		runReport.AddSwitch(switchReport, walkStart, time.Now(), snmpErr)
+		snmpSwitchErrors.WithLabelValues(managementSwitch.Xname, switchReport.ErrorClass).Inc()
+
+		return discovery.WalkedSwitch{}, false
 	}
-
-	hsmURL := fmt.Sprintf("%s/Inventory/RedfishEndpoints", *hsmURL)
-	request, requestErr := retryablehttp.NewRequest("POST", hsmURL, bytes.NewBuffer(payloadBytes))
-	if requestErr != nil {
-		err = fmt.Errorf("failed to construct request: %w", requestErr)
-		return
+	if closer, ok := snmpInterface.(io.Closer); ok {
+		defer closer.Close()
 	}
-	request.Header.Set("Content-Type", "application/json")
 
-	response, doErr := httpClient.Do(request)
-	base.DrainAndCloseResponseBody(response)
-	if doErr != nil {
-		err = fmt.Errorf("failed to execute POST request: %w", doErr)
-		return
+	identity, profile, identifyErr := discoveryClient.IdentifySwitch(snmpInterface, managementSwitch)
+	if errorClass := snmp_utilities.ClassifyError(identifyErr); errorClass.Unusable() {
+		// Nothing else is going to work either, so don't wait for every other walk to fail the same way.
+		switchLogger.Error("Unable to walk management switch!",
+			zap.Error(identifyErr),
+			zap.String("errorClass", string(errorClass)),
+		)
+		switchReport.Profile = profile.Name
+		switchReport.Vendor = profile.Vendor
+		switchReport.ErrorClass = string(errorClass)
+		recordSwitchWalkFailure(switchReport, walkStart, identifyErr)
+
+		return discovery.WalkedSwitch{}, false
 	}
-
-	if response.StatusCode == http.StatusConflict {
-		// If we're in conflict (which is almost provably impossible given we start with unknown devices),
-		// then PATCH the entry.
-		request.Method = "PATCH"
-
-		response, doErr := httpClient.Do(request)
-		base.DrainAndCloseResponseBody(response)
-		if doErr != nil {
-			err = fmt.Errorf("failed to execute PATCH request: %w", doErr)
-			return
-		}
-	} else if response.StatusCode != http.StatusCreated {
-		err = fmt.Errorf("unexpected status code (%d): %s. Payload: %s",
-			response.StatusCode, response.Status, string(payloadBytes))
+	if identifyErr != nil {
+		switchLogger.Warn("Unable to identify switch, using the vendor profile for its SLS Brand.",
+			zap.String("brand", managementSwitch.Brand),
+			zap.Error(identifyErr),
+		)
 	}
-
-	return
-}
-
-func getXnameForSwitchPort(managementSwitchXname string, portName string) (xname string, err error) {
-	url := fmt.Sprintf("%s/v1/search/hardware?type=comptype_mgmt_switch_connector&class=River"+
-		"&extra_properties.VendorName=%s&parent=%s", *slsURL, portName, managementSwitchXname)
-
-	response, err := httpClient.Get(url)
-	defer base.DrainAndCloseResponseBody(response)
-	if err != nil {
-		return
+	switchReport.Profile = profile.Name
+	switchReport.Vendor = profile.Vendor
+	switchReport.Model = identity.Model
+	switchReport.SysDescr = identity.SysDescr
+	switchReport.Mismatches = snmp_utilities.CompareWithSLS(identity, profile, managementSwitch.Brand,
+		managementSwitch.Model)
+	if len(switchReport.Mismatches) > 0 {
+		switchLogger.Warn("Management switch isn't what SLS says it is!",
+			zap.Strings("mismatches", switchReport.Mismatches))
 	}
+	switchLogger.Debug("Using vendor profile for switch.", zap.String("profile", profile.Name),
+		zap.String("sysDescr", identity.SysDescr), zap.String("model", identity.Model))
 
-	if response.StatusCode != http.StatusOK {
-		err = fmt.Errorf("unexpected status code from SLS: %d", response.StatusCode)
-		return
+	macPortMap, ports, macPortErr := discoveryClient.GetMACPortMap(snmpInterface, profile)
+	var vendorNames map[string]string
+	if macPortErr == nil {
+		vendorNames = matchSwitchPorts(ctx, managementSwitch, profile, ports, &switchReport, switchLogger)
+	}
+	for _, neighbor := range ports.InterSwitchNeighbors() {
+		switchReport.InterSwitchPorts = append(switchReport.InterSwitchPorts, report.Neighbor{
+			Port:      neighbor.Port,
+			SysName:   neighbor.SysName,
+			ChassisID: neighbor.ChassisID,
+			PortID:    neighbor.PortID,
+		})
 	}
 
-	jsonBytes, err := ioutil.ReadAll(response.Body)
-	if err != nil {
-		return
+	for lagName, memberNames := range ports.LAGs {
+		switchReport.LAGs = append(switchReport.LAGs, report.LAG{
+			Port:    lagName,
+			Members: memberNames,
+			Class:   string(ports.Classify(profile, lagName)),
+		})
+	}
+	sort.Slice(switchReport.LAGs, func(i, j int) bool {
+		return switchReport.LAGs[i].Port < switchReport.LAGs[j].Port
+	})
+
+	var arpTable map[string]string
+	if *snmpARP && macPortErr == nil {
+		var arpErr error
+		arpTable, arpErr = snmpInterface.GetARPTable()
+		if arpErr != nil {
+			switchLogger.Warn("Unable to get ARP table of switch!", zap.Error(arpErr))
+		}
+		switchReport.ARPEntries = len(arpTable)
 	}
 
-	var genericHardware []sls_common.GenericHardware
-	err = json.Unmarshal(jsonBytes, &genericHardware)
+	if macPortErr != nil {
+		errorClass := snmp_utilities.ClassifyError(macPortErr)
+		switchLogger.Warn("Unable to get MAC to port mapping for switch!",
+			zap.Error(macPortErr),
+			zap.String("errorClass", string(errorClass)),
+		)
+		switchReport.ErrorClass = string(errorClass)
+		recordSwitchWalkFailure(switchReport, walkStart, macPortErr)
 
-	// Make sure we have a result.
-	if len(genericHardware) == 0 {
-		err = fmt.Errorf("no results found for switch/port combination")
-		return
+		return discovery.WalkedSwitch{}, false
 	}
 
-	// This should be impossible given the primary key would clash, but always good to check the corners.
-	if len(genericHardware) > 1 {
-		err = fmt.Errorf("found more than one match for a switch/port combination")
-		return
-	}
+	walkEnd := time.Now()
+	switchReport.MACs = len(macPortMap)
+	switchReport.UplinkMACs = ports.UplinkMACs
+	runReport.AddSwitch(switchReport, walkStart, walkEnd, nil)
+	snmpWalkDuration.WithLabelValues(managementSwitch.Xname).Observe(walkEnd.Sub(walkStart).Seconds())
+
+	snmpWalks.WithLabelValues(managementSwitch.Xname, "success").Inc()
+	switchLogger.Debug("Got MAC to port mapping for switch.",
+		zap.Int("macs", len(macPortMap)), zap.Int("uplinkMACs", ports.UplinkMACs),
+		zap.Int("interSwitchPorts", len(switchReport.InterSwitchPorts)),
+		zap.Duration("duration", walkEnd.Sub(walkStart)))
+
+	return discovery.WalkedSwitch{
+		ManagementSwitch: managementSwitch,
+		Profile:          profile,
+		MACPortMap:       macPortMap,
+		Ports:            ports,
+		VendorNames:      vendorNames,
+		ARPTable:         arpTable,
+	}, true
+}
 
-	// Now we're sure there's only 1 result and it's the one we're after.
-	switchConnector := genericHardware[0]
+// recordSwitchWalkFailure adds a switch that couldn't be walked to the run report and metrics, with the class of error
+// it failed with.
+func recordSwitchWalkFailure(switchReport report.Switch, walkStart time.Time, err error) {
+	walkEnd := time.Now()
+	runReport.AddSwitch(switchReport, walkStart, walkEnd, err)
+	snmpWalkDuration.WithLabelValues(switchReport.Xname).Observe(walkEnd.Sub(walkStart).Seconds())
+	snmpWalks.WithLabelValues(switchReport.Xname, "failure").Inc()
+	snmpSwitchErrors.WithLabelValues(switchReport.Xname, switchReport.ErrorClass).Inc()
+}
 
-	var switchConnectorProperties sls_common.ComptypeMgmtSwitchConnector
-	decodeErr := mapstructure.Decode(switchConnector.ExtraPropertiesRaw, &switchConnectorProperties)
-	if decodeErr != nil {
-		err = fmt.Errorf("unable to decode switch connector properties: %w", decodeErr)
-		return
+// matchSwitchPorts returns the VendorName of the SLS switch connector each port of the switch matches, so the xname
+// cabled to them can be looked up, and records in the report which connectors matched a port.
+func matchSwitchPorts(ctx context.Context, managementSwitch switches.ManagementSwitch,
+	profile snmp_utilities.VendorProfile, ports discovery.SwitchPorts, switchReport *report.Switch,
+	switchLogger *zap.Logger) (vendorNames map[string]string) {
+	vendorNames, connectorMatches, matchErr := discoveryClient.MatchSwitchPorts(ctx, managementSwitch.Xname,
+		profile, ports.Names)
+	if matchErr != nil {
+		switchLogger.Warn("Unable to match switch ports with SLS switch connectors!", zap.Error(matchErr))
+		return nil
 	}
 
-	// Make sure there is at least 1 NodeNic.
-	if len(switchConnectorProperties.NodeNics) == 0 {
-		err = fmt.Errorf("no NodeNics defined for switch connector")
-		return
-	}
+	for _, connectorMatch := range connectorMatches {
+		connector := report.Connector{
+			Xname:      connectorMatch.Xname,
+			VendorName: connectorMatch.VendorName,
+			Port:       connectorMatch.Port,
+		}
 
-	// That said, there should only be 1, any more than that and we have ambiguity.
-	if len(switchConnectorProperties.NodeNics) > 1 {
-		err = fmt.Errorf("more than one NodeNic for switch connector, can not determine xname")
-		return
+		if connector.Port == "" {
+			switchReport.UnmatchedConnectors = append(switchReport.UnmatchedConnectors, connector)
+		} else {
+			switchReport.MatchedConnectors = append(switchReport.MatchedConnectors, connector)
+		}
 	}
 
-	// Finally, the only thing left must be the name we were after.
-	xname = switchConnectorProperties.NodeNics[0]
+	if len(switchReport.UnmatchedConnectors) > 0 {
+		switchLogger.Warn("SLS has switch connectors that don't match any port on the switch.",
+			zap.Int("unmatchedConnectors", len(switchReport.UnmatchedConnectors)))
+	}
 
-	return
+	return vendorNames
 }
 
-func getSwitches() (managementSwitches []switches.ManagementSwitch, err error) {
-	url := fmt.Sprintf("%s/v1/search/hardware?type=comptype_mgmt_switch&class=River", *slsURL)
-
-	response, err := httpClient.Get(url)
-	defer base.DrainAndCloseResponseBody(response)
-	if err != nil {
-		return
-	}
-
-	if response.StatusCode != http.StatusOK {
-		err = fmt.Errorf("unexpected status code from SLS: %d", response.StatusCode)
-		return
+// newSNMPInterface sets up an instance of an interface to use to get the MAC address tables from a switch. In mock
+// and replay mode the switch is never contacted, so it doesn't need working credentials.
+func newSNMPInterface(managementSwitch switches.ManagementSwitch,
+	switchLogger *zap.Logger) (snmp_utilities.SNMPInterface, error) {
+	switch strings.ToLower(*snmpMode) {
+	case "mock":
+		switchLogger.Debug("Using mock SNMP interface.", zap.String("snmpMockDir", *snmpMockDir))
+		return snmp_utilities.MockSNMP{
+			SwitchXname: managementSwitch.Xname,
+			Directory:   *snmpMockDir,
+		}, nil
+	case "replay":
+		switchLogger.Debug("Using replay SNMP interface.", zap.String("snmpFixtureDir", *snmpFixtureDir))
+		return snmp_utilities.NewReplaySNMP(managementSwitch.Xname, *snmpFixtureDir)
 	}
 
-	jsonBytes, err := ioutil.ReadAll(response.Body)
+	snmp, err := snmp_utilities.GetSNMPOjbect(managementSwitch)
 	if err != nil {
-		return
+		return nil, err
 	}
 
-	var genericHardware []sls_common.GenericHardware
-	unmarshalErr := json.Unmarshal(jsonBytes, &genericHardware)
-	if unmarshalErr != nil {
-		err = fmt.Errorf("unable to unmarshal generic hardware from SLS: %w", unmarshalErr)
-		return
+	switchLogger.Debug("Generated SNMP object for switch.",
+		zap.String("target", snmp.Target), zap.Uint16("port", snmp.Port), zap.Stringer("version", snmp.Version))
+	realSNMP := snmp_utilities.RealSNMP{
+		SNMP: snmp,
 	}
 
-	// Start by getting the default switch credentials.
-	defaultSwitchCredentials, switchErr := redsCredentialStore.GetDefaultSwitchCredentials()
-	if switchErr != nil {
-		logger.Error("Unable to get default switch credentials!", zap.Error(switchErr))
+	if strings.EqualFold(*snmpMode, "record") {
+		switchLogger.Debug("Using recording SNMP interface.", zap.String("snmpFixtureDir", *snmpFixtureDir))
+		return snmp_utilities.NewRecordingSNMP(realSNMP, managementSwitch.Xname, *snmpFixtureDir), nil
 	}
 
-	// Build the list of switches from the generic hardware.
-	for _, genericSwitch := range genericHardware {
-		var switchProperties sls_common.ComptypeMgmtSwitch
-		decodeErr := mapstructure.Decode(genericSwitch.ExtraPropertiesRaw, &switchProperties)
-		if decodeErr != nil {
-			// Might be a one off...don't quit over it.
-			logger.Error("Unable to decode switch properties!", zap.Error(decodeErr))
-			continue
-		}
-
-		// At this point we need to retrieve from Vault what we need.
-		// Start by trying the hardware specific credentials.
-		switchCreds, credErr := hsmCredentialStore.GetCompCred(genericSwitch.Xname)
-		if credErr != nil {
-			logger.Error("Unable to get credentials for switch!", zap.Error(credErr))
-		}
-
-		// The credentials we have for the specific hardware might be blank,
-		// supplement with the defaults where necessary.
-		if switchCreds.Username == "" {
-			if switchProperties.SNMPUsername != "" &&
-				!strings.HasPrefix(switchProperties.SNMPUsername, VaultPrefix) {
-				switchCreds.Username = switchProperties.SNMPUsername
-			} else {
-				switchCreds.Username = defaultSwitchCredentials.SNMPUsername
-			}
-		}
-		if switchCreds.SNMPPrivPass == "" {
-			if switchProperties.SNMPPrivPassword != "" &&
-				!strings.HasPrefix(switchProperties.SNMPPrivPassword, VaultPrefix) {
-				switchCreds.SNMPPrivPass = switchProperties.SNMPPrivPassword
-			} else {
-				switchCreds.SNMPPrivPass = defaultSwitchCredentials.SNMPPrivPassword
-			}
-		}
-		if switchCreds.SNMPAuthPass == "" {
-			if switchProperties.SNMPAuthPassword != "" &&
-				!strings.HasPrefix(switchProperties.SNMPAuthPassword, VaultPrefix) {
-				switchCreds.SNMPAuthPass = switchProperties.SNMPAuthPassword
-			} else {
-				switchCreds.SNMPAuthPass = defaultSwitchCredentials.SNMPAuthPassword
-			}
-		}
-
-		newSwitch := switches.ManagementSwitch{
-			Xname:            genericSwitch.Xname,
-			Aliases:          switchProperties.Aliases,
-			Address:          switchProperties.IP4Addr,
-			SNMPUser:         switchProperties.SNMPUsername,
-			SNMPAuthPassword: switchCreds.SNMPAuthPass,
-			SNMPAuthProtocol: switchProperties.SNMPAuthProtocol,
-			SNMPPrivPassword: switchCreds.SNMPPrivPass,
-			SNMPPrivProtocol: switchProperties.SNMPPrivProtocol,
-			Model:            switchProperties.Model,
-		}
-
-		managementSwitches = append(managementSwitches, newSwitch)
-	}
+	switchLogger.Debug("Using production SNMP interface.")
+	return realSNMP, nil
+}
 
-	return
+func informHSM(ctx context.Context, phase string, xname string, fqdn string, macAddr string) (err error) {
+	return createRedfishEndpoint(ctx, phase, discovery.NewRedfishEndpoint(xname, fqdn, macAddr))
 }
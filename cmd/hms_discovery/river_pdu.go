@@ -1,6 +1,6 @@
 // MIT License
 //
-// (C) Copyright [2020-2022,2025] Hewlett Packard Enterprise Development LP
+// (C) Copyright [2020-2022,2025-2026] Hewlett Packard Enterprise Development LP
 //
 // Permission is hereby granted, free of charge, to any person obtaining a
 // copy of this software and associated documentation files (the "Software"),
@@ -23,6 +23,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 
@@ -39,7 +40,19 @@ const (
 	pduRTS
 )
 
-func informRTS(xname, fqdn, macWithoutPunctuation string, unknownComponent sm.CompEthInterfaceV2) error {
+func pduTypeString(pduType int) string {
+	switch pduType {
+	case pduRedfish:
+		return "redfish"
+	case pduRTS:
+		return "rts"
+	default:
+		return "unknown"
+	}
+}
+
+func informRTS(ctx context.Context, xname, fqdn, macWithoutPunctuation string,
+	unknownComponent sm.CompEthInterfaceV2) error {
 	// Get Default Credentails for the PDU
 	defaultCreds, err := pduCredentialStore.GetDefaultPDUCredentails()
 	if err != nil {
@@ -50,7 +63,7 @@ func informRTS(xname, fqdn, macWithoutPunctuation string, unknownComponent sm.Co
 	unknownComponent.CompID = xname
 
 	// Add the new ethernet interface.
-	addErr := dhcpdnsClient.AddNewEthernetInterface(unknownComponent, true)
+	addErr := addNewEthernetInterface(ctx, "river", unknownComponent)
 	if addErr != nil {
 		logger.Error("Failed to add new ethernet interface to HSM, not processing further!",
 			zap.Error(addErr), zap.Any("unknownComponent", unknownComponent))
@@ -69,7 +82,7 @@ func informRTS(xname, fqdn, macWithoutPunctuation string, unknownComponent sm.Co
 	}
 
 	// ...and finally tell RTS about the newly found PDU.
-	err = pduCredentialStore.StorePDUCredentails(device)
+	err = storePDUCredentials("river", device)
 	if err != nil {
 		return fmt.Errorf("failed to store PDU credentails: %w", err)
 	}
@@ -0,0 +1,313 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+	compcredentials "github.com/Cray-HPE/hms-compcredentials"
+	"github.com/Cray-HPE/hms-discovery/pkg/hsm"
+	"github.com/Cray-HPE/hms-discovery/pkg/journal"
+	"github.com/Cray-HPE/hms-discovery/pkg/pdu_credential_store"
+	"github.com/Cray-HPE/hms-discovery/pkg/plan"
+	"github.com/Cray-HPE/hms-discovery/pkg/report"
+	"github.com/Cray-HPE/hms-discovery/pkg/switches"
+	rf "github.com/Cray-HPE/hms-smd/v2/pkg/redfish"
+	"github.com/Cray-HPE/hms-smd/v2/pkg/sm"
+	"github.com/namsral/flag"
+	"go.uber.org/zap"
+)
+
+/*
+Rolling back a run puts everything it wrote back the way the journal says it was, newest change first:
+
+	hms_discovery rollback --run <run ID>
+
+Anything that has been changed again since the run is left alone unless --force is given, so rolling back an old run
+won't undo fixes made since. With --dry_run the reverts are only shown, otherwise they are journaled under the ID of
+the rollback so that it can be rolled back in turn. Deleting a RedfishEndpoint HSM discovered also removes what HSM
+learned from it, but not the components it created.
+*/
+
+const rollbackPhase = "rollback"
+
+func runRollbackCommand(ctx context.Context, args []string) int {
+	rollbackFlags := flag.NewFlagSetWithEnvPrefix("rollback", "ROLLBACK", flag.ExitOnError)
+	runID := rollbackFlags.String("run", "", "ID of the run to roll back")
+	force := rollbackFlags.Bool("force", false,
+		"Roll back changes even when they have been changed again since the run")
+	rollbackFlags.Parse(args)
+
+	if *runID == "" {
+		logger.Error("Usage: hms_discovery rollback --run <run ID> [--force]")
+		return 2
+	}
+
+	entries, err := mutationJournal.Entries(*runID)
+	if err != nil {
+		logger.Error("Failed to read journal!", zap.Error(err), zap.String("runID", *runID))
+		return 1
+	}
+	if len(entries) == 0 {
+		logger.Error("Nothing journaled for run.", zap.String("runID", *runID))
+		return 1
+	}
+
+	ethernetInterfaces, err := hsmClient.GetEthernetInterfaces(ctx, nil)
+	if err != nil {
+		logger.Error("Failed to get EthernetInterfaces from HSM!", zap.Error(err))
+		return 1
+	}
+	ethernetInterfacesByMAC := map[string]sm.CompEthInterfaceV2{}
+	for _, ethernetInterface := range ethernetInterfaces {
+		ethernetInterfacesByMAC[ethernetInterface.MACAddr] = ethernetInterface
+	}
+
+	startRun()
+	start := time.Now()
+	for i := len(entries) - 1; i >= 0; i-- {
+		runReport.AddDevice(rollbackEntry(runContext(ctx), entries[i], ethernetInterfacesByMAC, *force))
+	}
+	runReport.AddPhase(rollbackPhase, start, time.Now(), nil)
+	runResult := finishRun()
+
+	if runResult.Failed() {
+		logger.Error("Rollback complete, however one or more changes were not rolled back.",
+			zap.String("runID", *runID), zap.String("rollbackRunID", runResult.RunID))
+		return 1
+	}
+
+	if *dryRun {
+		logger.Info("Dry run, not rolling back run.", zap.String("runID", *runID), zap.Int("changes", len(entries)))
+		return 0
+	}
+
+	logger.Info("Rolled back run.", zap.String("runID", *runID), zap.Int("changes", len(entries)),
+		zap.String("rollbackRunID", runResult.RunID))
+	return 0
+}
+
+func rollbackEntry(ctx context.Context, entry journal.Entry,
+	ethernetInterfacesByMAC map[string]sm.CompEthInterfaceV2, force bool) report.Device {
+	entryLogger := logger.With(zap.String("operation", entry.Operation), zap.String("xname", entry.Xname),
+		zap.Int("sequence", entry.Sequence))
+	device := report.Device{
+		Phase: rollbackPhase,
+		Xname: entry.Xname,
+	}
+
+	// An entry that removed something, made by rolling back an earlier run, only has what it removed to go on.
+	removed := plan.Removed(entry.After)
+	target := entry.After
+	if removed {
+		target = entry.Before
+	}
+
+	current, err := currentTarget(ctx, entry.Operation, entry.Xname, target, ethernetInterfacesByMAC)
+	if err != nil {
+		entryLogger.Error("Failed to get current state, not rolling back change!", zap.Error(err))
+		return device.Failure(err)
+	}
+
+	if current == nil && entry.Before == nil {
+		entryLogger.Info("What the run created is already gone, nothing to roll back.")
+		device.Action = report.ActionSkipped
+		return device
+	}
+
+	unchanged := removed && current == nil
+	if !removed && current != nil {
+		unchanged, err = plan.FieldsMatch(entry.After, entry.After, current, ignoredFields(entry.Operation)...)
+		if err != nil {
+			entryLogger.Error("Failed to compare current state, not rolling back change!", zap.Error(err))
+			return device.Failure(err)
+		}
+	}
+
+	if !unchanged {
+		if !force {
+			err := fmt.Errorf("%s of %s has been changed since run %s", entry.Operation, entry.Xname, entry.RunID)
+			entryLogger.Error("Change has been changed again since the run, not rolling it back.")
+			return device.Failure(err)
+		}
+
+		entryLogger.Warn("Change has been changed again since the run, rolling it back anyway.")
+	}
+
+	if err := revertEntry(ctx, entry, current); err != nil {
+		entryLogger.Error("Failed to roll back change!", zap.Error(err))
+		return device.Failure(err)
+	}
+
+	entryLogger.Info("Rolled back change.")
+	device.Action = report.ActionRolledBack
+	return device
+}
+
+// revertEntry puts back what was there before the write, or removes what the write created. current is what is there
+// now, in the form currentTarget gives it.
+func revertEntry(ctx context.Context, entry journal.Entry, current interface{}) error {
+	var (
+		restored interface{}
+		write    func() error
+	)
+
+	switch entry.Operation {
+	case plan.OpStoreCompCredentials:
+		if entry.Before == nil {
+			write = func() error {
+				return secureStorage.Delete(hsmCredentialStore.CCPath + "/" + entry.Xname)
+			}
+			break
+		}
+
+		var cred compcredentials.CompCredentials
+		if err := json.Unmarshal(entry.Before, &cred); err != nil {
+			return err
+		}
+		restored = cred
+		write = func() error {
+			return hsmCredentialStore.StoreCompCred(cred)
+		}
+
+	case plan.OpStorePDUCredentials:
+		if entry.Before == nil {
+			write = func() error {
+				return pduCredentialStore.DeletePDUCredentails(entry.Xname)
+			}
+			break
+		}
+
+		var device pdu_credential_store.Device
+		if err := json.Unmarshal(entry.Before, &device); err != nil {
+			return err
+		}
+		restored = device
+		write = func() error {
+			return pduCredentialStore.StorePDUCredentails(device)
+		}
+
+	case plan.OpStoreSwitchCommunity:
+		if entry.Before == nil {
+			write = func() error {
+				return redsCredentialStore.DeleteSwitchCommunity(entry.Xname)
+			}
+			break
+		}
+
+		var community switches.SwitchCommunity
+		if err := json.Unmarshal(entry.Before, &community); err != nil {
+			return err
+		}
+		restored = community
+		write = func() error {
+			return redsCredentialStore.StoreSwitchCommunity(community)
+		}
+
+	case plan.OpAddEthernetInterface:
+		if entry.Before == nil {
+			var added sm.CompEthInterfaceV2
+			if err := json.Unmarshal(entry.After, &added); err != nil {
+				return err
+			}
+			write = func() error {
+				return ignoreNotFound(hsmClient.DeleteEthernetInterface(ctx, added.MACAddr))
+			}
+			break
+		}
+
+		var ethernetInterface sm.CompEthInterfaceV2
+		if err := json.Unmarshal(entry.Before, &ethernetInterface); err != nil {
+			return err
+		}
+		restored = ethernetInterface
+		write = func() error {
+			return hsmClient.UpsertEthernetInterface(ctx, ethernetInterface)
+		}
+
+	case plan.OpCreateRedfishEndpoint:
+		if entry.Before == nil {
+			write = func() error {
+				return ignoreNotFound(hsmClient.DeleteRedfishEndpoint(ctx, entry.Xname))
+			}
+			break
+		}
+
+		// Only the fields discovery sets are journaled, so only they are put back. Writing the whole endpoint would
+		// blank its credentials and everything HSM learned about it.
+		var endpoint plannedRedfishEndpoint
+		if err := json.Unmarshal(entry.Before, &endpoint); err != nil {
+			return err
+		}
+		restored = endpoint
+		write = func() error {
+			err := hsmClient.PatchRedfishEndpoint(ctx, entry.Xname, endpoint.patch())
+			if errors.Is(err, hsm.ErrNotFound) {
+				return hsmClient.CreateRedfishEndpoints(ctx, []rf.RedfishEPDescription{endpoint.redfishEndpoint()})
+			}
+			return err
+		}
+
+	case plan.OpCreateComponent:
+		if entry.Before == nil {
+			write = func() error {
+				return ignoreNotFound(hsmClient.DeleteComponent(ctx, entry.Xname))
+			}
+			break
+		}
+
+		var component base.Component
+		if err := json.Unmarshal(entry.Before, &component); err != nil {
+			return err
+		}
+		restored = component
+		write = func() error {
+			return hsmClient.CreateComponent(ctx, component)
+		}
+
+	default:
+		return fmt.Errorf("unknown operation: %s", entry.Operation)
+	}
+
+	// Reverts are journaled under the rollback's own run ID like any other write, so a rollback can be rolled back.
+	if !*dryRun {
+		return makeWrite(rollbackPhase, entry.Operation, entry.Xname, current, restored, write)
+	}
+
+	return recordMutation(rollbackPhase, entry.Operation, entry.Xname, redactTarget(current), redactTarget(restored),
+		nil)
+}
+
+// ignoreNotFound treats removing something that is already gone as having removed it.
+func ignoreNotFound(err error) error {
+	if errors.Is(err, hsm.ErrNotFound) {
+		return nil
+	}
+
+	return err
+}
@@ -0,0 +1,792 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+	compcredentials "github.com/Cray-HPE/hms-compcredentials"
+	"github.com/Cray-HPE/hms-discovery/pkg/hsm"
+	"github.com/Cray-HPE/hms-discovery/pkg/offline"
+	"github.com/Cray-HPE/hms-discovery/pkg/pdu_credential_store"
+	"github.com/Cray-HPE/hms-discovery/pkg/plan"
+	"github.com/Cray-HPE/hms-discovery/pkg/report"
+	"github.com/Cray-HPE/hms-discovery/pkg/sls"
+	"github.com/Cray-HPE/hms-discovery/pkg/switches"
+	rf "github.com/Cray-HPE/hms-smd/v2/pkg/redfish"
+	"github.com/Cray-HPE/hms-smd/v2/pkg/sm"
+	"github.com/hashicorp/go-retryablehttp"
+	"go.uber.org/zap"
+)
+
+// fakeHSM keeps RedfishEndpoints, EthernetInterfaces and components in memory, answering the requests hsm.Client
+// makes with the status codes HSM uses.
+type fakeHSM struct {
+	lock        sync.Mutex
+	collections map[string]map[string]map[string]interface{}
+}
+
+const (
+	fakeRedfishEndpoints   = "Inventory/RedfishEndpoints"
+	fakeEthernetInterfaces = "Inventory/EthernetInterfaces"
+	fakeComponents         = "State/Components"
+)
+
+func newFakeHSM() *fakeHSM {
+	return &fakeHSM{
+		collections: map[string]map[string]map[string]interface{}{
+			fakeRedfishEndpoints:   {},
+			fakeEthernetInterfaces: {},
+			fakeComponents:         {},
+		},
+	}
+}
+
+// put stores value as HSM would return it.
+func (fake *fakeHSM) put(t *testing.T, collection, id string, value interface{}) {
+	t.Helper()
+
+	fake.lock.Lock()
+	defer fake.lock.Unlock()
+
+	fake.collections[collection][id] = toObject(t, value)
+}
+
+func (fake *fakeHSM) get(collection, id string) map[string]interface{} {
+	fake.lock.Lock()
+	defer fake.lock.Unlock()
+
+	return fake.collections[collection][id]
+}
+
+func toObject(t *testing.T, value interface{}) map[string]interface{} {
+	t.Helper()
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var object map[string]interface{}
+	if err := json.Unmarshal(data, &object); err != nil {
+		t.Fatal(err)
+	}
+
+	return object
+}
+
+func (fake *fakeHSM) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	fake.lock.Lock()
+	defer fake.lock.Unlock()
+
+	path := strings.TrimPrefix(r.URL.Path, "/hsm/v2/")
+	var (
+		collection string
+		id         string
+	)
+	for name := range fake.collections {
+		if path == name || strings.HasPrefix(path, name+"/") {
+			collection = name
+			id = strings.TrimPrefix(strings.TrimPrefix(path, name), "/")
+		}
+	}
+	if collection == "" {
+		http.NotFound(w, r)
+		return
+	}
+	objects := fake.collections[collection]
+
+	switch {
+	case r.Method == http.MethodGet && id == "":
+		list := []map[string]interface{}{}
+		for _, object := range objects {
+			list = append(list, object)
+		}
+		json.NewEncoder(w).Encode(list)
+
+	case r.Method == http.MethodGet:
+		object, found := objects[id]
+		if !found {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(object)
+
+	case r.Method == http.MethodPost:
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		switch collection {
+		case fakeRedfishEndpoints:
+			for _, endpoint := range body["RedfishEndpoints"].([]interface{}) {
+				if _, found := objects[endpoint.(map[string]interface{})["ID"].(string)]; found {
+					http.Error(w, "already exists", http.StatusConflict)
+					return
+				}
+			}
+			for _, endpoint := range body["RedfishEndpoints"].([]interface{}) {
+				objects[endpoint.(map[string]interface{})["ID"].(string)] = endpoint.(map[string]interface{})
+			}
+			w.WriteHeader(http.StatusCreated)
+
+		case fakeEthernetInterfaces:
+			id := strings.ToLower(strings.ReplaceAll(body["MACAddress"].(string), ":", ""))
+			if _, found := objects[id]; found {
+				http.Error(w, "already exists", http.StatusConflict)
+				return
+			}
+			body["ID"] = id
+			objects[id] = body
+			w.WriteHeader(http.StatusCreated)
+
+		case fakeComponents:
+			for _, component := range body["Components"].([]interface{}) {
+				objects[component.(map[string]interface{})["ID"].(string)] = component.(map[string]interface{})
+			}
+			w.WriteHeader(http.StatusNoContent)
+		}
+
+	case r.Method == http.MethodPatch:
+		object, found := objects[id]
+		if !found {
+			http.NotFound(w, r)
+			return
+		}
+
+		var patch map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		for field, value := range patch {
+			if value != nil {
+				object[field] = value
+			}
+		}
+
+	case r.Method == http.MethodDelete:
+		if _, found := objects[id]; !found {
+			http.NotFound(w, r)
+			return
+		}
+		delete(objects, id)
+
+	default:
+		http.Error(w, "unsupported", http.StatusMethodNotAllowed)
+	}
+}
+
+// setupTestRun points the HSM client at a fake HSM and the credential stores and journal at in-memory Vault.
+func setupTestRun(t *testing.T, dryRunValue bool) *fakeHSM {
+	t.Helper()
+
+	fake := newFakeHSM()
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	testHTTPClient := retryablehttp.NewClient()
+	testHTTPClient.RetryMax = 0
+	testHTTPClient.Logger = nil
+
+	logger = zap.NewNop()
+	hsmClient = hsm.NewClient(server.URL, testHTTPClient)
+	slsClient = sls.NewClient(server.URL, testHTTPClient)
+	setupCredentialStores(offline.NewStorage())
+	runReport = report.NewRecorder("rollback")
+	planRecorder = plan.NewRecorder("rollback")
+
+	previousDryRun := *dryRun
+	*dryRun = dryRunValue
+	t.Cleanup(func() {
+		*dryRun = previousDryRun
+	})
+
+	return fake
+}
+
+func ethernetInterfacesByMAC(t *testing.T) map[string]sm.CompEthInterfaceV2 {
+	t.Helper()
+
+	ethernetInterfaces, err := hsmClient.GetEthernetInterfaces(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	byMAC := map[string]sm.CompEthInterfaceV2{}
+	for _, ethernetInterface := range ethernetInterfaces {
+		byMAC[ethernetInterface.MACAddr] = ethernetInterface
+	}
+
+	return byMAC
+}
+
+const (
+	testBMC    = "x3000c0s1b0"
+	testPDU    = "x3000m0"
+	testSwitch = "x3000c0w14"
+	testMAC    = "a4:bf:01:2e:7f:a5"
+)
+
+func testCompCred(password string) compcredentials.CompCredentials {
+	return compcredentials.CompCredentials{
+		Xname:    testBMC,
+		URL:      testBMC + "/redfish/v1/UpdateService",
+		Username: "root",
+		Password: password,
+	}
+}
+
+func testPDUCred(password string) pdu_credential_store.Device {
+	return pdu_credential_store.Device{
+		Xname:    testPDU,
+		URL:      "https://" + testPDU,
+		Username: "admn",
+		Password: password,
+	}
+}
+
+func testEthernetInterface(description string) sm.CompEthInterfaceV2 {
+	return sm.CompEthInterfaceV2{
+		ID:      "a4bf012e7fa5",
+		Desc:    description,
+		MACAddr: testMAC,
+		CompID:  testBMC,
+		Type:    "NodeBMC",
+		IPAddrs: []sm.IPAddressMapping{{IPAddr: "10.254.1.5"}},
+	}
+}
+
+func testPlannedRedfishEndpoint(fqdn, macAddr string) plannedRedfishEndpoint {
+	return plannedRedfishEndpoint{
+		ID:             testBMC,
+		FQDN:           fqdn,
+		MACAddr:        macAddr,
+		RediscOnUpdate: true,
+		Enabled:        true,
+	}
+}
+
+// testRedfishEndpoint is the endpoint HSM has after discovery wrote planned and HSM discovered it.
+func testRedfishEndpoint(planned plannedRedfishEndpoint) rf.RedfishEPDescription {
+	endpoint := planned.redfishEndpoint()
+	endpoint.Type = "NodeBMC"
+	endpoint.IPAddr = "10.254.1.5"
+	endpoint.User = "root"
+	endpoint.Password = "bmc-password"
+
+	return endpoint
+}
+
+var testComponent = base.Component{
+	ID:      testBMC,
+	Type:    "NodeBMC",
+	State:   "Ready",
+	Flag:    "OK",
+	Enabled: func() *bool { enabled := true; return &enabled }(),
+}
+
+func TestRollbackEntry(t *testing.T) {
+	var (
+		createdEndpoint  = testPlannedRedfishEndpoint(testBMC, "a4bf012e7fa5")
+		originalEndpoint = testPlannedRedfishEndpoint("10.254.1.5", "")
+	)
+
+	tests := []struct {
+		name      string
+		operation string
+		xname     string
+		// before and after are what the run being rolled back journaled.
+		before interface{}
+		after  interface{}
+		// seed puts things the way they are now.
+		seed       func(t *testing.T, fake *fakeHSM)
+		force      bool
+		wantAction string
+		check      func(t *testing.T, fake *fakeHSM)
+	}{
+		{
+			name:      "credentials the run stored are deleted",
+			operation: plan.OpStoreCompCredentials,
+			xname:     testBMC,
+			after:     testCompCred("new"),
+			seed: func(t *testing.T, fake *fakeHSM) {
+				storeTestCompCred(t, testCompCred("new"))
+			},
+			wantAction: report.ActionRolledBack,
+			check: func(t *testing.T, fake *fakeHSM) {
+				checkTestCompCred(t, "")
+			},
+		},
+		{
+			name:      "credentials the run replaced are restored",
+			operation: plan.OpStoreCompCredentials,
+			xname:     testBMC,
+			before:    testCompCred("old"),
+			after:     testCompCred("new"),
+			seed: func(t *testing.T, fake *fakeHSM) {
+				storeTestCompCred(t, testCompCred("new"))
+			},
+			wantAction: report.ActionRolledBack,
+			check: func(t *testing.T, fake *fakeHSM) {
+				checkTestCompCred(t, "old")
+			},
+		},
+		{
+			name:      "credentials changed since the run are left alone",
+			operation: plan.OpStoreCompCredentials,
+			xname:     testBMC,
+			before:    testCompCred("old"),
+			after:     testCompCred("new"),
+			seed: func(t *testing.T, fake *fakeHSM) {
+				storeTestCompCred(t, testCompCred("changed"))
+			},
+			wantAction: report.ActionFailed,
+			check: func(t *testing.T, fake *fakeHSM) {
+				checkTestCompCred(t, "changed")
+			},
+		},
+		{
+			name:      "credentials changed since the run are restored with force",
+			operation: plan.OpStoreCompCredentials,
+			xname:     testBMC,
+			before:    testCompCred("old"),
+			after:     testCompCred("new"),
+			seed: func(t *testing.T, fake *fakeHSM) {
+				storeTestCompCred(t, testCompCred("changed"))
+			},
+			force:      true,
+			wantAction: report.ActionRolledBack,
+			check: func(t *testing.T, fake *fakeHSM) {
+				checkTestCompCred(t, "old")
+			},
+		},
+		{
+			name:      "credentials the run stored that are already gone are skipped",
+			operation: plan.OpStoreCompCredentials,
+			xname:     testBMC,
+			after:     testCompCred("new"),
+			seed: func(t *testing.T, fake *fakeHSM) {
+			},
+			wantAction: report.ActionSkipped,
+			check: func(t *testing.T, fake *fakeHSM) {
+				checkTestCompCred(t, "")
+			},
+		},
+		{
+			name:      "credentials removed by a rollback are restored",
+			operation: plan.OpStoreCompCredentials,
+			xname:     testBMC,
+			before:    testCompCred("new"),
+			seed: func(t *testing.T, fake *fakeHSM) {
+			},
+			wantAction: report.ActionRolledBack,
+			check: func(t *testing.T, fake *fakeHSM) {
+				checkTestCompCred(t, "new")
+			},
+		},
+		{
+			name:      "PDU credentials the run stored are deleted",
+			operation: plan.OpStorePDUCredentials,
+			xname:     testPDU,
+			after:     testPDUCred("new"),
+			seed: func(t *testing.T, fake *fakeHSM) {
+				storeTestPDUCred(t, testPDUCred("new"))
+			},
+			wantAction: report.ActionRolledBack,
+			check: func(t *testing.T, fake *fakeHSM) {
+				checkTestPDUCred(t, "")
+			},
+		},
+		{
+			name:      "PDU credentials the run replaced are restored",
+			operation: plan.OpStorePDUCredentials,
+			xname:     testPDU,
+			before:    testPDUCred("old"),
+			after:     testPDUCred("new"),
+			seed: func(t *testing.T, fake *fakeHSM) {
+				storeTestPDUCred(t, testPDUCred("new"))
+			},
+			wantAction: report.ActionRolledBack,
+			check: func(t *testing.T, fake *fakeHSM) {
+				checkTestPDUCred(t, "old")
+			},
+		},
+		{
+			name:      "switch community the run stored is deleted",
+			operation: plan.OpStoreSwitchCommunity,
+			xname:     testSwitch,
+			after:     switches.SwitchCommunity{Xname: testSwitch, SNMPCommunity: "new"},
+			seed: func(t *testing.T, fake *fakeHSM) {
+				storeTestSwitchCommunity(t, "new")
+			},
+			wantAction: report.ActionRolledBack,
+			check: func(t *testing.T, fake *fakeHSM) {
+				checkTestSwitchCommunity(t, "")
+			},
+		},
+		{
+			name:      "switch community the run replaced is restored",
+			operation: plan.OpStoreSwitchCommunity,
+			xname:     testSwitch,
+			before:    switches.SwitchCommunity{Xname: testSwitch, SNMPCommunity: "old"},
+			after:     switches.SwitchCommunity{Xname: testSwitch, SNMPCommunity: "new"},
+			seed: func(t *testing.T, fake *fakeHSM) {
+				storeTestSwitchCommunity(t, "new")
+			},
+			wantAction: report.ActionRolledBack,
+			check: func(t *testing.T, fake *fakeHSM) {
+				checkTestSwitchCommunity(t, "old")
+			},
+		},
+		{
+			name:      "EthernetInterface the run added is deleted",
+			operation: plan.OpAddEthernetInterface,
+			xname:     testBMC,
+			after:     testEthernetInterface("new"),
+			seed: func(t *testing.T, fake *fakeHSM) {
+				fake.put(t, fakeEthernetInterfaces, "a4bf012e7fa5", testEthernetInterface("new"))
+			},
+			wantAction: report.ActionRolledBack,
+			check: func(t *testing.T, fake *fakeHSM) {
+				if object := fake.get(fakeEthernetInterfaces, "a4bf012e7fa5"); object != nil {
+					t.Errorf("EthernetInterface = %v, want it deleted", object)
+				}
+			},
+		},
+		{
+			name:      "EthernetInterface the run replaced is restored",
+			operation: plan.OpAddEthernetInterface,
+			xname:     testBMC,
+			before:    testEthernetInterface("old"),
+			after:     testEthernetInterface("new"),
+			seed: func(t *testing.T, fake *fakeHSM) {
+				fake.put(t, fakeEthernetInterfaces, "a4bf012e7fa5", testEthernetInterface("new"))
+			},
+			wantAction: report.ActionRolledBack,
+			check: func(t *testing.T, fake *fakeHSM) {
+				object := fake.get(fakeEthernetInterfaces, "a4bf012e7fa5")
+				if object == nil || object["Description"] != "old" {
+					t.Errorf("EthernetInterface = %v, want Description old", object)
+				}
+			},
+		},
+		{
+			name:      "RedfishEndpoint the run created is deleted",
+			operation: plan.OpCreateRedfishEndpoint,
+			xname:     testBMC,
+			after:     createdEndpoint,
+			seed: func(t *testing.T, fake *fakeHSM) {
+				fake.put(t, fakeRedfishEndpoints, testBMC, testRedfishEndpoint(createdEndpoint))
+			},
+			wantAction: report.ActionRolledBack,
+			check: func(t *testing.T, fake *fakeHSM) {
+				if object := fake.get(fakeRedfishEndpoints, testBMC); object != nil {
+					t.Errorf("RedfishEndpoint = %v, want it deleted", object)
+				}
+			},
+		},
+		{
+			name:      "RedfishEndpoint that existed before the run keeps what discovery doesn't set",
+			operation: plan.OpCreateRedfishEndpoint,
+			xname:     testBMC,
+			before:    originalEndpoint,
+			after:     createdEndpoint,
+			seed: func(t *testing.T, fake *fakeHSM) {
+				fake.put(t, fakeRedfishEndpoints, testBMC, testRedfishEndpoint(createdEndpoint))
+			},
+			wantAction: report.ActionRolledBack,
+			check: func(t *testing.T, fake *fakeHSM) {
+				checkTestRedfishEndpoint(t, fake, map[string]interface{}{
+					"FQDN":      "10.254.1.5",
+					"MACAddr":   "",
+					"Type":      "NodeBMC",
+					"IPAddress": "10.254.1.5",
+					"User":      "root",
+					"Password":  "bmc-password",
+				})
+			},
+		},
+		{
+			name:      "RedfishEndpoint deleted since the run is recreated with force",
+			operation: plan.OpCreateRedfishEndpoint,
+			xname:     testBMC,
+			before:    originalEndpoint,
+			after:     createdEndpoint,
+			seed: func(t *testing.T, fake *fakeHSM) {
+			},
+			force:      true,
+			wantAction: report.ActionRolledBack,
+			check: func(t *testing.T, fake *fakeHSM) {
+				checkTestRedfishEndpoint(t, fake, map[string]interface{}{
+					"FQDN":    "10.254.1.5",
+					"Enabled": true,
+				})
+			},
+		},
+		{
+			name:      "component the run created is deleted",
+			operation: plan.OpCreateComponent,
+			xname:     testBMC,
+			after:     testComponent,
+			seed: func(t *testing.T, fake *fakeHSM) {
+				fake.put(t, fakeComponents, testBMC, testComponent)
+			},
+			wantAction: report.ActionRolledBack,
+			check: func(t *testing.T, fake *fakeHSM) {
+				if object := fake.get(fakeComponents, testBMC); object != nil {
+					t.Errorf("component = %v, want it deleted", object)
+				}
+			},
+		},
+		{
+			name:      "component HSM has updated since the run is still deleted",
+			operation: plan.OpCreateComponent,
+			xname:     testBMC,
+			after:     testComponent,
+			seed: func(t *testing.T, fake *fakeHSM) {
+				discovered := testComponent
+				discovered.State = "On"
+				fake.put(t, fakeComponents, testBMC, discovered)
+			},
+			wantAction: report.ActionRolledBack,
+			check: func(t *testing.T, fake *fakeHSM) {
+				if object := fake.get(fakeComponents, testBMC); object != nil {
+					t.Errorf("component = %v, want it deleted", object)
+				}
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fake := setupTestRun(t, false)
+			test.seed(t, fake)
+
+			if _, err := mutationJournal.Append("run", "river", test.operation, test.xname, test.before,
+				test.after); err != nil {
+				t.Fatal(err)
+			}
+			entries, err := mutationJournal.Entries("run")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			device := rollbackEntry(context.Background(), entries[0], ethernetInterfacesByMAC(t), test.force)
+			if device.Action != test.wantAction {
+				t.Errorf("rollbackEntry() Action = %q (%s), want %q", device.Action, device.Error, test.wantAction)
+			}
+			test.check(t, fake)
+
+			// Every revert is journaled under the rollback's run ID, and nothing else is.
+			reverts, err := mutationJournal.Entries(runReport.RunID())
+			if err != nil {
+				t.Fatal(err)
+			}
+			wantReverts := 0
+			if test.wantAction == report.ActionRolledBack {
+				wantReverts = 1
+			}
+			if len(reverts) != wantReverts {
+				t.Fatalf("journaled %d reverts, want %d", len(reverts), wantReverts)
+			}
+			for _, revert := range reverts {
+				if revert.Phase != rollbackPhase || revert.Operation != test.operation {
+					t.Errorf("journaled %s %s, want %s %s", revert.Phase, revert.Operation, rollbackPhase,
+						test.operation)
+				}
+			}
+		})
+	}
+}
+
+func TestRollbackEntryDryRun(t *testing.T) {
+	setupTestRun(t, true)
+	storeTestCompCred(t, testCompCred("new"))
+
+	if _, err := mutationJournal.Append("run", "river", plan.OpStoreCompCredentials, testBMC, testCompCred("old"),
+		testCompCred("new")); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := mutationJournal.Entries("run")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	device := rollbackEntry(context.Background(), entries[0], nil, false)
+	if device.Action != report.ActionRolledBack {
+		t.Errorf("rollbackEntry() Action = %q (%s), want %q", device.Action, device.Error, report.ActionRolledBack)
+	}
+	checkTestCompCred(t, "new")
+
+	reverts, err := mutationJournal.Entries(runReport.RunID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reverts) != 0 {
+		t.Errorf("journaled %d reverts on a dry run, want none", len(reverts))
+	}
+
+	mutations := planRecorder.Plan().Mutations
+	if len(mutations) != 1 {
+		t.Fatalf("planned %d mutations, want 1", len(mutations))
+	}
+	if mutations[0].Phase != rollbackPhase || mutations[0].Operation != plan.OpStoreCompCredentials {
+		t.Errorf("planned %s %s, want %s %s", mutations[0].Phase, mutations[0].Operation, rollbackPhase,
+			plan.OpStoreCompCredentials)
+	}
+	if strings.Contains(string(mutations[0].After), `"old"`) {
+		t.Errorf("planned After %s has the restored password in it", mutations[0].After)
+	}
+}
+
+func TestRunRollbackCommand(t *testing.T) {
+	setupTestRun(t, false)
+	storeTestCompCred(t, testCompCred("second"))
+
+	// Rolled back oldest first, the first write would look changed since the run as the second overwrote it.
+	writes := []struct {
+		before interface{}
+		after  interface{}
+	}{
+		{nil, testCompCred("first")},
+		{testCompCred("first"), testCompCred("second")},
+	}
+	for _, write := range writes {
+		if _, err := mutationJournal.Append("run", "river", plan.OpStoreCompCredentials, testBMC, write.before,
+			write.after); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if exitCode := runRollbackCommand(context.Background(), []string{"--run", "run"}); exitCode != 0 {
+		t.Fatalf("runRollbackCommand() = %d, want 0", exitCode)
+	}
+	checkTestCompCred(t, "")
+
+	reverts, err := mutationJournal.Entries(runReport.RunID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reverts) != len(writes) {
+		t.Fatalf("journaled %d reverts, want %d", len(reverts), len(writes))
+	}
+	if !plan.Removed(reverts[1].After) {
+		t.Errorf("last revert After = %s, want the first write removed", reverts[1].After)
+	}
+}
+
+func storeTestCompCred(t *testing.T, cred compcredentials.CompCredentials) {
+	t.Helper()
+
+	if err := hsmCredentialStore.StoreCompCred(cred); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// checkTestCompCred fails unless the stored credentials have password, or there are none when password is empty.
+func checkTestCompCred(t *testing.T, password string) {
+	t.Helper()
+
+	cred, err := hsmCredentialStore.GetCompCred(testBMC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cred.Password != password {
+		t.Errorf("stored password = %q, want %q", cred.Password, password)
+	}
+	if password == "" && cred.Xname != "" {
+		t.Errorf("stored credentials for %s, want none", cred.Xname)
+	}
+}
+
+func storeTestPDUCred(t *testing.T, device pdu_credential_store.Device) {
+	t.Helper()
+
+	if err := pduCredentialStore.StorePDUCredentails(device); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func checkTestPDUCred(t *testing.T, password string) {
+	t.Helper()
+
+	device, err := pduCredentialStore.GetPDUCredentails(testPDU)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if device.Password != password {
+		t.Errorf("stored PDU password = %q, want %q", device.Password, password)
+	}
+	if password == "" && device.Xname != "" {
+		t.Errorf("stored PDU credentials for %s, want none", device.Xname)
+	}
+}
+
+func storeTestSwitchCommunity(t *testing.T, community string) {
+	t.Helper()
+
+	err := redsCredentialStore.StoreSwitchCommunity(switches.SwitchCommunity{Xname: testSwitch, SNMPCommunity: community})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// checkTestSwitchCommunity fails unless the stored community is community, or there is none when it is empty.
+func checkTestSwitchCommunity(t *testing.T, community string) {
+	t.Helper()
+
+	stored, err := redsCredentialStore.GetSwitchCommunity(testSwitch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.SNMPCommunity != community {
+		t.Errorf("stored community = %q, want %q", stored.SNMPCommunity, community)
+	}
+	if community == "" && stored.Xname != "" {
+		t.Errorf("stored community for %s, want none", stored.Xname)
+	}
+}
+
+func checkTestRedfishEndpoint(t *testing.T, fake *fakeHSM, want map[string]interface{}) {
+	t.Helper()
+
+	object := fake.get(fakeRedfishEndpoints, testBMC)
+	if object == nil {
+		t.Fatal("RedfishEndpoint is missing")
+	}
+	for field, value := range want {
+		if object[field] != value {
+			t.Errorf("RedfishEndpoint %s = %v, want %v", field, object[field], value)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/Cray-HPE/hms-discovery/pkg/hsm"
+	"github.com/Cray-HPE/hms-discovery/pkg/report"
+	"go.uber.org/zap"
+)
+
+// runReport collects the report for the run that is currently in progress. In daemon mode every batch of phases that
+// is run together is its own run.
+var runReport = report.NewRecorder(report.NewRunID())
+
+func startRun() {
+	runReport = report.NewRecorder(report.NewRunID())
+	// Every phase in a run works from the same view of SLS, fetched once when it is first needed.
+	slsClient.Invalidate()
+	if *dryRun {
+		startPlan(runReport.RunID())
+	}
+	logger.Debug("Starting discovery run.", zap.String("runID", runReport.RunID()))
+}
+
+// runContext tags every request made to HSM under ctx with the ID of the current run, so HSM logs can be matched up
+// with the run report.
+func runContext(ctx context.Context) context.Context {
+	return hsm.WithRequestID(ctx, runReport.RunID())
+}
+
+// finishRun writes the report for the run that just finished to wherever it has been asked to go.
+func finishRun() report.Report {
+	finishedReport := runReport.Finish()
+
+	if *dryRun {
+		finishPlan()
+	} else if *journalRetention > 0 {
+		pruneJournal()
+	}
+
+	if *reportPath != "" {
+		if err := finishedReport.WriteFile(*reportPath, *reportFormat); err != nil {
+			logger.Error("Failed to write run report!", zap.Error(err), zap.String("reportPath", *reportPath))
+		} else {
+			logger.Info("Wrote run report.", zap.String("reportPath", *reportPath))
+		}
+	}
+
+	if *reportURL != "" {
+		if err := finishedReport.Post(httpClient.StandardClient(), *reportURL); err != nil {
+			logger.Error("Failed to send run report!", zap.Error(err), zap.String("reportURL", *reportURL))
+		} else {
+			logger.Info("Sent run report.", zap.String("reportURL", *reportURL))
+		}
+	}
+
+	return finishedReport
+}
+
+// pruneJournal removes the journal of every run older than journal_retention. Failing to prune never fails the run,
+// whatever is left is tried again at the end of the next one.
+func pruneJournal() {
+	pruned, err := mutationJournal.Prune(*journalRetention, time.Now())
+	if err != nil {
+		logger.Error("Failed to prune journal!", zap.Error(err))
+	}
+	if len(pruned) != 0 {
+		logger.Info("Pruned journal.", zap.Strings("runIDs", pruned), zap.Duration("retention", *journalRetention))
+	}
+}
@@ -0,0 +1,131 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Cray-HPE/hms-smd/v2/pkg/sm"
+)
+
+// PhaseStatus is the outcome of the most recent run of a discovery phase.
+type PhaseStatus struct {
+	Running   bool      `json:"Running"`
+	LastStart time.Time `json:"LastStart,omitempty"`
+	LastEnd   time.Time `json:"LastEnd,omitempty"`
+	Duration  string    `json:"Duration,omitempty"`
+	Error     string    `json:"Error,omitempty"`
+}
+
+// RiverDiscoveryStatus is what the last River discovery run was able to do with the unknown components in HSM.
+type RiverDiscoveryStatus struct {
+	Finished                   time.Time               `json:"Finished"`
+	DiscoveredXnames           []string                `json:"DiscoveredXnames"`
+	FailedXnames               []string                `json:"FailedXnames"`
+	RemainingUnknownComponents []sm.CompEthInterfaceV2 `json:"RemainingUnknownComponents"`
+}
+
+// DiscoveryStatus is the status of every phase that has run since the process started.
+type DiscoveryStatus struct {
+	Phases map[string]PhaseStatus `json:"Phases"`
+	River  *RiverDiscoveryStatus  `json:"River,omitempty"`
+}
+
+var (
+	statusLock sync.Mutex
+	status     = DiscoveryStatus{
+		Phases: map[string]PhaseStatus{},
+	}
+
+	// Set once Vault is usable, which is the last thing needed before discovery can do any real work.
+	ready bool
+)
+
+func setReady() {
+	statusLock.Lock()
+	defer statusLock.Unlock()
+
+	ready = true
+}
+
+func isReady() bool {
+	statusLock.Lock()
+	defer statusLock.Unlock()
+
+	return ready
+}
+
+func recordPhaseStart(name string, start time.Time) {
+	statusLock.Lock()
+	defer statusLock.Unlock()
+
+	phaseStatus := status.Phases[name]
+	phaseStatus.Running = true
+	phaseStatus.LastStart = start
+	status.Phases[name] = phaseStatus
+}
+
+func recordPhaseEnd(name string, end time.Time, err error) {
+	statusLock.Lock()
+	defer statusLock.Unlock()
+
+	phaseStatus := status.Phases[name]
+	phaseStatus.Running = false
+	phaseStatus.LastEnd = end
+	phaseStatus.Duration = end.Sub(phaseStatus.LastStart).String()
+	phaseStatus.Error = ""
+	if err != nil {
+		phaseStatus.Error = err.Error()
+	}
+	status.Phases[name] = phaseStatus
+}
+
+func recordRiverDiscoveryStatus(discoveredXnames, failedXnames []string,
+	remainingUnknownComponents []sm.CompEthInterfaceV2) {
+	statusLock.Lock()
+	defer statusLock.Unlock()
+
+	status.River = &RiverDiscoveryStatus{
+		Finished:                   time.Now(),
+		DiscoveredXnames:           discoveredXnames,
+		FailedXnames:               failedXnames,
+		RemainingUnknownComponents: remainingUnknownComponents,
+	}
+}
+
+func getStatus() DiscoveryStatus {
+	statusLock.Lock()
+	defer statusLock.Unlock()
+
+	// Hand back a copy so the caller can marshal it without holding the lock.
+	statusCopy := DiscoveryStatus{
+		Phases: map[string]PhaseStatus{},
+		River:  status.River,
+	}
+	for name, phaseStatus := range status.Phases {
+		statusCopy.Phases[name] = phaseStatus
+	}
+
+	return statusCopy
+}
@@ -0,0 +1,265 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/Cray-HPE/hms-discovery/pkg/sls"
+	"github.com/Cray-HPE/hms-discovery/pkg/snmp_utilities"
+	"github.com/Cray-HPE/hms-discovery/pkg/switches"
+	"github.com/namsral/flag"
+	"go.uber.org/zap"
+)
+
+/*
+Checking the management switches tries out the SNMP credentials of every management switch in SLS, rather than finding
+out they are broken when a new node fails to discover weeks later:
+
+	hms_discovery switches check
+
+Each switch is asked what it is and for its MAC address tables, the same way River discovery does it. The exit code is
+1 when any switch can't be walked.
+*/
+
+const (
+	checkYes     = "yes"
+	checkNo      = "no"
+	checkUnknown = "unknown"
+)
+
+// switchCheck is how a management switch held up to being checked.
+type switchCheck struct {
+	managementSwitch switches.ManagementSwitch
+	reachable        string
+	credentials      string
+	model            string
+	matchesSLS       string
+	fdbSize          int
+	err              error
+}
+
+func (check switchCheck) healthy() bool {
+	return check.err == nil
+}
+
+func runSwitchesCommand(ctx context.Context, args []string) int {
+	switchesFlags := flag.NewFlagSetWithEnvPrefix("switches", "SWITCHES", flag.ExitOnError)
+	switchesFlags.Parse(args)
+
+	if switchesFlags.NArg() != 1 || switchesFlags.Arg(0) != "check" {
+		logger.Error("Usage: hms_discovery switches check")
+		return 2
+	}
+
+	var queries []sls.Query
+	for _, switchType := range managementSwitchTypes {
+		queries = append(queries, sls.Query{Type: switchType})
+	}
+
+	managementSwitches, invalidSwitches, err := discoveryClient.GetSwitches(ctx, queries...)
+	if err != nil {
+		logger.Error("Failed to get management switches!", zap.Error(err))
+		return 1
+	}
+	if len(managementSwitches) == 0 && len(invalidSwitches) == 0 {
+		logger.Error("No management switches in SLS.")
+		return 1
+	}
+
+	checks := checkSwitches(ctx, managementSwitches)
+	// Switches with broken SLS entries are exactly what the check is for, so they get a row of their own.
+	for _, invalidSwitch := range invalidSwitches {
+		checks = append(checks, switchCheck{
+			managementSwitch: invalidSwitch.ManagementSwitch,
+			reachable:        checkUnknown,
+			credentials:      checkUnknown,
+			matchesSLS:       checkUnknown,
+			fdbSize:          -1,
+			err:              fmt.Errorf("%s: %w", snmp_utilities.ErrorClassConfiguration, invalidSwitch.Err),
+		})
+	}
+	sort.Slice(checks, func(i, j int) bool {
+		return checks[i].managementSwitch.Xname < checks[j].managementSwitch.Xname
+	})
+	if err := writeSwitchChecks(os.Stdout, checks); err != nil {
+		logger.Error("Failed to write switch checks!", zap.Error(err))
+		return 1
+	}
+
+	for _, check := range checks {
+		if !check.healthy() {
+			return 1
+		}
+	}
+
+	return 0
+}
+
+// checkSwitches checks every switch, up to snmp_concurrency of them at a time.
+func checkSwitches(ctx context.Context, managementSwitches []switches.ManagementSwitch) []switchCheck {
+	checks := make([]switchCheck, len(managementSwitches))
+
+	workers := make(chan struct{}, *snmpConcurrency)
+	var checkWaitGroup sync.WaitGroup
+
+	for i, managementSwitch := range managementSwitches {
+		checkWaitGroup.Add(1)
+
+		go func(i int, managementSwitch switches.ManagementSwitch) {
+			defer checkWaitGroup.Done()
+
+			workers <- struct{}{}
+			defer func() { <-workers }()
+
+			if ctx.Err() != nil {
+				checks[i] = switchCheck{
+					managementSwitch: managementSwitch,
+					reachable:        checkUnknown,
+					credentials:      checkUnknown,
+					matchesSLS:       checkUnknown,
+					fdbSize:          -1,
+					err:              ctx.Err(),
+				}
+				return
+			}
+
+			checks[i] = checkSwitch(managementSwitch)
+		}(i, managementSwitch)
+	}
+
+	checkWaitGroup.Wait()
+
+	return checks
+}
+
+// checkSwitch asks a switch what it is and then for its MAC address tables. Whether the switch is reachable and its
+// credentials work is told from how the first of those went.
+func checkSwitch(managementSwitch switches.ManagementSwitch) switchCheck {
+	switchLogger := logger.With(zap.String("managementSwitchXname", managementSwitch.Xname))
+	check := switchCheck{
+		managementSwitch: managementSwitch,
+		reachable:        checkUnknown,
+		credentials:      checkUnknown,
+		matchesSLS:       checkUnknown,
+		fdbSize:          -1,
+	}
+
+	snmpInterface, err := newSNMPInterface(managementSwitch, switchLogger)
+	if err != nil {
+		check.err = fmt.Errorf("%s: %w", snmp_utilities.ErrorClassConfiguration, err)
+		return check
+	}
+	if closer, ok := snmpInterface.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	identity, profile, err := discoveryClient.IdentifySwitch(snmpInterface, managementSwitch)
+	errorClass := snmp_utilities.ClassifyError(err)
+	switch {
+	case err == nil || identity.SysDescr != "":
+		// Getting anything back at all means the credentials are good, even if the switch has no ENTITY-MIB.
+		check.reachable = checkYes
+		check.credentials = checkYes
+	case errorClass == snmp_utilities.ErrorClassTimeout || errorClass == snmp_utilities.ErrorClassUnreachable:
+		// A switch drops requests with the wrong SNMPv2c community, so a timeout can be either.
+		check.reachable = checkNo
+	case errorClass == snmp_utilities.ErrorClassCredentials:
+		check.reachable = checkYes
+		check.credentials = checkNo
+	case errorClass == snmp_utilities.ErrorClassConfiguration:
+		check.credentials = checkNo
+	default:
+		check.reachable = checkYes
+	}
+	if check.credentials != checkYes {
+		check.err = fmt.Errorf("%s: %w", errorClass, err)
+		return check
+	}
+
+	check.model = identity.Model
+	if identity.Known() {
+		check.matchesSLS = checkYes
+		if mismatches := snmp_utilities.CompareWithSLS(identity, profile, managementSwitch.Brand,
+			managementSwitch.Model); len(mismatches) > 0 {
+			check.matchesSLS = checkNo
+			switchLogger.Warn("Management switch isn't what SLS says it is!", zap.Strings("mismatches", mismatches))
+		}
+	}
+
+	macPortMap, ports, err := discoveryClient.GetMACPortMap(snmpInterface, profile)
+	if err != nil {
+		check.err = fmt.Errorf("%s: %w", snmp_utilities.ClassifyError(err), err)
+		return check
+	}
+	check.fdbSize = len(macPortMap) + ports.UplinkMACs
+
+	return check
+}
+
+func writeSwitchChecks(w io.Writer, checks []switchCheck) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "XNAME\tADDRESS\tREACHABLE\tCREDENTIALS\tSLS MODEL\tDETECTED MODEL\tMATCHES SLS\tFDB SIZE\tERROR")
+
+	for _, check := range checks {
+		fdbSize := "-"
+		if check.fdbSize >= 0 {
+			fdbSize = strconv.Itoa(check.fdbSize)
+		}
+		errorMessage := "-"
+		if check.err != nil {
+			// Keep the table to one line per switch.
+			errorMessage = strings.Join(strings.Fields(check.err.Error()), " ")
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			check.managementSwitch.Xname,
+			orDash(check.managementSwitch.Address),
+			check.reachable,
+			check.credentials,
+			orDash(check.managementSwitch.Model),
+			orDash(check.model),
+			check.matchesSLS,
+			fdbSize,
+			errorMessage,
+		)
+	}
+
+	return tw.Flush()
+}
+
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+
+	return value
+}
@@ -0,0 +1,538 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Cray-HPE/hms-discovery/pkg/report"
+	"github.com/Cray-HPE/hms-discovery/pkg/sls"
+	"github.com/Cray-HPE/hms-discovery/pkg/snmp_utilities"
+	"gopkg.in/yaml.v3"
+)
+
+/*
+Every setting in the config file is also a flag (and so also an environment variable), the file is only another way of
+setting them. When the same setting comes from more than one place the first of these wins:
+
+ 1. Command line flag
+ 2. Environment variable
+ 3. Config file
+ 4. Built in default
+
+Settings left out of the file keep whatever value they would have had without it. The file can be YAML or JSON:
+
+	sls_url: http://cray-sls
+	vault:
+	  comp_credentials_path: hms-creds
+	phases:
+	  river:
+	    enabled: true
+	    interval: 3m
+*/
+
+// Duration is a time.Duration written the same way as for flags, such as "3m" or "30s".
+type Duration time.Duration
+
+func (duration Duration) String() string {
+	return time.Duration(duration).String()
+}
+
+func (duration Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(duration.String())
+}
+
+func (duration Duration) MarshalYAML() (interface{}, error) {
+	return duration.String(), nil
+}
+
+func (duration *Duration) UnmarshalYAML(node *yaml.Node) error {
+	parsed, err := time.ParseDuration(node.Value)
+	if err != nil {
+		return fmt.Errorf("line %d: %w", node.Line, err)
+	}
+
+	*duration = Duration(parsed)
+	return nil
+}
+
+type Phase struct {
+	Enabled  *bool     `yaml:"enabled,omitempty" json:"enabled,omitempty"`
+	Interval *Duration `yaml:"interval,omitempty" json:"interval,omitempty"`
+	Timeout  *Duration `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+}
+
+type MountainPhase struct {
+	Phase `yaml:",inline"`
+
+	Script      *string   `yaml:"script,omitempty" json:"script,omitempty"`
+	SleepLength *Duration `yaml:"sleep_length,omitempty" json:"sleep_length,omitempty"`
+}
+
+type Phases struct {
+	// Timeout and Interval apply to any phase that doesn't set its own, including phases added by site specific code.
+	Timeout  *Duration `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+	Interval *Duration `yaml:"interval,omitempty" json:"interval,omitempty"`
+
+	ManagementVirtualNodes           Phase         `yaml:"management_virtual_nodes" json:"management_virtual_nodes"`
+	ManagementNodes                  Phase         `yaml:"management_nodes" json:"management_nodes"`
+	ManagementSwitchCredentials      Phase         `yaml:"management_switch_credentials" json:"management_switch_credentials"`
+	River                            Phase         `yaml:"river" json:"river"`
+	Mountain                         MountainPhase `yaml:"mountain" json:"mountain"`
+	RediscoverFailedRedfishEndpoints Phase         `yaml:"rediscover_failed_redfish_endpoints" json:"rediscover_failed_redfish_endpoints"`
+}
+
+type HTTP struct {
+	RetryMax     *int      `yaml:"retry_max,omitempty" json:"retry_max,omitempty"`
+	RetryWaitMax *Duration `yaml:"retry_wait_max,omitempty" json:"retry_wait_max,omitempty"`
+}
+
+type Vault struct {
+	BasePath            *string   `yaml:"base_path,omitempty" json:"base_path,omitempty"`
+	CompCredentialsPath *string   `yaml:"comp_credentials_path,omitempty" json:"comp_credentials_path,omitempty"`
+	RedsCredentialsPath *string   `yaml:"reds_credentials_path,omitempty" json:"reds_credentials_path,omitempty"`
+	PDUCredentialsPath  *string   `yaml:"pdu_credentials_path,omitempty" json:"pdu_credentials_path,omitempty"`
+	JournalPath         *string   `yaml:"journal_path,omitempty" json:"journal_path,omitempty"`
+	JournalRetention    *Duration `yaml:"journal_retention,omitempty" json:"journal_retention,omitempty"`
+}
+
+type SNMP struct {
+	Mode       *string `yaml:"mode,omitempty" json:"mode,omitempty"`
+	MockDir    *string `yaml:"mock_dir,omitempty" json:"mock_dir,omitempty"`
+	FixtureDir *string `yaml:"fixture_dir,omitempty" json:"fixture_dir,omitempty"`
+	Retries    *int    `yaml:"retries,omitempty" json:"retries,omitempty"`
+
+	Concurrency    *int      `yaml:"concurrency,omitempty" json:"concurrency,omitempty"`
+	MaxRepetitions *int      `yaml:"max_repetitions,omitempty" json:"max_repetitions,omitempty"`
+	Timeout        *Duration `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+
+	PortNamePrefixes *string `yaml:"port_name_prefixes,omitempty" json:"port_name_prefixes,omitempty"`
+	Switches         *string `yaml:"switches,omitempty" json:"switches,omitempty"`
+	ARP              *bool   `yaml:"arp,omitempty" json:"arp,omitempty"`
+}
+
+type Offline struct {
+	Enabled *bool   `yaml:"enabled,omitempty" json:"enabled,omitempty"`
+	SLSFile *string `yaml:"sls_file,omitempty" json:"sls_file,omitempty"`
+	HSMFile *string `yaml:"hsm_file,omitempty" json:"hsm_file,omitempty"`
+}
+
+type Daemon struct {
+	Enabled        *bool     `yaml:"enabled,omitempty" json:"enabled,omitempty"`
+	HTTPListen     *string   `yaml:"http_listen,omitempty" json:"http_listen,omitempty"`
+	ScheduleJitter *Duration `yaml:"schedule_jitter,omitempty" json:"schedule_jitter,omitempty"`
+}
+
+type Report struct {
+	Path   *string `yaml:"path,omitempty" json:"path,omitempty"`
+	Format *string `yaml:"format,omitempty" json:"format,omitempty"`
+	URL    *string `yaml:"url,omitempty" json:"url,omitempty"`
+}
+
+type Metrics struct {
+	PushgatewayURL *string `yaml:"pushgateway_url,omitempty" json:"pushgateway_url,omitempty"`
+}
+
+// Config is everything discovery can be configured with. A nil field is one that isn't set.
+type Config struct {
+	SLSURL   *string `yaml:"sls_url,omitempty" json:"sls_url,omitempty"`
+	HSMURL   *string `yaml:"hsm_url,omitempty" json:"hsm_url,omitempty"`
+	CAPMCURL *string `yaml:"capmc_url,omitempty" json:"capmc_url,omitempty"`
+	LogLevel *string `yaml:"log_level,omitempty" json:"log_level,omitempty"`
+	DryRun   *bool   `yaml:"dry_run,omitempty" json:"dry_run,omitempty"`
+	PlanPath *string `yaml:"plan_path,omitempty" json:"plan_path,omitempty"`
+
+	HTTP    HTTP    `yaml:"http" json:"http"`
+	Vault   Vault   `yaml:"vault" json:"vault"`
+	SNMP    SNMP    `yaml:"snmp" json:"snmp"`
+	Offline Offline `yaml:"offline" json:"offline"`
+	Daemon  Daemon  `yaml:"daemon" json:"daemon"`
+	Report  Report  `yaml:"report" json:"report"`
+	Metrics Metrics `yaml:"metrics" json:"metrics"`
+	Phases  Phases  `yaml:"phases" json:"phases"`
+}
+
+// binding ties a setting to the flag it is the same as. The value is a pointer to one of the pointer fields of Config.
+type binding struct {
+	flag  string
+	value interface{}
+}
+
+func (config *Config) bindings() []binding {
+	return []binding{
+		{"sls_url", &config.SLSURL},
+		{"hsm_url", &config.HSMURL},
+		{"capmc_url", &config.CAPMCURL},
+		{"log_level", &config.LogLevel},
+		{"dry_run", &config.DryRun},
+		{"plan_path", &config.PlanPath},
+
+		{"http_retry_max", &config.HTTP.RetryMax},
+		{"http_retry_wait_max", &config.HTTP.RetryWaitMax},
+
+		{"vault_base_path", &config.Vault.BasePath},
+		{"comp_credentials_vault_path", &config.Vault.CompCredentialsPath},
+		{"reds_credentials_vault_path", &config.Vault.RedsCredentialsPath},
+		{"pdu_credentials_vault_path", &config.Vault.PDUCredentialsPath},
+		{"journal_path", &config.Vault.JournalPath},
+		{"journal_retention", &config.Vault.JournalRetention},
+
+		{"snmp_mode", &config.SNMP.Mode},
+		{"snmp_mock_dir", &config.SNMP.MockDir},
+		{"snmp_fixture_dir", &config.SNMP.FixtureDir},
+		{"snmp_retries", &config.SNMP.Retries},
+		{"snmp_concurrency", &config.SNMP.Concurrency},
+		{"snmp_max_repetitions", &config.SNMP.MaxRepetitions},
+		{"snmp_timeout", &config.SNMP.Timeout},
+		{"snmp_port_name_prefixes", &config.SNMP.PortNamePrefixes},
+		{"snmp_switches", &config.SNMP.Switches},
+		{"snmp_arp", &config.SNMP.ARP},
+
+		{"offline", &config.Offline.Enabled},
+		{"offline_sls_file", &config.Offline.SLSFile},
+		{"offline_hsm_file", &config.Offline.HSMFile},
+
+		{"daemon", &config.Daemon.Enabled},
+		{"http_listen", &config.Daemon.HTTPListen},
+		{"schedule_jitter", &config.Daemon.ScheduleJitter},
+
+		{"report_path", &config.Report.Path},
+		{"report_format", &config.Report.Format},
+		{"report_url", &config.Report.URL},
+
+		{"pushgateway_url", &config.Metrics.PushgatewayURL},
+
+		{"phase_timeout", &config.Phases.Timeout},
+		{"phase_interval", &config.Phases.Interval},
+		{"discover_management_virtual_nodes", &config.Phases.ManagementVirtualNodes.Enabled},
+		{"management_virtual_node_discovery_interval", &config.Phases.ManagementVirtualNodes.Interval},
+		{"management_virtual_node_discovery_timeout", &config.Phases.ManagementVirtualNodes.Timeout},
+		{"discover_management_nodes", &config.Phases.ManagementNodes.Enabled},
+		{"management_node_discovery_interval", &config.Phases.ManagementNodes.Interval},
+		{"management_node_discovery_timeout", &config.Phases.ManagementNodes.Timeout},
+		{"populate_management_switch_credentials", &config.Phases.ManagementSwitchCredentials.Enabled},
+		{"management_switch_credentials_interval", &config.Phases.ManagementSwitchCredentials.Interval},
+		{"management_switch_credentials_timeout", &config.Phases.ManagementSwitchCredentials.Timeout},
+		{"discover_river", &config.Phases.River.Enabled},
+		{"river_discovery_interval", &config.Phases.River.Interval},
+		{"river_discovery_timeout", &config.Phases.River.Timeout},
+		{"discover_mountain", &config.Phases.Mountain.Enabled},
+		{"mountain_discovery_interval", &config.Phases.Mountain.Interval},
+		{"mountain_discovery_timeout", &config.Phases.Mountain.Timeout},
+		{"mountain_discovery_script", &config.Phases.Mountain.Script},
+		{"mountain_sleep_length", &config.Phases.Mountain.SleepLength},
+		{"rediscover_failed_redfish_endpoints", &config.Phases.RediscoverFailedRedfishEndpoints.Enabled},
+		{"rediscovery_interval", &config.Phases.RediscoverFailedRedfishEndpoints.Interval},
+		{"rediscovery_timeout", &config.Phases.RediscoverFailedRedfishEndpoints.Timeout},
+	}
+}
+
+// FlagNames lists the flag behind every setting.
+func FlagNames() []string {
+	var config Config
+
+	var names []string
+	for _, binding := range config.bindings() {
+		names = append(names, binding.flag)
+	}
+
+	return names
+}
+
+// FlagValues gives every setting that is set as the flag value that would set it the same way.
+func (config Config) FlagValues() map[string]string {
+	values := map[string]string{}
+	for _, binding := range config.bindings() {
+		switch value := binding.value.(type) {
+		case **string:
+			if *value != nil {
+				values[binding.flag] = **value
+			}
+		case **bool:
+			if *value != nil {
+				values[binding.flag] = strconv.FormatBool(**value)
+			}
+		case **int:
+			if *value != nil {
+				values[binding.flag] = strconv.Itoa(**value)
+			}
+		case **Duration:
+			if *value != nil {
+				values[binding.flag] = (**value).String()
+			}
+		}
+	}
+
+	return values
+}
+
+// FromFlags builds a config with every setting set from the flag values given by lookup.
+func FromFlags(lookup func(flag string) string) (Config, error) {
+	var config Config
+
+	for _, binding := range config.bindings() {
+		flagValue := lookup(binding.flag)
+
+		switch value := binding.value.(type) {
+		case **string:
+			*value = &flagValue
+		case **bool:
+			parsed, err := strconv.ParseBool(flagValue)
+			if err != nil {
+				return config, fmt.Errorf("%s: %w", binding.flag, err)
+			}
+			*value = &parsed
+		case **int:
+			parsed, err := strconv.Atoi(flagValue)
+			if err != nil {
+				return config, fmt.Errorf("%s: %w", binding.flag, err)
+			}
+			*value = &parsed
+		case **Duration:
+			parsed, err := time.ParseDuration(flagValue)
+			if err != nil {
+				return config, fmt.Errorf("%s: %w", binding.flag, err)
+			}
+			duration := Duration(parsed)
+			*value = &duration
+		}
+	}
+
+	return config, nil
+}
+
+// Load reads and validates a config file.
+func Load(path string) (Config, error) {
+	var config Config
+
+	file, err := os.Open(path)
+	if err != nil {
+		return config, err
+	}
+	defer file.Close()
+
+	decoder := yaml.NewDecoder(file)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&config); err != nil && !errors.Is(err, io.EOF) {
+		return config, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	if err := config.Validate(); err != nil {
+		return config, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+
+	return config, nil
+}
+
+func validateURL(name string, value *string, required bool) error {
+	if value == nil || (*value == "" && !required) {
+		return nil
+	}
+
+	parsed, err := url.Parse(*value)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("%s: not an http or https URL: %q", name, *value)
+	}
+
+	return nil
+}
+
+func validatePortNamePrefixes(name string, value *string) error {
+	if value == nil {
+		return nil
+	}
+
+	if _, err := snmp_utilities.ParsePortNamePrefixes(*value); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+
+	return nil
+}
+
+func validateSLSQueries(name string, value *string) error {
+	if value == nil {
+		return nil
+	}
+
+	queries, err := sls.ParseQueries(*value)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	if len(queries) == 0 {
+		return fmt.Errorf("%s: must not be empty", name)
+	}
+
+	return nil
+}
+
+func validateOneOf(name string, value *string, allowed ...string) error {
+	if value == nil {
+		return nil
+	}
+
+	for _, allowedValue := range allowed {
+		if strings.EqualFold(*value, allowedValue) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%s: %q is not one of %s", name, *value, strings.Join(allowed, ", "))
+}
+
+func validateNotEmpty(name string, value *string) error {
+	if value != nil && *value == "" {
+		return fmt.Errorf("%s: must not be empty", name)
+	}
+
+	return nil
+}
+
+// validateRequiredWhen makes sure value is given whenever condition is set to true.
+func validateRequiredWhen(name string, value *string, conditionName string, condition *bool) error {
+	if condition == nil || !*condition {
+		return nil
+	}
+	if value == nil || *value == "" {
+		return fmt.Errorf("%s: required when %s is set", name, conditionName)
+	}
+
+	return nil
+}
+
+func validateAtLeast(name string, value *int, minimum int) error {
+	if value != nil && *value < minimum {
+		return fmt.Errorf("%s: must be at least %d", name, minimum)
+	}
+
+	return nil
+}
+
+func validateNotNegative(name string, value interface{}) error {
+	switch value := value.(type) {
+	case *int:
+		if value != nil && *value < 0 {
+			return fmt.Errorf("%s: must not be negative", name)
+		}
+	case *Duration:
+		if value != nil && *value < 0 {
+			return fmt.Errorf("%s: must not be negative", name)
+		}
+	}
+
+	return nil
+}
+
+// validateWholeSeconds is for durations handed on to something that only takes a number of seconds.
+func validateWholeSeconds(name string, value *Duration) error {
+	if value != nil && time.Duration(*value)%time.Second != 0 {
+		return fmt.Errorf("%s: must be a whole number of seconds", name)
+	}
+
+	return nil
+}
+
+// Validate checks every setting that is set, returning all of the problems found.
+func (config Config) Validate() error {
+	errs := []error{
+		validateURL("sls_url", config.SLSURL, true),
+		validateURL("hsm_url", config.HSMURL, true),
+		validateURL("capmc_url", config.CAPMCURL, true),
+		validateOneOf("log_level", config.LogLevel, "", "DEBUG", "INFO", "WARN", "ERROR", "FATAL", "PANIC"),
+
+		validateNotNegative("http.retry_max", config.HTTP.RetryMax),
+		validateNotNegative("http.retry_wait_max", config.HTTP.RetryWaitMax),
+
+		validateNotEmpty("vault.comp_credentials_path", config.Vault.CompCredentialsPath),
+		validateNotEmpty("vault.reds_credentials_path", config.Vault.RedsCredentialsPath),
+		validateNotEmpty("vault.pdu_credentials_path", config.Vault.PDUCredentialsPath),
+		validateNotEmpty("vault.journal_path", config.Vault.JournalPath),
+		validateNotNegative("vault.journal_retention", config.Vault.JournalRetention),
+
+		validateOneOf("snmp.mode", config.SNMP.Mode, "", "real", "mock", "record", "replay"),
+		validateNotEmpty("snmp.mock_dir", config.SNMP.MockDir),
+		validateNotEmpty("snmp.fixture_dir", config.SNMP.FixtureDir),
+		validateNotNegative("snmp.retries", config.SNMP.Retries),
+		validateAtLeast("snmp.concurrency", config.SNMP.Concurrency, 1),
+		validateAtLeast("snmp.max_repetitions", config.SNMP.MaxRepetitions, 1),
+		validateNotNegative("snmp.timeout", config.SNMP.Timeout),
+		validatePortNamePrefixes("snmp.port_name_prefixes", config.SNMP.PortNamePrefixes),
+		validateSLSQueries("snmp.switches", config.SNMP.Switches),
+
+		validateRequiredWhen("offline.sls_file", config.Offline.SLSFile, "offline.enabled", config.Offline.Enabled),
+		validateRequiredWhen("offline.hsm_file", config.Offline.HSMFile, "offline.enabled", config.Offline.Enabled),
+
+		validateNotNegative("daemon.schedule_jitter", config.Daemon.ScheduleJitter),
+
+		validateOneOf("report.format", config.Report.Format, report.FormatJSON, report.FormatYAML, report.FormatCSV),
+		validateURL("report.url", config.Report.URL, false),
+
+		validateURL("metrics.pushgateway_url", config.Metrics.PushgatewayURL, false),
+
+		validateNotNegative("phases.timeout", config.Phases.Timeout),
+		validateNotNegative("phases.interval", config.Phases.Interval),
+		validateNotNegative("phases.management_virtual_nodes.interval", config.Phases.ManagementVirtualNodes.Interval),
+		validateNotNegative("phases.management_virtual_nodes.timeout", config.Phases.ManagementVirtualNodes.Timeout),
+		validateNotNegative("phases.management_nodes.interval", config.Phases.ManagementNodes.Interval),
+		validateNotNegative("phases.management_nodes.timeout", config.Phases.ManagementNodes.Timeout),
+		validateNotNegative("phases.management_switch_credentials.interval",
+			config.Phases.ManagementSwitchCredentials.Interval),
+		validateNotNegative("phases.management_switch_credentials.timeout",
+			config.Phases.ManagementSwitchCredentials.Timeout),
+		validateNotNegative("phases.river.interval", config.Phases.River.Interval),
+		validateNotNegative("phases.river.timeout", config.Phases.River.Timeout),
+		validateNotNegative("phases.mountain.interval", config.Phases.Mountain.Interval),
+		validateNotNegative("phases.mountain.timeout", config.Phases.Mountain.Timeout),
+		validateNotEmpty("phases.mountain.script", config.Phases.Mountain.Script),
+		validateNotNegative("phases.mountain.sleep_length", config.Phases.Mountain.SleepLength),
+		validateWholeSeconds("phases.mountain.sleep_length", config.Phases.Mountain.SleepLength),
+		validateNotNegative("phases.rediscover_failed_redfish_endpoints.interval",
+			config.Phases.RediscoverFailedRedfishEndpoints.Interval),
+		validateNotNegative("phases.rediscover_failed_redfish_endpoints.timeout",
+			config.Phases.RediscoverFailedRedfishEndpoints.Timeout),
+	}
+
+	return errors.Join(errs...)
+}
+
+// Render turns the config into YAML or JSON.
+func (config Config) Render(format string) ([]byte, error) {
+	switch format {
+	case "yaml":
+		return yaml.Marshal(config)
+	case "json":
+		return json.MarshalIndent(config, "", "  ")
+	}
+
+	return nil, fmt.Errorf("unknown config format: %s", format)
+}
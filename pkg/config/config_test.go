@@ -0,0 +1,153 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestValidate loads config files and checks every problem with them is reported, each by the name of the setting.
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		wantErrs []string
+	}{
+		{
+			name: "empty",
+			file: "",
+		},
+		{
+			name: "valid",
+			file: `
+sls_url: http://cray-sls
+hsm_url: https://api-gw-service-nmn.local/apis/smd
+log_level: DEBUG
+vault:
+  journal_retention: 720h
+snmp:
+  concurrency: 4
+  switches: comptype_mgmt_switch:River,comptype_cdu_mgmt_switch
+  port_name_prefixes: dell-os10=ethernet,eth
+offline:
+  enabled: true
+  sls_file: sls.json
+  hsm_file: hsm.json
+phases:
+  mountain:
+    sleep_length: 30s
+`,
+		},
+		{
+			name:     "not a URL",
+			file:     "sls_url: cray-sls",
+			wantErrs: []string{"sls_url"},
+		},
+		{
+			name:     "unknown log level",
+			file:     "log_level: VERBOSE",
+			wantErrs: []string{"log_level"},
+		},
+		{
+			name:     "negative count",
+			file:     "http:\n  retry_max: -1",
+			wantErrs: []string{"http.retry_max"},
+		},
+		{
+			name:     "too few workers",
+			file:     "snmp:\n  concurrency: 0",
+			wantErrs: []string{"snmp.concurrency"},
+		},
+		{
+			name:     "no switch types",
+			file:     `snmp: {switches: ""}`,
+			wantErrs: []string{"snmp.switches"},
+		},
+		{
+			name:     "unknown switch class",
+			file:     "snmp:\n  switches: comptype_mgmt_switch:Lake",
+			wantErrs: []string{"snmp.switches"},
+		},
+		{
+			name:     "unknown vendor profile",
+			file:     "snmp:\n  port_name_prefixes: juniper=xe",
+			wantErrs: []string{"snmp.port_name_prefixes"},
+		},
+		{
+			name:     "negative journal retention",
+			file:     "vault:\n  journal_retention: -1h",
+			wantErrs: []string{"vault.journal_retention"},
+		},
+		{
+			name:     "sub-second mountain sleep length",
+			file:     "phases:\n  mountain:\n    sleep_length: 1500ms",
+			wantErrs: []string{"phases.mountain.sleep_length"},
+		},
+		{
+			name:     "offline without files",
+			file:     "offline:\n  enabled: true",
+			wantErrs: []string{"offline.sls_file", "offline.hsm_file"},
+		},
+		{
+			name: "every problem is reported",
+			file: `
+capmc_url: ftp://capmc
+report:
+  format: xml
+phases:
+  river:
+    timeout: -5m
+`,
+			wantErrs: []string{"capmc_url", "report.format", "phases.river.timeout"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(path, []byte(test.file), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := Load(path)
+			if len(test.wantErrs) == 0 {
+				if err != nil {
+					t.Errorf("Load() error = %v, want none", err)
+				}
+				return
+			}
+
+			if err == nil {
+				t.Fatalf("Load() returned no error, want errors for %v", test.wantErrs)
+			}
+			for _, wantErr := range test.wantErrs {
+				if !strings.Contains(err.Error(), wantErr+":") {
+					t.Errorf("Load() error = %v, want an error for %s", err, wantErr)
+				}
+			}
+		})
+	}
+}
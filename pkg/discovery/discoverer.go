@@ -0,0 +1,97 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+// Package discovery holds the logic for finding hardware and telling HSM about it, along with the phases that make up
+// a discovery run. Each phase is a Discoverer that names the phases it has to run after, and a Runner works out the
+// order, runs whatever it can at the same time and collects the results. The Discovery type does the work against
+// SLS, HSM, Vault and the management switches with clients supplied by the caller.
+package discovery
+
+import (
+	"context"
+	"time"
+)
+
+// Discoverer is a single phase of discovery, such as River or Mountain discovery.
+type Discoverer interface {
+	// Name identifies the phase in logs, reports, metrics and the control API, so it must be unique.
+	Name() string
+
+	// Dependencies names the phases that have to finish before this one can start. They only order the phases, a
+	// phase still runs when one it depends on failed. Phases that aren't part of a run are ignored, so disabling a
+	// phase doesn't stop the phases after it from running.
+	Dependencies() []string
+
+	// Run does the work of the phase. The context is cancelled once the phase times out, phases are expected to stop
+	// at the next point it is safe to do so.
+	Run(ctx context.Context) Result
+}
+
+// Result is the outcome of running a phase.
+type Result struct {
+	Name     string
+	Started  time.Time
+	Finished time.Time
+
+	// Err is set when the phase failed, or why it was skipped.
+	Err error
+
+	// Skipped is set when the phase never ran.
+	Skipped bool
+}
+
+// Failed is true when the phase failed or could not run.
+func (result Result) Failed() bool {
+	return result.Err != nil
+}
+
+// Duration is how long the phase ran for.
+func (result Result) Duration() time.Duration {
+	return result.Finished.Sub(result.Started)
+}
+
+type funcDiscoverer struct {
+	name         string
+	dependencies []string
+	run          func(ctx context.Context) error
+}
+
+// NewDiscoverer makes a Discoverer out of a function that returns an error when the phase fails.
+func NewDiscoverer(name string, dependencies []string, run func(ctx context.Context) error) Discoverer {
+	return funcDiscoverer{
+		name:         name,
+		dependencies: dependencies,
+		run:          run,
+	}
+}
+
+func (discoverer funcDiscoverer) Name() string {
+	return discoverer.name
+}
+
+func (discoverer funcDiscoverer) Dependencies() []string {
+	return discoverer.dependencies
+}
+
+func (discoverer funcDiscoverer) Run(ctx context.Context) Result {
+	return Result{Err: discoverer.run(ctx)}
+}
@@ -0,0 +1,75 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package discovery
+
+import (
+	compcredentials "github.com/Cray-HPE/hms-compcredentials"
+	"github.com/Cray-HPE/hms-discovery/pkg/hsm"
+	"github.com/Cray-HPE/hms-discovery/pkg/sls"
+	"github.com/Cray-HPE/hms-discovery/pkg/switches"
+	"github.com/hashicorp/go-retryablehttp"
+	"go.uber.org/zap"
+)
+
+// CredentialStore is where the credentials for individual BMCs and switches are kept, normally a
+// *compcredentials.CompCredStore.
+type CredentialStore interface {
+	GetCompCred(xname string) (compcredentials.CompCredentials, error)
+}
+
+// DefaultCredentialStore is where the credentials used for hardware that has none of its own are kept, normally a
+// *switches.RedsCredStore.
+type DefaultCredentialStore interface {
+	GetDefaultCredentials() (map[string]switches.RedsCredentials, error)
+	GetDefaultSwitchCredentials() (switches.SwitchCredentials, error)
+}
+
+// SwitchCommunityStore is where the SNMPv2c communities of individual management switches are kept, normally a
+// *switches.RedsCredStore.
+type SwitchCommunityStore interface {
+	GetSwitchCommunity(xname string) (switches.SwitchCommunity, error)
+}
+
+// Discovery is everything needed to work out what hardware is on the system and tell HSM about it. All of the clients
+// are supplied by the caller so the same logic can be used from discovery itself and from other tools.
+type Discovery struct {
+	// SLS is where the layout of the system comes from, every lookup goes through its snapshot.
+	SLS *sls.Client
+	// HSM is where discovered hardware is recorded.
+	HSM *hsm.Client
+
+	HTTPClient         *retryablehttp.Client
+	Logger             *zap.Logger
+	Credentials        CredentialStore
+	DefaultCredentials DefaultCredentialStore
+	// SwitchCommunities is optional, without it switches only use the community from SLS or the defaults.
+	SwitchCommunities SwitchCommunityStore
+}
+
+func (discovery *Discovery) logger() *zap.Logger {
+	if discovery.Logger == nil {
+		return zap.NewNop()
+	}
+
+	return discovery.Logger
+}
@@ -0,0 +1,90 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+	rf "github.com/Cray-HPE/hms-smd/v2/pkg/redfish"
+	"github.com/hashicorp/go-retryablehttp"
+	"go.uber.org/zap"
+)
+
+// CheckBMCRedfish makes sure the Redfish service of a BMC is reachable with the credentials stored for it.
+func (discovery *Discovery) CheckBMCRedfish(xname string, fqdn string) (err error) {
+	// Endpoint might require authentication, get what we need.
+	creds, credsErr := discovery.Credentials.GetCompCred(xname)
+	if credsErr != nil {
+		// Credential error...log it but don't stop, might not need the authentication.
+		discovery.logger().Error("Failed to get credentials from Vault for xname!",
+			zap.String("xname", xname), zap.Error(credsErr))
+	}
+
+	var redfishURLs []string
+	redfishURLs = append(redfishURLs, fmt.Sprintf("https://%s/redfish/v1", fqdn))
+	redfishURLs = append(redfishURLs, fmt.Sprintf("https://%s/redfish/v1/", fqdn))
+
+	for _, redfishURL := range redfishURLs {
+		request, requestErr := retryablehttp.NewRequest("GET", redfishURL, nil)
+		if requestErr != nil {
+			err = fmt.Errorf("failed to make request: %w", requestErr)
+			continue
+		}
+		request.SetBasicAuth(creds.Username, creds.Password)
+
+		response, doErr := discovery.HTTPClient.Do(request)
+		base.DrainAndCloseResponseBody(response)
+		if doErr != nil {
+			err = fmt.Errorf("failed to execute GET request: %w", doErr)
+			continue
+		}
+
+		if response.StatusCode != http.StatusOK {
+			err = fmt.Errorf("unexpected status code from Redfish: %d", response.StatusCode)
+			continue
+		} else {
+			return nil
+		}
+	}
+
+	return
+}
+
+// NewRedfishEndpoint describes a BMC the way HSM is told about it so that it goes and discovers it.
+func NewRedfishEndpoint(xname string, fqdn string, macAddr string) rf.RedfishEPDescription {
+	return rf.RedfishEPDescription{
+		ID:             xname,
+		FQDN:           fqdn,
+		MACAddr:        macAddr,
+		RediscOnUpdate: true,
+		Enabled:        true,
+	}
+}
+
+// InformHSM tells HSM about a BMC so that it goes and discovers it.
+func (discovery *Discovery) InformHSM(ctx context.Context, xname string, fqdn string, macAddr string) error {
+	return discovery.HSM.UpsertRedfishEndpoint(ctx, NewRedfishEndpoint(xname, fqdn, macAddr))
+}
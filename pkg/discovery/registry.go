@@ -0,0 +1,122 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package discovery
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Registry holds every phase that is available to run, in the order they were registered.
+type Registry struct {
+	lock        sync.Mutex
+	discoverers []Discoverer
+}
+
+// DefaultRegistry is where the built in phases are registered, site specific phases can register themselves here from
+// an init function to be picked up without any other changes.
+var DefaultRegistry = NewRegistry()
+
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register adds a phase to the registry, failing if a phase with the same name is already registered.
+func (registry *Registry) Register(discoverer Discoverer) error {
+	registry.lock.Lock()
+	defer registry.lock.Unlock()
+
+	for _, existing := range registry.discoverers {
+		if existing.Name() == discoverer.Name() {
+			return fmt.Errorf("phase %s is already registered", discoverer.Name())
+		}
+	}
+
+	registry.discoverers = append(registry.discoverers, discoverer)
+	return nil
+}
+
+// Get looks up a phase by name.
+func (registry *Registry) Get(name string) (Discoverer, bool) {
+	registry.lock.Lock()
+	defer registry.lock.Unlock()
+
+	for _, discoverer := range registry.discoverers {
+		if discoverer.Name() == name {
+			return discoverer, true
+		}
+	}
+
+	return nil, false
+}
+
+// All returns every registered phase in the order they were registered.
+func (registry *Registry) All() []Discoverer {
+	registry.lock.Lock()
+	defer registry.lock.Unlock()
+
+	return append([]Discoverer(nil), registry.discoverers...)
+}
+
+// Names returns the name of every registered phase in the order they were registered.
+func (registry *Registry) Names() []string {
+	var names []string
+	for _, discoverer := range registry.All() {
+		names = append(names, discoverer.Name())
+	}
+
+	return names
+}
+
+// Validate makes sure every dependency is a registered phase and that no phases depend on each other in a cycle.
+func (registry *Registry) Validate() error {
+	discoverers := registry.All()
+
+	registered := map[string]bool{}
+	for _, discoverer := range discoverers {
+		registered[discoverer.Name()] = true
+	}
+
+	for _, discoverer := range discoverers {
+		for _, dependency := range discoverer.Dependencies() {
+			if !registered[dependency] {
+				return fmt.Errorf("phase %s depends on unknown phase %s", discoverer.Name(), dependency)
+			}
+		}
+	}
+
+	_, err := Order(discoverers)
+	return err
+}
+
+// Register adds a phase to DefaultRegistry.
+func Register(discoverer Discoverer) error {
+	return DefaultRegistry.Register(discoverer)
+}
+
+// MustRegister adds a phase to DefaultRegistry, panicking if it can't. It is meant to be called from init functions.
+func MustRegister(discoverer Discoverer) {
+	if err := Register(discoverer); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,201 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package discovery
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/Cray-HPE/hms-discovery/pkg/snmp_utilities"
+	"github.com/Cray-HPE/hms-discovery/pkg/switches"
+	"github.com/Cray-HPE/hms-smd/v2/pkg/sm"
+)
+
+// WalkedSwitch is what River discovery needs from the walk of a management switch to work out which xname a MAC
+// address belongs to.
+type WalkedSwitch struct {
+	ManagementSwitch switches.ManagementSwitch
+	Profile          snmp_utilities.VendorProfile
+	// MACPortMap maps the MAC addresses learned on the edge ports of the switch to the port they were learned on.
+	MACPortMap map[string]string
+	Ports      SwitchPorts
+	// VendorNames maps the ports that matched an SLS switch connector to the VendorName of the connector.
+	VendorNames map[string]string
+	// ARPTable maps MAC addresses to IP addresses, when the ARP table of the switch was collected.
+	ARPTable map[string]string
+}
+
+// VendorName is the name SLS gives a port of the switch, which is the port name when it didn't match a connector.
+func (walked WalkedSwitch) VendorName(port string) string {
+	if vendorName, found := walked.VendorNames[port]; found {
+		return vendorName
+	}
+
+	return port
+}
+
+// SwitchPort is a port of a management switch, named as it is in SLS when it matched a connector.
+type SwitchPort struct {
+	SwitchXname string
+	Port        string
+}
+
+// candidatePorts are the ports a device a MAC address was learned from could be cabled to. That is the port it was
+// learned on, unless that is a LAG, in which case it is every member of the LAG on the switch and on its MLAG peers.
+func candidatePorts(walkedSwitches map[string]WalkedSwitch, switchXname string, port string) []SwitchPort {
+	walked := walkedSwitches[switchXname]
+	members, isLAG := walked.Ports.LAGs[port]
+	if !isLAG {
+		return []SwitchPort{{SwitchXname: switchXname, Port: walked.VendorName(port)}}
+	}
+
+	var candidates []SwitchPort
+	for _, member := range members {
+		candidates = append(candidates, SwitchPort{SwitchXname: switchXname, Port: walked.VendorName(member)})
+	}
+
+	for _, peer := range mlagPeers(walkedSwitches, switchXname) {
+		// The peer's half of the MLAG has the same name, only an edge LAG there can be the other half.
+		peerMembers, found := peer.Ports.LAGs[port]
+		if !found || peer.Ports.Classify(peer.Profile, port) != snmp_utilities.PortClassEdge {
+			continue
+		}
+
+		for _, member := range peerMembers {
+			candidates = append(candidates, SwitchPort{
+				SwitchXname: peer.ManagementSwitch.Xname,
+				Port:        peer.VendorName(member),
+			})
+		}
+	}
+
+	return candidates
+}
+
+// mlagPeers are the other walked switches heard with LLDP on the inter-switch ports of a switch, by their xname or one
+// of their aliases. Those are the switches it can be paired with for MLAG or VSX.
+func mlagPeers(walkedSwitches map[string]WalkedSwitch, switchXname string) (peers []WalkedSwitch) {
+	for _, neighbor := range walkedSwitches[switchXname].Ports.InterSwitchNeighbors() {
+		for peerXname, peer := range walkedSwitches {
+			if peerXname == switchXname || !peer.ManagementSwitch.HasName(neighbor.SysName) {
+				continue
+			}
+
+			peers = append(peers, peer)
+		}
+	}
+
+	sort.Slice(peers, func(i, j int) bool {
+		return peers[i].ManagementSwitch.Xname < peers[j].ManagementSwitch.Xname
+	})
+
+	return slices.CompactFunc(peers, func(a, b WalkedSwitch) bool {
+		return a.ManagementSwitch.Xname == b.ManagementSwitch.Xname
+	})
+}
+
+// ResolveSwitchPort looks up in SLS the xname of the device cabled to the port of a switch a MAC address was learned
+// on. When the port is a LAG each of its members, and those of the same LAG on the MLAG peers of the switch, are tried
+// until one of them has a connector. The port the device is cabled to is returned along with it, named as it is in
+// SLS.
+func (discovery *Discovery) ResolveSwitchPort(ctx context.Context, walkedSwitches map[string]WalkedSwitch,
+	switchXname string, port string) (xname string, connector SwitchPort, err error) {
+	var lookupErrs []error
+	for _, connector = range candidatePorts(walkedSwitches, switchXname, port) {
+		xname, err = discovery.GetXnameForSwitchPort(ctx, connector.SwitchXname, connector.Port)
+		if err == nil {
+			return
+		}
+
+		lookupErrs = append(lookupErrs, fmt.Errorf("%s %s: %w", connector.SwitchXname, connector.Port, err))
+	}
+
+	err = errors.Join(lookupErrs...)
+	if err == nil {
+		err = fmt.Errorf("LAG %s has no members", port)
+	}
+
+	return
+}
+
+// ARPCandidates are the MAC addresses learned on the edge ports of the switches that are in the ARP table of one of
+// them but that HSM has no EthernetInterface for, made into unknown components with the IP address from the ARP
+// table so they can be resolved the same way as those that asked DHCP for an address.
+func (discovery *Discovery) ARPCandidates(ctx context.Context,
+	walkedSwitches map[string]WalkedSwitch) ([]sm.CompEthInterfaceV2, error) {
+	arpTable := map[string]string{}
+	for _, walked := range walkedSwitches {
+		for mac, ip := range walked.ARPTable {
+			arpTable[mac] = ip
+		}
+	}
+	if len(arpTable) == 0 {
+		return nil, nil
+	}
+
+	ethernetInterfaces, err := discovery.HSM.GetEthernetInterfaces(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get EthernetInterfaces from HSM: %w", err)
+	}
+
+	knownMACs := map[string]bool{}
+	for _, ethernetInterface := range ethernetInterfaces {
+		knownMACs[strings.ToLower(strings.ReplaceAll(ethernetInterface.MACAddr, ":", ""))] = true
+	}
+
+	candidateMACs := map[string]bool{}
+	for _, walked := range walkedSwitches {
+		// Only edge ports are left in the MAC port map, so these are all cabled to the switch.
+		for mac := range walked.MACPortMap {
+			if _, found := arpTable[mac]; found && !knownMACs[mac] {
+				candidateMACs[mac] = true
+			}
+		}
+	}
+
+	var candidates []sm.CompEthInterfaceV2
+	for mac := range candidateMACs {
+		macBytes, decodeErr := hex.DecodeString(mac)
+		if decodeErr != nil {
+			continue
+		}
+
+		candidates = append(candidates, sm.CompEthInterfaceV2{
+			ID:      mac,
+			Desc:    "Found in the ARP table of a management switch",
+			MACAddr: net.HardwareAddr(macBytes).String(),
+			IPAddrs: []sm.IPAddressMapping{{IPAddr: arpTable[mac]}},
+		})
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].ID < candidates[j].ID
+	})
+
+	return candidates, nil
+}
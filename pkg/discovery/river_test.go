@@ -0,0 +1,129 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package discovery
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Cray-HPE/hms-discovery/pkg/snmp_utilities"
+	"github.com/Cray-HPE/hms-discovery/pkg/switches"
+)
+
+// mlagPair is a pair of switches with port-channel10 bonded across both of them, cabled to each other with
+// port-channel100. The ports are named differently in SLS, which must not stop the LAGs from being classified.
+func mlagPair() map[string]WalkedSwitch {
+	walkedSwitch := func(xname, alias, peerAlias, member string) WalkedSwitch {
+		return WalkedSwitch{
+			ManagementSwitch: switches.ManagementSwitch{Xname: xname, Aliases: []string{alias}},
+			Profile:          snmp_utilities.GenericProfile,
+			Ports: SwitchPorts{
+				Neighbors: []snmp_utilities.LLDPNeighbor{
+					{Port: "Eth1/51", SysName: peerAlias + ".example.com", Capabilities: []string{"bridge"}},
+					{Port: "Eth1/52", SysName: peerAlias + ".example.com", Capabilities: []string{"bridge"}},
+				},
+				LLDP: true,
+				LAGs: map[string][]string{
+					"port-channel10":  {member},
+					"port-channel100": {"Eth1/51", "Eth1/52"},
+				},
+			},
+			VendorNames: map[string]string{
+				member:    "ethernet" + member[3:],
+				"Eth1/51": "ethernet1/51",
+				"Eth1/52": "ethernet1/52",
+			},
+		}
+	}
+
+	return map[string]WalkedSwitch{
+		"x3000c0w36": walkedSwitch("x3000c0w36", "sw-leaf-bmc-001", "sw-leaf-bmc-002", "Eth1/25"),
+		"x3000c0w38": walkedSwitch("x3000c0w38", "sw-leaf-bmc-002", "sw-leaf-bmc-001", "Eth1/10"),
+	}
+}
+
+func TestCandidatePorts(t *testing.T) {
+	tests := []struct {
+		name        string
+		switchXname string
+		port        string
+		want        []SwitchPort
+	}{
+		{
+			name:        "edge port matched with SLS",
+			switchXname: "x3000c0w36",
+			port:        "Eth1/25",
+			want:        []SwitchPort{{"x3000c0w36", "ethernet1/25"}},
+		},
+		{
+			name:        "edge port not in SLS",
+			switchXname: "x3000c0w36",
+			port:        "Eth1/30",
+			want:        []SwitchPort{{"x3000c0w36", "Eth1/30"}},
+		},
+		{
+			name:        "MLAG",
+			switchXname: "x3000c0w36",
+			port:        "port-channel10",
+			want:        []SwitchPort{{"x3000c0w36", "ethernet1/25"}, {"x3000c0w38", "ethernet1/10"}},
+		},
+		{
+			name:        "MLAG from the peer",
+			switchXname: "x3000c0w38",
+			port:        "port-channel10",
+			want:        []SwitchPort{{"x3000c0w38", "ethernet1/10"}, {"x3000c0w36", "ethernet1/25"}},
+		},
+		{
+			name:        "inter-switch LAG is not looked for on the peer",
+			switchXname: "x3000c0w36",
+			port:        "port-channel100",
+			want:        []SwitchPort{{"x3000c0w36", "ethernet1/51"}, {"x3000c0w36", "ethernet1/52"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			walkedSwitches := mlagPair()
+			if got := candidatePorts(walkedSwitches, test.switchXname, test.port); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("candidatePorts() = %v, want %v", got, test.want)
+			}
+
+			// Looking up the SLS names must leave the switch's own port names alone.
+			if !reflect.DeepEqual(walkedSwitches, mlagPair()) {
+				t.Errorf("candidatePorts() modified the walked switches")
+			}
+		})
+	}
+}
+
+func TestMLAGPeers(t *testing.T) {
+	walkedSwitches := mlagPair()
+	walkedSwitches["x3000c0w40"] = WalkedSwitch{
+		ManagementSwitch: switches.ManagementSwitch{Xname: "x3000c0w40", Aliases: []string{"sw-spine-001"}},
+	}
+
+	peers := mlagPeers(walkedSwitches, "x3000c0w36")
+	if len(peers) != 1 || peers[0].ManagementSwitch.Xname != "x3000c0w38" {
+		t.Errorf("mlagPeers() = %v, want only x3000c0w38", peers)
+	}
+}
@@ -0,0 +1,219 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package discovery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Runner runs a set of phases, starting each one as soon as the phases it depends on have finished.
+type Runner struct {
+	// DefaultTimeout is how long a phase is allowed to run when it isn't in Timeouts, zero means no limit.
+	DefaultTimeout time.Duration
+	Timeouts       map[string]time.Duration
+
+	// Detach hands phases a context that isn't cancelled along with the one given to Run, so phases that are in
+	// progress get to finish cleanly while phases that haven't started yet are skipped.
+	Detach bool
+
+	// OnStart and OnFinish are called around every phase, including phases that are skipped. As phases run at the
+	// same time they can be called concurrently.
+	OnStart  func(name string, started time.Time)
+	OnFinish func(result Result)
+}
+
+func (runner Runner) timeout(name string) time.Duration {
+	if timeout, found := runner.Timeouts[name]; found && timeout > 0 {
+		return timeout
+	}
+
+	return runner.DefaultTimeout
+}
+
+func (runner Runner) start(name string, started time.Time) {
+	if runner.OnStart != nil {
+		runner.OnStart(name, started)
+	}
+}
+
+func (runner Runner) finish(result Result) {
+	if runner.OnFinish != nil {
+		runner.OnFinish(result)
+	}
+}
+
+func (runner Runner) skip(name string, err error) Result {
+	now := time.Now()
+	result := Result{
+		Name:     name,
+		Started:  now,
+		Finished: now,
+		Err:      err,
+		Skipped:  true,
+	}
+
+	runner.start(name, now)
+	runner.finish(result)
+
+	return result
+}
+
+// runRecovered runs a phase, turning a panic into a failure of the phase so it can't take the other phases, or a
+// daemon, down with it.
+func runRecovered(ctx context.Context, discoverer Discoverer) (result Result) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			result = Result{Err: fmt.Errorf("phase panicked: %v", recovered)}
+		}
+	}()
+
+	return discoverer.Run(ctx)
+}
+
+func (runner Runner) runOne(ctx context.Context, discoverer Discoverer) Result {
+	name := discoverer.Name()
+
+	if ctx.Err() != nil {
+		return runner.skip(name, ctx.Err())
+	}
+
+	phaseCtx := ctx
+	if runner.Detach {
+		phaseCtx = context.WithoutCancel(ctx)
+	}
+
+	timeout := runner.timeout(name)
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		phaseCtx, cancel = context.WithTimeout(phaseCtx, timeout)
+		defer cancel()
+	}
+
+	started := time.Now()
+	runner.start(name, started)
+
+	result := runRecovered(phaseCtx, discoverer)
+	result.Name = name
+	result.Started = started
+	result.Finished = time.Now()
+
+	if result.Err != nil && errors.Is(phaseCtx.Err(), context.DeadlineExceeded) {
+		result.Err = fmt.Errorf("timed out after %s: %w", timeout, result.Err)
+	}
+
+	runner.finish(result)
+
+	return result
+}
+
+// Run runs the given phases, returning their results in the same order. Phases that don't depend on each other run at
+// the same time. A phase still runs when a phase it depends on failed, as each phase does what it can with whatever is
+// in HSM, SLS and Vault at the time. Dependencies on phases that aren't being run are ignored. The only error returned
+// is for phases that can't be ordered.
+func (runner Runner) Run(ctx context.Context, discoverers []Discoverer) ([]Result, error) {
+	if _, err := Order(discoverers); err != nil {
+		return nil, err
+	}
+
+	results := make([]Result, len(discoverers))
+	done := map[string]chan struct{}{}
+	for _, discoverer := range discoverers {
+		done[discoverer.Name()] = make(chan struct{})
+	}
+
+	var waitGroup sync.WaitGroup
+	for i, discoverer := range discoverers {
+		waitGroup.Add(1)
+
+		go func(i int, discoverer Discoverer) {
+			defer waitGroup.Done()
+			defer close(done[discoverer.Name()])
+
+			for _, dependency := range discoverer.Dependencies() {
+				if dependencyDone, found := done[dependency]; found {
+					<-dependencyDone
+				}
+			}
+
+			results[i] = runner.runOne(ctx, discoverer)
+		}(i, discoverer)
+	}
+
+	waitGroup.Wait()
+
+	return results, nil
+}
+
+// Order sorts phases so every phase comes after the phases it depends on, otherwise keeping them in the order given.
+// Dependencies on phases that aren't in the list are ignored.
+func Order(discoverers []Discoverer) ([]Discoverer, error) {
+	remaining := map[string]Discoverer{}
+	for _, discoverer := range discoverers {
+		if _, duplicate := remaining[discoverer.Name()]; duplicate {
+			return nil, fmt.Errorf("phase %s is listed more than once", discoverer.Name())
+		}
+		remaining[discoverer.Name()] = discoverer
+	}
+
+	var ordered []Discoverer
+	for len(remaining) > 0 {
+		progressed := false
+
+		for _, discoverer := range discoverers {
+			if _, pending := remaining[discoverer.Name()]; !pending {
+				continue
+			}
+
+			ready := true
+			for _, dependency := range discoverer.Dependencies() {
+				if _, pending := remaining[dependency]; pending {
+					ready = false
+					break
+				}
+			}
+
+			if ready {
+				ordered = append(ordered, discoverer)
+				delete(remaining, discoverer.Name())
+				progressed = true
+			}
+		}
+
+		if !progressed {
+			var cycle []string
+			for _, discoverer := range discoverers {
+				if _, pending := remaining[discoverer.Name()]; pending {
+					cycle = append(cycle, discoverer.Name())
+				}
+			}
+
+			return nil, fmt.Errorf("phases depend on each other in a cycle: %v", cycle)
+		}
+	}
+
+	return ordered, nil
+}
@@ -0,0 +1,239 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package discovery
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestRunnerDetach cancels the context given to Run while a phase is in progress, which only reaches the phase when
+// the runner isn't detached, timeout or not.
+func TestRunnerDetach(t *testing.T) {
+	tests := []struct {
+		name          string
+		detach        bool
+		timeout       time.Duration
+		wantCancelled bool
+	}{
+		{name: "attached", detach: false, wantCancelled: true},
+		{name: "attached with timeout", detach: false, timeout: time.Hour, wantCancelled: true},
+		{name: "detached", detach: true, wantCancelled: false},
+		{name: "detached with timeout", detach: true, timeout: time.Hour, wantCancelled: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			started := make(chan struct{})
+			phase := NewDiscoverer("phase", nil, func(phaseCtx context.Context) error {
+				close(started)
+				cancel()
+
+				// Give the cancellation long enough to get through when it is going to.
+				select {
+				case <-phaseCtx.Done():
+					return phaseCtx.Err()
+				case <-time.After(100 * time.Millisecond):
+					return nil
+				}
+			})
+
+			runner := Runner{
+				Detach:   test.detach,
+				Timeouts: map[string]time.Duration{"phase": test.timeout},
+			}
+			results, err := runner.Run(ctx, []Discoverer{phase})
+			if err != nil {
+				t.Fatalf("Run() returned error: %v", err)
+			}
+
+			<-started
+			if cancelled := results[0].Failed(); cancelled != test.wantCancelled {
+				t.Errorf("phase cancelled = %v, want %v (err: %v)", cancelled, test.wantCancelled, results[0].Err)
+			}
+		})
+	}
+}
+
+func TestRunnerTimeout(t *testing.T) {
+	phase := NewDiscoverer("phase", nil, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	runner := Runner{
+		Detach:   true,
+		Timeouts: map[string]time.Duration{"phase": 10 * time.Millisecond},
+	}
+	results, err := runner.Run(context.Background(), []Discoverer{phase})
+	if err != nil {
+		t.Fatalf("Run() returned error: %v", err)
+	}
+
+	if !results[0].Failed() {
+		t.Errorf("phase didn't time out")
+	}
+}
+
+// TestRunnerDependencies checks dependencies only order the phases, a phase still runs after one it depends on failed.
+func TestRunnerDependencies(t *testing.T) {
+	var lock sync.Mutex
+	var ran []string
+	phase := func(name string, dependencies []string, err error) Discoverer {
+		return NewDiscoverer(name, dependencies, func(ctx context.Context) error {
+			lock.Lock()
+			ran = append(ran, name)
+			lock.Unlock()
+
+			return err
+		})
+	}
+
+	phases := []Discoverer{
+		phase("rediscovery", []string{"river", "mountain"}, nil),
+		phase("river", []string{"credentials"}, nil),
+		phase("credentials", nil, errors.New("SLS is down")),
+		phase("mountain", []string{"river"}, errors.New("script failed")),
+	}
+
+	results, err := Runner{}.Run(context.Background(), phases)
+	if err != nil {
+		t.Fatalf("Run() returned error: %v", err)
+	}
+
+	want := []string{"credentials", "river", "mountain", "rediscovery"}
+	if len(ran) != len(want) {
+		t.Fatalf("ran %v, want %v", ran, want)
+	}
+	for i := range want {
+		if ran[i] != want[i] {
+			t.Fatalf("ran %v, want %v", ran, want)
+		}
+	}
+
+	for _, result := range results {
+		if result.Skipped {
+			t.Errorf("phase %s was skipped: %v", result.Name, result.Err)
+		}
+	}
+}
+
+// TestRunnerPanic checks a phase that panics fails on its own, without stopping the phases after it.
+func TestRunnerPanic(t *testing.T) {
+	phases := []Discoverer{
+		NewDiscoverer("panics", nil, func(ctx context.Context) error {
+			panic("missing default credentials")
+		}),
+		NewDiscoverer("after", []string{"panics"}, func(ctx context.Context) error {
+			return nil
+		}),
+	}
+
+	results, err := Runner{}.Run(context.Background(), phases)
+	if err != nil {
+		t.Fatalf("Run() returned error: %v", err)
+	}
+
+	if !results[0].Failed() || results[0].Name != "panics" {
+		t.Errorf("panicking phase result = %+v, want a failure", results[0])
+	}
+	if results[1].Failed() {
+		t.Errorf("phase after the panic failed: %v", results[1].Err)
+	}
+}
+
+// TestOrder checks phases are moved after the phases they depend on and otherwise kept in the order given.
+func TestOrder(t *testing.T) {
+	phase := func(name string, dependencies ...string) Discoverer {
+		return NewDiscoverer(name, dependencies, func(ctx context.Context) error { return nil })
+	}
+
+	tests := []struct {
+		name    string
+		phases  []Discoverer
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:   "no dependencies",
+			phases: []Discoverer{phase("river"), phase("mountain"), phase("credentials")},
+			want:   []string{"river", "mountain", "credentials"},
+		},
+		{
+			name:   "dependency listed after",
+			phases: []Discoverer{phase("river", "credentials"), phase("mountain"), phase("credentials")},
+			want:   []string{"mountain", "credentials", "river"},
+		},
+		{
+			name: "chain of dependencies",
+			phases: []Discoverer{
+				phase("rediscovery", "river"), phase("river", "credentials"), phase("credentials"),
+			},
+			want: []string{"credentials", "river", "rediscovery"},
+		},
+		{
+			name:   "dependency not in the list",
+			phases: []Discoverer{phase("river", "credentials"), phase("mountain")},
+			want:   []string{"river", "mountain"},
+		},
+		{
+			name:    "duplicate phase",
+			phases:  []Discoverer{phase("river"), phase("river")},
+			wantErr: true,
+		},
+		{
+			name:    "cycle",
+			phases:  []Discoverer{phase("river", "mountain"), phase("mountain", "river"), phase("credentials")},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ordered, err := Order(test.phases)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("Order() error = %v, wantErr %v", err, test.wantErr)
+			}
+
+			var names []string
+			for _, discoverer := range ordered {
+				names = append(names, discoverer.Name())
+			}
+			if !slices.Equal(names, test.want) {
+				t.Errorf("Order() = %v, want %v", names, test.want)
+			}
+
+			// Run refuses to start anything when the phases can't be ordered.
+			if _, err := (Runner{}).Run(context.Background(), test.phases); (err != nil) != test.wantErr {
+				t.Errorf("Run() error = %v, wantErr %v", err, test.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,446 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Cray-HPE/hms-discovery/pkg/sls"
+	"github.com/Cray-HPE/hms-discovery/pkg/snmp_utilities"
+	"github.com/Cray-HPE/hms-discovery/pkg/switches"
+	sls_common "github.com/Cray-HPE/hms-sls/v2/pkg/sls-common"
+	"go.uber.org/zap"
+)
+
+// VaultPrefix marks SLS properties whose real value is kept in Vault.
+const VaultPrefix = "vault://"
+
+// GetXnameForSwitchPort looks up in SLS the xname of the device that is cabled to a port of a management switch.
+func (discovery *Discovery) GetXnameForSwitchPort(ctx context.Context, managementSwitchXname string,
+	portName string) (xname string, err error) {
+	snapshot, err := discovery.SLS.Snapshot(ctx)
+	if err != nil {
+		return
+	}
+
+	// Whichever class the switch is, the hardware cabled to it is discovered the River way.
+	switchConnector, found := snapshot.SwitchConnector(managementSwitchXname, portName)
+	if !found {
+		err = fmt.Errorf("no results found for switch/port combination")
+		return
+	}
+
+	var switchConnectorProperties sls_common.ComptypeMgmtSwitchConnector
+	decodeErr := sls.DecodeExtraProperties(switchConnector, &switchConnectorProperties)
+	if decodeErr != nil {
+		err = fmt.Errorf("unable to decode switch connector properties: %w", decodeErr)
+		return
+	}
+
+	// Make sure there is at least 1 NodeNic.
+	if len(switchConnectorProperties.NodeNics) == 0 {
+		err = fmt.Errorf("no NodeNics defined for switch connector")
+		return
+	}
+
+	// That said, there should only be 1, any more than that and we have ambiguity.
+	if len(switchConnectorProperties.NodeNics) > 1 {
+		err = fmt.Errorf("more than one NodeNic for switch connector, can not determine xname")
+		return
+	}
+
+	// Finally, the only thing left must be the name we were after.
+	xname = switchConnectorProperties.NodeNics[0]
+
+	return
+}
+
+// InvalidSwitch is a management switch that can't be walked because of what SLS has for it. The ManagementSwitch has
+// as much filled in as could be made out.
+type InvalidSwitch struct {
+	switches.ManagementSwitch
+	Err error
+}
+
+// GetSwitches returns every management switch in SLS matching any of the queries along with the SNMP credentials to
+// walk it with. Switch specific credentials are used where they are set, falling back to those in SLS and then to the
+// defaults. Switches that can't be walked because of what SLS has for them are returned separately.
+func (discovery *Discovery) GetSwitches(ctx context.Context, queries ...sls.Query) (
+	managementSwitches []switches.ManagementSwitch, invalidSwitches []InvalidSwitch, err error) {
+	snapshot, err := discovery.SLS.Snapshot(ctx)
+	if err != nil {
+		return
+	}
+
+	// Queries can overlap, a switch is only returned once.
+	var genericHardware []sls_common.GenericHardware
+	seen := map[string]bool{}
+	for _, query := range queries {
+		for _, hardware := range snapshot.Search(query) {
+			if !seen[hardware.Xname] {
+				seen[hardware.Xname] = true
+				genericHardware = append(genericHardware, hardware)
+			}
+		}
+	}
+
+	logger := discovery.logger()
+
+	// Start by getting the default switch credentials.
+	defaultSwitchCredentials, switchErr := discovery.DefaultCredentials.GetDefaultSwitchCredentials()
+	if switchErr != nil {
+		logger.Error("Unable to get default switch credentials!", zap.Error(switchErr))
+	}
+
+	// Build the list of switches from the generic hardware.
+	for _, genericSwitch := range genericHardware {
+		var switchProperties switches.SwitchProperties
+		decodeErr := sls.DecodeExtraProperties(genericSwitch, &switchProperties)
+		if decodeErr != nil {
+			// Might be a one off...don't quit over it.
+			logger.Error("Unable to decode switch properties!", zap.Error(decodeErr),
+				zap.String("xname", genericSwitch.Xname))
+			invalidSwitches = append(invalidSwitches, InvalidSwitch{
+				ManagementSwitch: switches.ManagementSwitch{Xname: genericSwitch.Xname},
+				Err:              fmt.Errorf("unable to decode switch properties: %w", decodeErr),
+			})
+			continue
+		}
+
+		snmpVersion, versionErr := switches.ParseSNMPVersion(switchProperties.SNMPVersion)
+		if versionErr != nil {
+			logger.Error("Unable to determine SNMP version of switch!", zap.Error(versionErr),
+				zap.String("xname", genericSwitch.Xname))
+			invalidSwitches = append(invalidSwitches, InvalidSwitch{
+				ManagementSwitch: switches.ManagementSwitch{
+					Xname:   genericSwitch.Xname,
+					Aliases: switchProperties.Aliases,
+					Address: switchProperties.IP4Addr,
+					Brand:   switchProperties.Brand,
+					Model:   switchProperties.Model,
+				},
+				Err: fmt.Errorf("unable to determine SNMP version: %w", versionErr),
+			})
+			continue
+		}
+
+		// At this point we need to retrieve from Vault what we need.
+		// Start by trying the hardware specific credentials.
+		switchCreds, credErr := discovery.Credentials.GetCompCred(genericSwitch.Xname)
+		if credErr != nil {
+			logger.Error("Unable to get credentials for switch!", zap.Error(credErr))
+		}
+
+		// The credentials we have for the specific hardware might be blank,
+		// supplement with the defaults where necessary.
+		if switchCreds.Username == "" {
+			if switchProperties.SNMPUsername != "" &&
+				!strings.HasPrefix(switchProperties.SNMPUsername, VaultPrefix) {
+				switchCreds.Username = switchProperties.SNMPUsername
+			} else {
+				switchCreds.Username = defaultSwitchCredentials.SNMPUsername
+			}
+		}
+		if switchCreds.SNMPPrivPass == "" {
+			if switchProperties.SNMPPrivPassword != "" &&
+				!strings.HasPrefix(switchProperties.SNMPPrivPassword, VaultPrefix) {
+				switchCreds.SNMPPrivPass = switchProperties.SNMPPrivPassword
+			} else {
+				switchCreds.SNMPPrivPass = defaultSwitchCredentials.SNMPPrivPassword
+			}
+		}
+		if switchCreds.SNMPAuthPass == "" {
+			if switchProperties.SNMPAuthPassword != "" &&
+				!strings.HasPrefix(switchProperties.SNMPAuthPassword, VaultPrefix) {
+				switchCreds.SNMPAuthPass = switchProperties.SNMPAuthPassword
+			} else {
+				switchCreds.SNMPAuthPass = defaultSwitchCredentials.SNMPAuthPassword
+			}
+		}
+
+		// The SNMPv2c community of the switch is kept on its own, again falling back on the defaults.
+		var switchCommunity switches.SwitchCommunity
+		if discovery.SwitchCommunities != nil {
+			switchCommunity, credErr = discovery.SwitchCommunities.GetSwitchCommunity(genericSwitch.Xname)
+			if credErr != nil {
+				logger.Error("Unable to get SNMP community for switch!", zap.Error(credErr))
+			}
+		}
+		if switchCommunity.SNMPCommunity == "" {
+			if switchProperties.SNMPCommunity != "" &&
+				!strings.HasPrefix(switchProperties.SNMPCommunity, VaultPrefix) {
+				switchCommunity.SNMPCommunity = switchProperties.SNMPCommunity
+			} else {
+				switchCommunity.SNMPCommunity = defaultSwitchCredentials.SNMPCommunity
+			}
+		}
+
+		newSwitch := switches.ManagementSwitch{
+			Xname:            genericSwitch.Xname,
+			Aliases:          switchProperties.Aliases,
+			Address:          switchProperties.IP4Addr,
+			SNMPVersion:      snmpVersion,
+			SNMPUser:         switchProperties.SNMPUsername,
+			SNMPAuthPassword: switchCreds.SNMPAuthPass,
+			SNMPAuthProtocol: switchProperties.SNMPAuthProtocol,
+			SNMPPrivPassword: switchCreds.SNMPPrivPass,
+			SNMPPrivProtocol: switchProperties.SNMPPrivProtocol,
+			SNMPCommunity:    switchCommunity.SNMPCommunity,
+			Brand:            switchProperties.Brand,
+			Model:            switchProperties.Model,
+		}
+
+		managementSwitches = append(managementSwitches, newSwitch)
+	}
+
+	return
+}
+
+// IdentifySwitch asks a management switch what it is and picks the vendor profile to walk it with. Switches that
+// can't be asked, or don't answer, get the profile for their SLS Brand. This is the first time the switch is
+// contacted, so the error it didn't answer with is returned for the caller to decide whether to carry on.
+func (discovery *Discovery) IdentifySwitch(snmpInterface snmp_utilities.SNMPInterface,
+	managementSwitch switches.ManagementSwitch) (identity snmp_utilities.SwitchIdentity,
+	profile snmp_utilities.VendorProfile, err error) {
+	if walker, ok := snmpInterface.(snmp_utilities.Walker); ok {
+		identity, err = snmp_utilities.Identify(walker)
+	}
+
+	profile = snmp_utilities.SelectProfile(identity, managementSwitch.Brand)
+
+	return
+}
+
+// SwitchPorts is what was found out about the ports of a management switch while walking it for MAC addresses.
+type SwitchPorts struct {
+	// Names of every port on the switch.
+	Names []string
+	// Neighbors heard with LLDP on the ports of the switch, LLDP is false when there are none to go on.
+	Neighbors []snmp_utilities.LLDPNeighbor
+	LLDP      bool
+	// LAGs are the member ports of each link aggregation on the switch, by the name of the aggregation.
+	LAGs map[string][]string
+	// UplinkMACs is how many MAC addresses were left out for being learned on a port cabled to another switch.
+	UplinkMACs int
+}
+
+// InterSwitchNeighbors are the neighbors that are switches, the ports they were heard on are cabled to other switches.
+func (ports SwitchPorts) InterSwitchNeighbors() (neighbors []snmp_utilities.LLDPNeighbor) {
+	for _, neighbor := range ports.Neighbors {
+		if neighbor.IsSwitch() {
+			neighbors = append(neighbors, neighbor)
+		}
+	}
+
+	return
+}
+
+func (ports SwitchPorts) hasSwitchNeighbor(portName string) bool {
+	for _, neighbor := range ports.Neighbors {
+		if neighbor.Port == portName && neighbor.IsSwitch() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Classify works out what a port is cabled to. A port is an inter-switch port when a switch is heard on it with
+// LLDP, or on any member of it when it is a link aggregation. Link aggregations with known members are otherwise edge
+// ports, as nodes bonded to an MLAG pair are. Failing that, for switches that don't run LLDP, the profile's uplinks
+// are taken to be inter-switch ports.
+func (ports SwitchPorts) Classify(profile snmp_utilities.VendorProfile, portName string) snmp_utilities.PortClass {
+	if ports.hasSwitchNeighbor(portName) {
+		return snmp_utilities.PortClassInterSwitch
+	}
+
+	if members := ports.LAGs[portName]; ports.LLDP && len(members) > 0 {
+		for _, member := range members {
+			if ports.hasSwitchNeighbor(member) {
+				return snmp_utilities.PortClassInterSwitch
+			}
+		}
+
+		return snmp_utilities.PortClassEdge
+	}
+
+	if profile.IsUplink(portName) {
+		return snmp_utilities.PortClassInterSwitch
+	}
+
+	return snmp_utilities.PortClassEdge
+}
+
+// GetMACPortMap walks a management switch for the MAC addresses it has learned, returning a map of MAC address
+// without punctuation to the name of the port it was learned on. The profile says which FDB tables to walk and how to
+// name the ports. Ports are classified with the LLDP neighbors and link aggregations of the switch, MAC addresses
+// learned on inter-switch ports are left out as they belong to devices cabled to other switches. What was found out about the ports of the
+// switch along the way is returned too.
+func (discovery *Discovery) GetMACPortMap(snmpInterface snmp_utilities.SNMPInterface,
+	profile snmp_utilities.VendorProfile) (macPortMap map[string]string, ports SwitchPorts, err error) {
+	logger := discovery.logger()
+
+	// Get a mapping of interface indexes to names.
+	portMap, err := snmpInterface.GetPortMap()
+	if err != nil {
+		err = fmt.Errorf("failed to get port map: %w", err)
+		return
+	}
+
+	logger.Debug("Got port map from switch.", zap.Any("portMap", portMap))
+
+	for _, portName := range portMap {
+		ports.Names = append(ports.Names, profile.NormalizePortName(portName))
+	}
+	sort.Strings(ports.Names)
+
+	// Switches that don't run LLDP just don't have any neighbors, so the uplinks of the profile are all there is to
+	// go on for them.
+	neighbors, lldpErr := snmpInterface.GetLLDPNeighbors(portMap)
+	if lldpErr != nil {
+		logger.Warn("Unable to get LLDP neighbors of switch, only leaving out MAC addresses on known uplinks.",
+			zap.String("profile", profile.Name), zap.Error(lldpErr))
+	}
+	// A switch that hears nobody at all most likely doesn't have LLDP turned on.
+	ports.LLDP = lldpErr == nil && len(neighbors) > 0
+	for _, neighbor := range neighbors {
+		neighbor.Port = profile.NormalizePortName(neighbor.Port)
+		ports.Neighbors = append(ports.Neighbors, neighbor)
+	}
+
+	logger.Debug("Got LLDP neighbors from switch.", zap.Any("neighbors", ports.Neighbors))
+
+	// Without them MAC addresses learned on a LAG can't be tied to the physical port SLS has a connector for, but
+	// the rest still can be.
+	lagMembers, lagErr := snmpInterface.GetLAGMembers(portMap)
+	if lagErr != nil {
+		logger.Warn("Unable to get LAG members of switch, MAC addresses learned on LAGs will not be resolved.",
+			zap.String("profile", profile.Name), zap.Error(lagErr))
+	}
+	ports.LAGs = map[string][]string{}
+	for lagName, memberNames := range lagMembers {
+		lagName = profile.NormalizePortName(lagName)
+		for _, memberName := range memberNames {
+			ports.LAGs[lagName] = append(ports.LAGs[lagName], profile.NormalizePortName(memberName))
+		}
+	}
+
+	logger.Debug("Got LAG members from switch.", zap.Any("lags", ports.LAGs))
+
+	// Next get a mapping of interface indexes to numbers.
+	portNumberMap, err := snmpInterface.GetPortNumberMap()
+	if err != nil {
+		err = fmt.Errorf("failed to get port number map: %w", err)
+		return
+	}
+
+	logger.Debug("Got port number map from switch.", zap.Any("portNumberMap", portNumberMap))
+
+	// Reverse the keys and values
+	portNumberIfIndexMap := make(map[int]int)
+	for key, val := range portNumberMap {
+		portNumberIfIndexMap[val] = key
+	}
+
+	// Now get the MAC addresses for all the ports on this switch.
+	macPortMap, err = snmpInterface.GetMACPortNameTable(portNumberIfIndexMap, portMap, profile.FDBTables)
+	if err != nil {
+		err = fmt.Errorf("unable to get MAC to port mapping: %w", err)
+		return
+	}
+
+	for mac, portName := range macPortMap {
+		portName = profile.NormalizePortName(portName)
+		if ports.Classify(profile, portName) == snmp_utilities.PortClassInterSwitch {
+			delete(macPortMap, mac)
+			ports.UplinkMACs++
+			continue
+		}
+
+		macPortMap[mac] = portName
+	}
+
+	logger.Debug("Got MAC port map from switch.", zap.Any("macPortMap", macPortMap),
+		zap.Int("uplinkMACs", ports.UplinkMACs))
+
+	return
+}
+
+// ConnectorMatch is a River switch connector in SLS and the port of the switch it matched, Port is empty when none
+// did.
+type ConnectorMatch struct {
+	Xname      string
+	VendorName string
+	Port       string
+}
+
+// MatchSwitchPorts pairs the switch connectors SLS has for a management switch with the ports the switch has,
+// comparing the canonical form of the names so that formatting differences between the two don't matter. It returns
+// the SLS VendorName of each port that matched a connector, along with every connector and the port it matched.
+func (discovery *Discovery) MatchSwitchPorts(ctx context.Context, managementSwitchXname string,
+	profile snmp_utilities.VendorProfile, portNames []string) (vendorNames map[string]string,
+	matches []ConnectorMatch, err error) {
+	snapshot, err := discovery.SLS.Snapshot(ctx)
+	if err != nil {
+		return
+	}
+
+	portsByCanonicalName := map[string]string{}
+	for _, portName := range portNames {
+		canonicalName := profile.CanonicalPortName(portName)
+		if _, found := portsByCanonicalName[canonicalName]; !found {
+			portsByCanonicalName[canonicalName] = portName
+		}
+	}
+
+	vendorNames = map[string]string{}
+	switchConnectors := snapshot.Search(sls.Query{
+		Type:   sls_common.MgmtSwitchConnector,
+		Parent: managementSwitchXname,
+	})
+	for _, switchConnector := range switchConnectors {
+		var switchConnectorProperties sls_common.ComptypeMgmtSwitchConnector
+		decodeErr := sls.DecodeExtraProperties(switchConnector, &switchConnectorProperties)
+		if decodeErr != nil {
+			discovery.logger().Warn("Unable to decode switch connector properties!", zap.Error(decodeErr))
+			continue
+		}
+
+		match := ConnectorMatch{
+			Xname:      switchConnector.Xname,
+			VendorName: switchConnectorProperties.VendorName,
+		}
+		canonicalName := profile.CanonicalPortName(switchConnectorProperties.VendorName)
+		if portName, found := portsByCanonicalName[canonicalName]; found {
+			match.Port = portName
+			vendorNames[portName] = switchConnectorProperties.VendorName
+		}
+
+		matches = append(matches, match)
+	}
+
+	return
+}
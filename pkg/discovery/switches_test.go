@@ -0,0 +1,231 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package discovery
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	compcredentials "github.com/Cray-HPE/hms-compcredentials"
+	"github.com/Cray-HPE/hms-discovery/pkg/sls"
+	"github.com/Cray-HPE/hms-discovery/pkg/switches"
+	sls_common "github.com/Cray-HPE/hms-sls/v2/pkg/sls-common"
+)
+
+// testCredentials stands in for Vault, holding credentials and communities for individual switches along with the
+// defaults.
+type testCredentials struct {
+	compCreds   map[string]compcredentials.CompCredentials
+	communities map[string]switches.SwitchCommunity
+	defaults    switches.SwitchCredentials
+}
+
+func (credentials testCredentials) GetCompCred(xname string) (compcredentials.CompCredentials, error) {
+	return credentials.compCreds[xname], nil
+}
+
+func (credentials testCredentials) GetSwitchCommunity(xname string) (switches.SwitchCommunity, error) {
+	return credentials.communities[xname], nil
+}
+
+func (credentials testCredentials) GetDefaultCredentials() (map[string]switches.RedsCredentials, error) {
+	return nil, nil
+}
+
+func (credentials testCredentials) GetDefaultSwitchCredentials() (switches.SwitchCredentials, error) {
+	return credentials.defaults, nil
+}
+
+var testSwitchHardware = []sls_common.GenericHardware{
+	{
+		Xname: "x3000c0w14", Parent: "x3000c0", Type: sls_common.MgmtSwitch, Class: sls_common.ClassRiver,
+		ExtraPropertiesRaw: map[string]interface{}{
+			"IP4addr": "10.254.0.2", "Brand": "Dell", "SNMPUsername": "sls-user",
+			"SNMPAuthPassword": "vault://hms-creds/x3000c0w14", "SNMPPrivPassword": "sls-priv",
+		},
+	},
+	{
+		Xname: "x3000c0w15", Parent: "x3000c0", Type: sls_common.MgmtSwitch, Class: sls_common.ClassRiver,
+		ExtraPropertiesRaw: map[string]interface{}{
+			"IP4addr": "10.254.0.3", "Brand": "Aruba", "SNMPVersion": "v2c", "SNMPCommunity": "sls-community",
+		},
+	},
+	{
+		Xname: "x3000c0w16", Parent: "x3000c0", Type: sls_common.MgmtSwitch, Class: sls_common.ClassRiver,
+		ExtraPropertiesRaw: map[string]interface{}{"IP4addr": "10.254.0.4", "SNMPVersion": "v1"},
+	},
+	{
+		Xname: "x3000c0w14j10", Parent: "x3000c0w14", Type: sls_common.MgmtSwitchConnector,
+		Class: sls_common.ClassRiver,
+		ExtraPropertiesRaw: map[string]interface{}{
+			"NodeNics": []interface{}{"x3000c0s9b0"}, "VendorName": "ethernet1/1/10",
+		},
+	},
+	{
+		Xname: "x3000c0w14j9", Parent: "x3000c0w14", Type: sls_common.MgmtSwitchConnector,
+		Class: sls_common.ClassRiver,
+		ExtraPropertiesRaw: map[string]interface{}{
+			"NodeNics": []interface{}{"x3000c0s7b0", "x3000c0s8b0"}, "VendorName": "ethernet1/1/9",
+		},
+	},
+}
+
+func newTestDiscovery(credentials testCredentials) *Discovery {
+	slsClient := sls.NewClient("", nil)
+	slsClient.SetSnapshot(sls.NewSnapshot(testSwitchHardware))
+
+	return &Discovery{
+		SLS:                slsClient,
+		Credentials:        credentials,
+		DefaultCredentials: credentials,
+		SwitchCommunities:  credentials,
+	}
+}
+
+func TestGetSwitches(t *testing.T) {
+	defaults := switches.SwitchCredentials{
+		SNMPUsername:     "default-user",
+		SNMPAuthPassword: "default-auth",
+		SNMPPrivPassword: "default-priv",
+		SNMPCommunity:    "default-community",
+	}
+
+	tests := []struct {
+		name        string
+		credentials testCredentials
+		xname       string
+		want        switches.ManagementSwitch
+	}{
+		{
+			name:        "SNMPv3 from SLS and the defaults",
+			credentials: testCredentials{defaults: defaults},
+			xname:       "x3000c0w14",
+			want: switches.ManagementSwitch{
+				Xname: "x3000c0w14", Address: "10.254.0.2", Brand: "Dell", SNMPVersion: switches.SNMPVersion3,
+				SNMPUser: "sls-user", SNMPAuthPassword: "default-auth", SNMPPrivPassword: "sls-priv",
+				SNMPCommunity: "default-community",
+			},
+		},
+		{
+			name: "SNMPv3 from Vault",
+			credentials: testCredentials{
+				compCreds: map[string]compcredentials.CompCredentials{
+					"x3000c0w14": {Xname: "x3000c0w14", Username: "vault-user", SNMPAuthPass: "vault-auth",
+						SNMPPrivPass: "vault-priv"},
+				},
+				defaults: defaults,
+			},
+			xname: "x3000c0w14",
+			want: switches.ManagementSwitch{
+				Xname: "x3000c0w14", Address: "10.254.0.2", Brand: "Dell", SNMPVersion: switches.SNMPVersion3,
+				SNMPUser: "sls-user", SNMPAuthPassword: "vault-auth", SNMPPrivPassword: "vault-priv",
+				SNMPCommunity: "default-community",
+			},
+		},
+		{
+			name:        "SNMPv2c community from SLS",
+			credentials: testCredentials{defaults: defaults},
+			xname:       "x3000c0w15",
+			want: switches.ManagementSwitch{
+				Xname: "x3000c0w15", Address: "10.254.0.3", Brand: "Aruba", SNMPVersion: switches.SNMPVersion2c,
+				SNMPAuthPassword: "default-auth", SNMPPrivPassword: "default-priv",
+				SNMPCommunity: "sls-community",
+			},
+		},
+		{
+			name: "SNMPv2c community from Vault",
+			credentials: testCredentials{
+				communities: map[string]switches.SwitchCommunity{
+					"x3000c0w15": {Xname: "x3000c0w15", SNMPCommunity: "vault-community"},
+				},
+				// A password of the switch's own is never used as its community.
+				compCreds: map[string]compcredentials.CompCredentials{
+					"x3000c0w15": {Xname: "x3000c0w15", Password: "password"},
+				},
+				defaults: defaults,
+			},
+			xname: "x3000c0w15",
+			want: switches.ManagementSwitch{
+				Xname: "x3000c0w15", Address: "10.254.0.3", Brand: "Aruba", SNMPVersion: switches.SNMPVersion2c,
+				SNMPAuthPassword: "default-auth", SNMPPrivPassword: "default-priv",
+				SNMPCommunity: "vault-community",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			discovery := newTestDiscovery(test.credentials)
+
+			managementSwitches, invalidSwitches, err := discovery.GetSwitches(context.Background(),
+				sls.Query{Type: sls_common.MgmtSwitch})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(invalidSwitches) != 1 || invalidSwitches[0].Xname != "x3000c0w16" {
+				t.Errorf("GetSwitches() invalid switches = %v, want only x3000c0w16", invalidSwitches)
+			}
+
+			for _, managementSwitch := range managementSwitches {
+				if managementSwitch.Xname != test.xname {
+					continue
+				}
+
+				if !reflect.DeepEqual(managementSwitch, test.want) {
+					t.Errorf("GetSwitches() %s = %+v, want %+v", test.xname, managementSwitch, test.want)
+				}
+				return
+			}
+			t.Errorf("GetSwitches() didn't return %s", test.xname)
+		})
+	}
+}
+
+func TestGetXnameForSwitchPort(t *testing.T) {
+	discovery := newTestDiscovery(testCredentials{})
+
+	tests := []struct {
+		name      string
+		port      string
+		wantXname string
+		wantErr   bool
+	}{
+		{name: "single NodeNic", port: "ethernet1/1/10", wantXname: "x3000c0s9b0"},
+		{name: "more than one NodeNic", port: "ethernet1/1/9", wantErr: true},
+		{name: "not in SLS", port: "ethernet1/1/11", wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			xname, err := discovery.GetXnameForSwitchPort(context.Background(), "x3000c0w14", test.port)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("GetXnameForSwitchPort(%q) error = %v, want error %v", test.port, err, test.wantErr)
+			}
+			if xname != test.wantXname {
+				t.Errorf("GetXnameForSwitchPort(%q) = %q, want %q", test.port, xname, test.wantXname)
+			}
+		})
+	}
+}
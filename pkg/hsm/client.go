@@ -0,0 +1,172 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+// Package hsm is a client for the parts of the Hardware State Manager v2 API that discovery uses. Every failed request
+// returns an error that can be checked with errors.Is against ErrNotFound, ErrConflict and ErrUnavailable, and every
+// request carries a request ID so it can be found in the HSM logs.
+package hsm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/url"
+	"slices"
+	"strings"
+	"sync/atomic"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+	"github.com/Cray-HPE/hms-xname/xnametypes"
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+// RequestIDHeader is the header each request's ID is sent in.
+const RequestIDHeader = "X-Request-ID"
+
+type requestIDKey struct{}
+
+// WithRequestID makes every request made with the returned context use requestID as the start of its request ID,
+// such as the ID of the discovery run the request is part of.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// Client talks to the HSM v2 API. It is safe to use from multiple goroutines.
+type Client struct {
+	baseURL    string
+	httpClient *retryablehttp.Client
+
+	requestPrefix string
+	requestCount  atomic.Uint64
+}
+
+// NewClient makes a client for the HSM at baseURL, such as http://cray-smd. A trailing /hsm/v2 is dropped as the
+// client adds it to every request itself.
+func NewClient(baseURL string, httpClient *retryablehttp.Client) *Client {
+	baseURL = strings.TrimSuffix(strings.TrimSuffix(baseURL, "/"), "/hsm/v2")
+
+	return &Client{
+		baseURL:       baseURL + "/hsm/v2",
+		httpClient:    httpClient,
+		requestPrefix: fmt.Sprintf("%08x", rand.Uint32()),
+	}
+}
+
+// BaseURL is the URL of the HSM v2 API, every request is made to a path under it.
+func (client *Client) BaseURL() string {
+	return client.baseURL
+}
+
+func (client *Client) requestID(ctx context.Context) string {
+	prefix, _ := ctx.Value(requestIDKey{}).(string)
+	if prefix == "" {
+		prefix = client.requestPrefix
+	}
+
+	return fmt.Sprintf("%s-%d", prefix, client.requestCount.Add(1))
+}
+
+// do makes a request to HSM, decoding the response into result when it isn't nil. Any status code other than those
+// expected is an *Error.
+func (client *Client) do(ctx context.Context, method, path string, query url.Values, payload interface{},
+	result interface{}, expectedStatusCodes ...int) error {
+	requestURL := fmt.Sprintf("%s/%s", client.baseURL, path)
+	if len(query) > 0 {
+		requestURL = fmt.Sprintf("%s?%s", requestURL, query.Encode())
+	}
+
+	var body io.Reader
+	if payload != nil {
+		payloadBytes, err := json.Marshal(payload)
+		if err != nil {
+			return errors.Join(fmt.Errorf("failed to marshal %s request body", method), err)
+		}
+		body = bytes.NewReader(payloadBytes)
+	}
+
+	req, err := retryablehttp.NewRequestWithContext(ctx, method, requestURL, body)
+	if err != nil {
+		return errors.Join(fmt.Errorf("failed to build %s request", method), err)
+	}
+
+	requestID := client.requestID(ctx)
+	req.Header.Set(RequestIDHeader, requestID)
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	response, err := client.httpClient.Do(req)
+	defer base.DrainAndCloseResponseBody(response)
+	if err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("failed to perform %s request against HSM (request ID %s): %w", method, requestID, err)
+		}
+
+		return fmt.Errorf("%w: failed to perform %s request against HSM (request ID %s): %w",
+			ErrUnavailable, method, requestID, err)
+	}
+
+	if !slices.Contains(expectedStatusCodes, response.StatusCode) {
+		// Read whatever HSM sent back so the Istio sidecar doesn't fill up, and so it can be reported.
+		responseBody, _ := io.ReadAll(response.Body)
+
+		return &Error{
+			Method:     method,
+			URL:        requestURL,
+			RequestID:  requestID,
+			StatusCode: response.StatusCode,
+			Body:       strings.TrimSpace(string(responseBody)),
+		}
+	}
+
+	if result == nil {
+		return nil
+	}
+
+	if err := json.NewDecoder(response.Body).Decode(result); err != nil {
+		return errors.Join(fmt.Errorf("failed to decode response from HSM (request ID %s)", requestID), err)
+	}
+
+	return nil
+}
+
+func validateXname(xname string) error {
+	if !xnametypes.IsHMSCompIDValid(xname) {
+		return fmt.Errorf("invalid component ID (%s)", xname)
+	}
+
+	return nil
+}
+
+// Convert query parameters to url.Values, which takes care of escaping them.
+func queryValues(params map[string]string) url.Values {
+	query := url.Values{}
+	for key, value := range params {
+		query.Set(key, value)
+	}
+
+	return query
+}
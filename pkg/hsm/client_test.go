@@ -0,0 +1,46 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package hsm
+
+import "testing"
+
+func TestNewClientBaseURL(t *testing.T) {
+	tests := []struct {
+		baseURL string
+		want    string
+	}{
+		{"http://cray-smd", "http://cray-smd/hsm/v2"},
+		{"http://cray-smd/", "http://cray-smd/hsm/v2"},
+		{"http://cray-smd/hsm/v2", "http://cray-smd/hsm/v2"},
+		{"http://cray-smd/hsm/v2/", "http://cray-smd/hsm/v2"},
+		{"http://hsm.example", "http://hsm.example/hsm/v2"},
+		{"https://hsm.example:8443/hsm/v2", "https://hsm.example:8443/hsm/v2"},
+		{"http://api-gw/apis/smd", "http://api-gw/apis/smd/hsm/v2"},
+	}
+
+	for _, test := range tests {
+		if got := NewClient(test.baseURL, nil).BaseURL(); got != test.want {
+			t.Errorf("NewClient(%q).BaseURL() = %q, want %q", test.baseURL, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package hsm
+
+import (
+	"context"
+	"net/http"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+)
+
+// GetComponents returns the components in State/Components matching params, such as Type and Role.
+func (client *Client) GetComponents(ctx context.Context, params map[string]string) ([]base.Component, error) {
+	var result base.ComponentArray
+	err := client.do(ctx, http.MethodGet, "State/Components", queryValues(params), nil, &result, http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+
+	components := make([]base.Component, 0, len(result.Components))
+	for _, component := range result.Components {
+		components = append(components, *component)
+	}
+
+	return components, nil
+}
+
+// GetComponent returns the component with the given xname.
+func (client *Client) GetComponent(ctx context.Context, xname string) (base.Component, error) {
+	if err := validateXname(xname); err != nil {
+		return base.Component{}, err
+	}
+
+	var component base.Component
+	err := client.do(ctx, http.MethodGet, "State/Components/"+xname, nil, nil, &component, http.StatusOK)
+	if err != nil {
+		return base.Component{}, err
+	}
+
+	return component, nil
+}
+
+// CreateComponents creates or replaces all of the given components in a single request.
+func (client *Client) CreateComponents(ctx context.Context, components []base.Component) error {
+	payload := base.ComponentArray{}
+	for i := range components {
+		if err := validateXname(components[i].ID); err != nil {
+			return err
+		}
+
+		payload.Components = append(payload.Components, &components[i])
+	}
+
+	return client.do(ctx, http.MethodPost, "State/Components", nil, payload, nil, http.StatusNoContent)
+}
+
+// CreateComponent creates or replaces a single component.
+func (client *Client) CreateComponent(ctx context.Context, component base.Component) error {
+	return client.CreateComponents(ctx, []base.Component{component})
+}
+
+// DeleteComponent removes a component, returning ErrNotFound if it isn't there.
+func (client *Client) DeleteComponent(ctx context.Context, xname string) error {
+	if err := validateXname(xname); err != nil {
+		return err
+	}
+
+	return client.do(ctx, http.MethodDelete, "State/Components/"+xname, nil, nil, nil, http.StatusOK)
+}
@@ -0,0 +1,76 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package hsm
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Errors every failed request can be checked against with errors.Is.
+var (
+	// ErrNotFound is returned when the thing asked for isn't in HSM.
+	ErrNotFound = errors.New("not found in HSM")
+	// ErrConflict is returned when creating something that is already in HSM.
+	ErrConflict = errors.New("already exists in HSM")
+	// ErrUnavailable is returned when HSM couldn't be reached or said it can't handle requests right now, so trying
+	// again later may work.
+	ErrUnavailable = errors.New("HSM unavailable")
+)
+
+// Error is an unexpected response from HSM.
+type Error struct {
+	Method     string
+	URL        string
+	RequestID  string
+	StatusCode int
+	// Body is what HSM sent back, usually an RFC 7807 problem description.
+	Body string
+}
+
+func (err *Error) Error() string {
+	message := fmt.Sprintf("unexpected status code %d from HSM for %s %s (request ID %s)",
+		err.StatusCode, err.Method, err.URL, err.RequestID)
+	if err.Body != "" {
+		message = fmt.Sprintf("%s: %s", message, err.Body)
+	}
+
+	return message
+}
+
+// Is makes the error match ErrNotFound, ErrConflict or ErrUnavailable depending on the status code.
+func (err *Error) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return err.StatusCode == http.StatusNotFound
+	case ErrConflict:
+		return err.StatusCode == http.StatusConflict
+	case ErrUnavailable:
+		return err.StatusCode == http.StatusBadGateway ||
+			err.StatusCode == http.StatusServiceUnavailable ||
+			err.StatusCode == http.StatusGatewayTimeout
+	default:
+		return false
+	}
+}
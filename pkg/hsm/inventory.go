@@ -0,0 +1,209 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package hsm
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	rf "github.com/Cray-HPE/hms-smd/v2/pkg/redfish"
+	"github.com/Cray-HPE/hms-smd/v2/pkg/sm"
+)
+
+//
+// RedfishEndpoints
+//
+
+type redfishEndpointArray struct {
+	RedfishEndpoints []rf.RedfishEPDescription `json:"RedfishEndpoints"`
+}
+
+// GetRedfishEndpoints returns the RedfishEndpoints matching params, such as type or laststatus.
+func (client *Client) GetRedfishEndpoints(ctx context.Context, params map[string]string) ([]rf.RedfishEPDescription,
+	error) {
+	var result redfishEndpointArray
+	err := client.do(ctx, http.MethodGet, "Inventory/RedfishEndpoints", queryValues(params), nil, &result,
+		http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.RedfishEndpoints, nil
+}
+
+// GetRedfishEndpoint returns the RedfishEndpoint with the given xname.
+func (client *Client) GetRedfishEndpoint(ctx context.Context, xname string) (rf.RedfishEPDescription, error) {
+	if err := validateXname(xname); err != nil {
+		return rf.RedfishEPDescription{}, err
+	}
+
+	var endpoint rf.RedfishEPDescription
+	err := client.do(ctx, http.MethodGet, "Inventory/RedfishEndpoints/"+xname, nil, nil, &endpoint, http.StatusOK)
+	if err != nil {
+		return rf.RedfishEPDescription{}, err
+	}
+
+	return endpoint, nil
+}
+
+// CreateRedfishEndpoints creates all of the given RedfishEndpoints in a single request, returning ErrConflict if any of
+// them already exist.
+func (client *Client) CreateRedfishEndpoints(ctx context.Context, endpoints []rf.RedfishEPDescription) error {
+	for _, endpoint := range endpoints {
+		if err := validateXname(endpoint.ID); err != nil {
+			return err
+		}
+	}
+
+	return client.do(ctx, http.MethodPost, "Inventory/RedfishEndpoints", nil,
+		redfishEndpointArray{RedfishEndpoints: endpoints}, nil, http.StatusCreated)
+}
+
+// UpdateRedfishEndpoint updates a RedfishEndpoint that is already in HSM.
+func (client *Client) UpdateRedfishEndpoint(ctx context.Context, endpoint rf.RedfishEPDescription) error {
+	if err := validateXname(endpoint.ID); err != nil {
+		return err
+	}
+
+	return client.do(ctx, http.MethodPatch, "Inventory/RedfishEndpoints/"+endpoint.ID, nil, endpoint, nil,
+		http.StatusOK)
+}
+
+// PatchRedfishEndpoint changes only the fields set in patch of a RedfishEndpoint that is already in HSM, leaving the
+// rest, such as its credentials, alone.
+func (client *Client) PatchRedfishEndpoint(ctx context.Context, xname string, patch sm.RedfishEndpointPatch) error {
+	if err := validateXname(xname); err != nil {
+		return err
+	}
+
+	return client.do(ctx, http.MethodPatch, "Inventory/RedfishEndpoints/"+xname, nil, patch, nil, http.StatusOK)
+}
+
+// UpsertRedfishEndpoint creates a RedfishEndpoint, or updates it if it is already there.
+func (client *Client) UpsertRedfishEndpoint(ctx context.Context, endpoint rf.RedfishEPDescription) error {
+	err := client.CreateRedfishEndpoints(ctx, []rf.RedfishEPDescription{endpoint})
+	if errors.Is(err, ErrConflict) {
+		return client.UpdateRedfishEndpoint(ctx, endpoint)
+	}
+
+	return err
+}
+
+// DeleteRedfishEndpoint removes a RedfishEndpoint, returning ErrNotFound if it isn't there.
+func (client *Client) DeleteRedfishEndpoint(ctx context.Context, xname string) error {
+	if err := validateXname(xname); err != nil {
+		return err
+	}
+
+	return client.do(ctx, http.MethodDelete, "Inventory/RedfishEndpoints/"+xname, nil, nil, nil, http.StatusOK)
+}
+
+// Discover asks HSM to discover the given RedfishEndpoints again.
+func (client *Client) Discover(ctx context.Context, xnames []string, force bool) error {
+	discover := sm.DiscoverIn{
+		XNames: xnames,
+		Force:  force,
+	}
+
+	return client.do(ctx, http.MethodPost, "Inventory/Discover", nil, discover, nil, http.StatusOK)
+}
+
+//
+// EthernetInterfaces
+//
+
+// GetEthernetInterfaces returns the EthernetInterfaces matching params, such as ComponentID or MACAddress.
+func (client *Client) GetEthernetInterfaces(ctx context.Context, params map[string]string) ([]sm.CompEthInterfaceV2,
+	error) {
+	var ethernetInterfaces []sm.CompEthInterfaceV2
+	err := client.do(ctx, http.MethodGet, "Inventory/EthernetInterfaces", queryValues(params), nil,
+		&ethernetInterfaces, http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+
+	return ethernetInterfaces, nil
+}
+
+// GetUnknownEthernetInterfaces returns the EthernetInterfaces that haven't been matched up with a component yet.
+func (client *Client) GetUnknownEthernetInterfaces(ctx context.Context) ([]sm.CompEthInterfaceV2, error) {
+	var ethernetInterfaces []sm.CompEthInterfaceV2
+	// An empty ComponentID finds the interfaces without one.
+	err := client.do(ctx, http.MethodGet, "Inventory/EthernetInterfaces", url.Values{"ComponentID": {""}}, nil,
+		&ethernetInterfaces, http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+
+	return ethernetInterfaces, nil
+}
+
+// ethernetInterfaceID is the ID HSM gives the EthernetInterface with a MAC address.
+func ethernetInterfaceID(macAddr string) string {
+	return strings.ToLower(strings.ReplaceAll(macAddr, ":", ""))
+}
+
+// GetEthernetInterface returns the EthernetInterface with the given MAC address.
+func (client *Client) GetEthernetInterface(ctx context.Context, macAddr string) (sm.CompEthInterfaceV2, error) {
+	var ethernetInterface sm.CompEthInterfaceV2
+	err := client.do(ctx, http.MethodGet, "Inventory/EthernetInterfaces/"+url.PathEscape(ethernetInterfaceID(macAddr)),
+		nil, nil, &ethernetInterface, http.StatusOK)
+	if err != nil {
+		return sm.CompEthInterfaceV2{}, err
+	}
+
+	return ethernetInterface, nil
+}
+
+// CreateEthernetInterface adds an EthernetInterface, returning ErrConflict if one with the same MAC address is
+// already there.
+func (client *Client) CreateEthernetInterface(ctx context.Context, ethernetInterface sm.CompEthInterfaceV2) error {
+	return client.do(ctx, http.MethodPost, "Inventory/EthernetInterfaces", nil, ethernetInterface, nil,
+		http.StatusCreated)
+}
+
+// UpdateEthernetInterface updates the EthernetInterface with the same MAC address.
+func (client *Client) UpdateEthernetInterface(ctx context.Context, ethernetInterface sm.CompEthInterfaceV2) error {
+	return client.do(ctx, http.MethodPatch,
+		"Inventory/EthernetInterfaces/"+url.PathEscape(ethernetInterfaceID(ethernetInterface.MACAddr)), nil,
+		ethernetInterface, nil, http.StatusOK)
+}
+
+// DeleteEthernetInterface removes the EthernetInterface with the given MAC address.
+func (client *Client) DeleteEthernetInterface(ctx context.Context, macAddr string) error {
+	return client.do(ctx, http.MethodDelete,
+		"Inventory/EthernetInterfaces/"+url.PathEscape(ethernetInterfaceID(macAddr)), nil, nil, nil, http.StatusOK)
+}
+
+// UpsertEthernetInterface adds an EthernetInterface, or updates it if one with the same MAC address is already there.
+func (client *Client) UpsertEthernetInterface(ctx context.Context, ethernetInterface sm.CompEthInterfaceV2) error {
+	err := client.CreateEthernetInterface(ctx, ethernetInterface)
+	if errors.Is(err, ErrConflict) {
+		return client.UpdateEthernetInterface(ctx, ethernetInterface)
+	}
+
+	return err
+}
@@ -0,0 +1,242 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package journal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	securestorage "github.com/Cray-HPE/hms-securestorage"
+)
+
+/*
+The journal is kept in Vault rather than on disk as discovery normally runs in a pod with nothing persistent mounted,
+and because the prior values of credentials are secrets themselves. Each entry is its own key under the run it
+belongs to so a large run never runs into the size limit of a single secret:
+
+	<keyPath>/<runID>/<sequence>
+*/
+
+// Entry is a single write made by discovery along with what was there before it. A nil Before means nothing was
+// there.
+type Entry struct {
+	RunID     string          `json:"RunID"`
+	Sequence  int             `json:"Sequence"`
+	Time      time.Time       `json:"Time"`
+	Phase     string          `json:"Phase"`
+	Operation string          `json:"Operation"`
+	Xname     string          `json:"Xname"`
+	Before    json.RawMessage `json:"Before,omitempty"`
+	After     json.RawMessage `json:"After"`
+}
+
+// record is how an Entry is kept in Vault. The secure storage adapter doesn't know about JSON, so the values are
+// stored as JSON text.
+type record struct {
+	RunID     string
+	Sequence  int
+	Time      string
+	Phase     string
+	Operation string
+	Xname     string
+	Before    string
+	After     string
+}
+
+type Journal struct {
+	SS      securestorage.SecureStorage
+	KeyPath string
+
+	lock      sync.Mutex
+	sequences map[string]int
+}
+
+func NewJournal(keyPath string, ss securestorage.SecureStorage) *Journal {
+	return &Journal{
+		SS:        ss,
+		KeyPath:   keyPath,
+		sequences: map[string]int{},
+	}
+}
+
+func (journal *Journal) key(runID string, sequence int) string {
+	return fmt.Sprintf("%s/%s/%06d", journal.KeyPath, runID, sequence)
+}
+
+// Append records a write that is about to be made. The entry should be discarded if the write then fails.
+func (journal *Journal) Append(runID, phase, operation, xname string, before, after interface{}) (Entry, error) {
+	entry := Entry{
+		RunID:     runID,
+		Time:      time.Now().UTC(),
+		Phase:     phase,
+		Operation: operation,
+		Xname:     xname,
+	}
+
+	var err error
+	if before != nil {
+		if entry.Before, err = json.Marshal(before); err != nil {
+			return entry, fmt.Errorf("failed to marshal prior value: %w", err)
+		}
+	}
+	if entry.After, err = json.Marshal(after); err != nil {
+		return entry, fmt.Errorf("failed to marshal new value: %w", err)
+	}
+
+	journal.lock.Lock()
+	journal.sequences[runID]++
+	entry.Sequence = journal.sequences[runID]
+	journal.lock.Unlock()
+
+	err = journal.SS.Store(journal.key(runID, entry.Sequence), record{
+		RunID:     entry.RunID,
+		Sequence:  entry.Sequence,
+		Time:      entry.Time.Format(time.RFC3339Nano),
+		Phase:     entry.Phase,
+		Operation: entry.Operation,
+		Xname:     entry.Xname,
+		Before:    string(entry.Before),
+		After:     string(entry.After),
+	})
+
+	return entry, err
+}
+
+// Discard removes an entry for a write that was never made.
+func (journal *Journal) Discard(entry Entry) error {
+	return journal.SS.Delete(journal.key(entry.RunID, entry.Sequence))
+}
+
+// lookupKeys lists the keys under keyPath. The Vault adapter panics rather than returning an error when there is
+// nothing at all under a path, which is treated here as there being no keys.
+func (journal *Journal) lookupKeys(keyPath string) (keys []string, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			keys, err = nil, nil
+		}
+	}()
+
+	return journal.SS.LookupKeys(keyPath)
+}
+
+// Prune removes every run whose last entry was written more than maxAge before now, returning the IDs of the runs
+// that were removed. A run that can't be read is left alone so the rest can still be pruned.
+func (journal *Journal) Prune(maxAge time.Duration, now time.Time) ([]string, error) {
+	runKeys, err := journal.lookupKeys(journal.KeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list journaled runs: %w", err)
+	}
+
+	var (
+		pruned []string
+		errs   []error
+	)
+	for _, runKey := range runKeys {
+		runID := strings.TrimSuffix(runKey, "/")
+
+		keys, err := journal.lookupKeys(journal.KeyPath + "/" + runID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to list journal entries for run %s: %w", runID, err))
+			continue
+		}
+		if len(keys) == 0 {
+			continue
+		}
+
+		// Sequences are zero padded, so the last key is the newest entry.
+		sort.Strings(keys)
+		var newest record
+		if err := journal.SS.Lookup(journal.KeyPath+"/"+runID+"/"+keys[len(keys)-1], &newest); err != nil {
+			errs = append(errs, fmt.Errorf("failed to read newest journal entry of run %s: %w", runID, err))
+			continue
+		}
+		written, err := time.Parse(time.RFC3339Nano, newest.Time)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to parse time of newest journal entry of run %s: %w", runID, err))
+			continue
+		}
+		if now.Sub(written) <= maxAge {
+			continue
+		}
+
+		var deleteErrs []error
+		for _, key := range keys {
+			if err := journal.SS.Delete(journal.KeyPath + "/" + runID + "/" + key); err != nil {
+				deleteErrs = append(deleteErrs, err)
+			}
+		}
+		if len(deleteErrs) != 0 {
+			errs = append(errs, fmt.Errorf("failed to remove journal entries of run %s: %w", runID,
+				errors.Join(deleteErrs...)))
+			continue
+		}
+
+		pruned = append(pruned, runID)
+	}
+
+	return pruned, errors.Join(errs...)
+}
+
+// Entries returns every entry for a run in the order the writes were made.
+func (journal *Journal) Entries(runID string) ([]Entry, error) {
+	keys, err := journal.lookupKeys(journal.KeyPath + "/" + runID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list journal entries for run %s: %w", runID, err)
+	}
+
+	var entries []Entry
+	for _, key := range keys {
+		var stored record
+		if err := journal.SS.Lookup(journal.KeyPath+"/"+runID+"/"+key, &stored); err != nil {
+			return nil, fmt.Errorf("failed to read journal entry %s of run %s: %w", key, runID, err)
+		}
+
+		entry := Entry{
+			RunID:     stored.RunID,
+			Sequence:  stored.Sequence,
+			Phase:     stored.Phase,
+			Operation: stored.Operation,
+			Xname:     stored.Xname,
+			After:     json.RawMessage(stored.After),
+		}
+		if stored.Before != "" {
+			entry.Before = json.RawMessage(stored.Before)
+		}
+		if entry.Time, err = time.Parse(time.RFC3339Nano, stored.Time); err != nil {
+			return nil, fmt.Errorf("failed to parse time of journal entry %s of run %s: %w", key, runID, err)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Sequence < entries[j].Sequence
+	})
+
+	return entries, nil
+}
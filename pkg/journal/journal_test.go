@@ -0,0 +1,106 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package journal
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Cray-HPE/hms-discovery/pkg/offline"
+)
+
+// TestPrune journals a few runs at different ages and checks only the ones older than the retention are removed.
+func TestPrune(t *testing.T) {
+	now := time.Date(2026, 1, 31, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		runs       map[string][]time.Duration
+		maxAge     time.Duration
+		wantPruned []string
+		wantKept   []string
+	}{
+		{
+			name:   "nothing journaled",
+			maxAge: time.Hour,
+		},
+		{
+			name: "old and new runs",
+			runs: map[string][]time.Duration{
+				"old":    {72 * time.Hour, 71 * time.Hour},
+				"recent": {2 * time.Hour},
+			},
+			maxAge:     24 * time.Hour,
+			wantPruned: []string{"old"},
+			wantKept:   []string{"recent"},
+		},
+		{
+			name: "run with a recent entry is kept",
+			runs: map[string][]time.Duration{
+				"long": {72 * time.Hour, 48 * time.Hour, time.Hour},
+			},
+			maxAge:   24 * time.Hour,
+			wantKept: []string{"long"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			journal := NewJournal("journal", offline.NewStorage())
+			for runID, ages := range test.runs {
+				for sequence, age := range ages {
+					if err := journal.SS.Store(journal.key(runID, sequence+1), record{
+						RunID:     runID,
+						Sequence:  sequence + 1,
+						Time:      now.Add(-age).Format(time.RFC3339Nano),
+						Operation: "test",
+						After:     "{}",
+					}); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+
+			pruned, err := journal.Prune(test.maxAge, now)
+			if err != nil {
+				t.Fatalf("Prune() error = %v", err)
+			}
+			slices.Sort(pruned)
+			if !slices.Equal(pruned, test.wantPruned) {
+				t.Errorf("Prune() = %v, want %v", pruned, test.wantPruned)
+			}
+
+			for _, runID := range test.wantPruned {
+				if entries, err := journal.Entries(runID); err != nil || len(entries) != 0 {
+					t.Errorf("Entries(%s) = %v, %v, want none", runID, entries, err)
+				}
+			}
+			for _, runID := range test.wantKept {
+				if entries, err := journal.Entries(runID); err != nil || len(entries) != len(test.runs[runID]) {
+					t.Errorf("Entries(%s) = %v, %v, want %d entries", runID, entries, err, len(test.runs[runID]))
+				}
+			}
+		})
+	}
+}
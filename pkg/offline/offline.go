@@ -0,0 +1,87 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+// Package offline lets discovery run against files captured from a system instead of the live services, so problems
+// seen in the field can be reproduced anywhere. SLS and HSM are answered from an SLS dump and an HSM snapshot by
+// Transport, and Vault is replaced by an in memory Storage.
+package offline
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+	sls_common "github.com/Cray-HPE/hms-sls/v2/pkg/sls-common"
+	rf "github.com/Cray-HPE/hms-smd/v2/pkg/redfish"
+	"github.com/Cray-HPE/hms-smd/v2/pkg/sm"
+)
+
+// HSMSnapshot is everything discovery reads from HSM. The fields are named the same as in the HSM API responses so a
+// snapshot can be put together from them:
+//
+//	{
+//	  "Components": [...],         // GET /hsm/v2/State/Components
+//	  "RedfishEndpoints": [...],   // GET /hsm/v2/Inventory/RedfishEndpoints
+//	  "EthernetInterfaces": [...]  // GET /hsm/v2/Inventory/EthernetInterfaces
+//	}
+type HSMSnapshot struct {
+	Components         []base.Component          `json:"Components"`
+	RedfishEndpoints   []rf.RedfishEPDescription `json:"RedfishEndpoints"`
+	EthernetInterfaces []sm.CompEthInterfaceV2   `json:"EthernetInterfaces"`
+}
+
+func readJSONFile(path string, result interface{}) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := json.NewDecoder(file).Decode(result); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// LoadSLS reads an SLS dump, such as the output of GET /v1/dumpstate.
+func LoadSLS(path string) (sls_common.SLSState, error) {
+	var state sls_common.SLSState
+	if err := readJSONFile(path, &state); err != nil {
+		return state, err
+	}
+
+	if len(state.Hardware) == 0 {
+		return state, fmt.Errorf("no hardware in SLS dump %s", path)
+	}
+
+	return state, nil
+}
+
+// LoadHSM reads an HSMSnapshot.
+func LoadHSM(path string) (HSMSnapshot, error) {
+	var snapshot HSMSnapshot
+	err := readJSONFile(path, &snapshot)
+
+	return snapshot, err
+}
@@ -0,0 +1,119 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package offline
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	securestorage "github.com/Cray-HPE/hms-securestorage"
+	"github.com/mitchellh/mapstructure"
+)
+
+// Storage is a securestorage.SecureStorage kept in memory, standing in for Vault. Values are converted the same way
+// the Vault adapter converts them so everything built on top of it sees the same thing it would with Vault.
+type Storage struct {
+	lock    sync.Mutex
+	secrets map[string]map[string]interface{}
+}
+
+var _ securestorage.SecureStorage = (*Storage)(nil)
+
+func NewStorage() *Storage {
+	return &Storage{
+		secrets: map[string]map[string]interface{}{},
+	}
+}
+
+func normalizeKey(key string) string {
+	return strings.Trim(key, "/")
+}
+
+func (storage *Storage) Store(key string, value interface{}) error {
+	var data map[string]interface{}
+	if err := mapstructure.Decode(value, &data); err != nil {
+		return err
+	}
+
+	storage.lock.Lock()
+	defer storage.lock.Unlock()
+
+	storage.secrets[normalizeKey(key)] = data
+	return nil
+}
+
+func (storage *Storage) StoreWithData(key string, value interface{}, output interface{}) error {
+	// Vault only returns data from writes to secrets engines other than KV, which nothing here uses.
+	return storage.Store(key, value)
+}
+
+// Lookup leaves output alone when there is nothing stored under key, the same as the Vault adapter.
+func (storage *Storage) Lookup(key string, output interface{}) error {
+	storage.lock.Lock()
+	data, found := storage.secrets[normalizeKey(key)]
+	storage.lock.Unlock()
+
+	if !found {
+		return nil
+	}
+
+	return mapstructure.Decode(data, output)
+}
+
+func (storage *Storage) Delete(key string) error {
+	storage.lock.Lock()
+	defer storage.lock.Unlock()
+
+	delete(storage.secrets, normalizeKey(key))
+	return nil
+}
+
+// LookupKeys lists what is directly under keyPath, with a trailing / on anything that has keys under it.
+func (storage *Storage) LookupKeys(keyPath string) ([]string, error) {
+	storage.lock.Lock()
+	defer storage.lock.Unlock()
+
+	prefix := normalizeKey(keyPath) + "/"
+
+	found := map[string]bool{}
+	for key := range storage.secrets {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+
+		name, rest, nested := strings.Cut(strings.TrimPrefix(key, prefix), "/")
+		if nested && rest != "" {
+			name += "/"
+		}
+		found[name] = true
+	}
+
+	keys := make([]string, 0, len(found))
+	for key := range found {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys, nil
+}
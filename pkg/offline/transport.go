@@ -0,0 +1,206 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package offline
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	sls_common "github.com/Cray-HPE/hms-sls/v2/pkg/sls-common"
+)
+
+// Transport answers the requests discovery makes of SLS, HSM and BMCs from the snapshots without touching the
+// network. SLS and HSM are told apart by the path of the request so it works whatever their URLs are set to. Every BMC
+// answers Redfish, and anything other than a read of SLS or HSM fails.
+type Transport struct {
+	SLS sls_common.SLSState
+	HSM HSMSnapshot
+}
+
+func (transport *Transport) RoundTrip(request *http.Request) (*http.Response, error) {
+	if request.Body != nil {
+		request.Body.Close()
+	}
+
+	if request.Method != http.MethodGet {
+		return respondError(request, http.StatusMethodNotAllowed, "only reads can be made offline")
+	}
+
+	path := request.URL.Path
+	switch {
+	case strings.HasPrefix(path, "/redfish/v1"):
+		return respond(request, http.StatusOK, map[string]string{})
+	case strings.Contains(path, "/hsm/v2/"):
+		_, hsmPath, _ := strings.Cut(path, "/hsm/v2/")
+		return transport.serveHSM(request, strings.Trim(hsmPath, "/"))
+	case strings.HasSuffix(strings.TrimSuffix(path, "/"), "/v1/hardware"):
+		return respond(request, http.StatusOK, transport.hardware())
+	}
+
+	return respondError(request, http.StatusNotFound, fmt.Sprintf("%s is not available offline", request.URL))
+}
+
+func (transport *Transport) hardware() []sls_common.GenericHardware {
+	hardware := make([]sls_common.GenericHardware, 0, len(transport.SLS.Hardware))
+	for _, item := range transport.SLS.Hardware {
+		hardware = append(hardware, item)
+	}
+	sort.Slice(hardware, func(i, j int) bool {
+		return hardware[i].Xname < hardware[j].Xname
+	})
+
+	return hardware
+}
+
+func (transport *Transport) serveHSM(request *http.Request, path string) (*http.Response, error) {
+	collections := []struct {
+		path    string
+		items   interface{}
+		wrapper string
+	}{
+		{"State/Components", transport.HSM.Components, "Components"},
+		{"Inventory/RedfishEndpoints", transport.HSM.RedfishEndpoints, "RedfishEndpoints"},
+		// EthernetInterfaces are the odd one out and come back as a bare array.
+		{"Inventory/EthernetInterfaces", transport.HSM.EthernetInterfaces, ""},
+	}
+
+	for _, collection := range collections {
+		if path != collection.path && !strings.HasPrefix(path, collection.path+"/") {
+			continue
+		}
+
+		items, err := toMaps(collection.items)
+		if err != nil {
+			return nil, err
+		}
+
+		if id := strings.TrimPrefix(path, collection.path+"/"); id != path {
+			for _, item := range items {
+				if strings.EqualFold(fieldString(item, "ID"), id) {
+					return respond(request, http.StatusOK, item)
+				}
+			}
+
+			return respondError(request, http.StatusNotFound, fmt.Sprintf("no such ID: %s", id))
+		}
+
+		matching := filter(items, request.URL.Query())
+		if collection.wrapper == "" {
+			return respond(request, http.StatusOK, matching)
+		}
+
+		return respond(request, http.StatusOK, map[string]interface{}{collection.wrapper: matching})
+	}
+
+	return respondError(request, http.StatusNotFound, fmt.Sprintf("HSM %s is not available offline", path))
+}
+
+// toMaps turns items into their JSON form so they can be matched against query parameters the way HSM does, by field
+// name without regard to case.
+func toMaps(items interface{}) ([]map[string]interface{}, error) {
+	itemsJSON, err := json.Marshal(items)
+	if err != nil {
+		return nil, err
+	}
+
+	maps := []map[string]interface{}{}
+	if err := json.Unmarshal(itemsJSON, &maps); err != nil {
+		return nil, err
+	}
+
+	return maps, nil
+}
+
+// A field that isn't there is the same as an empty one, which is how HSM finds EthernetInterfaces without a
+// ComponentID.
+func fieldString(item map[string]interface{}, name string) string {
+	for key, value := range item {
+		if strings.EqualFold(key, name) && value != nil {
+			return fmt.Sprint(value)
+		}
+	}
+
+	return ""
+}
+
+func filter(items []map[string]interface{}, query url.Values) []map[string]interface{} {
+	matching := []map[string]interface{}{}
+	for _, item := range items {
+		matches := true
+		for name, values := range query {
+			fieldValue := fieldString(item, name)
+
+			valueMatches := false
+			for _, value := range values {
+				if strings.EqualFold(fieldValue, value) {
+					valueMatches = true
+					break
+				}
+			}
+
+			if !valueMatches {
+				matches = false
+				break
+			}
+		}
+
+		if matches {
+			matching = append(matching, item)
+		}
+	}
+
+	return matching
+}
+
+func respond(request *http.Request, statusCode int, payload interface{}) (*http.Response, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", statusCode, http.StatusText(statusCode)),
+		StatusCode:    statusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": {"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       request,
+	}, nil
+}
+
+func respondError(request *http.Request, statusCode int, detail string) (*http.Response, error) {
+	return respond(request, statusCode, map[string]interface{}{
+		"title":  http.StatusText(statusCode),
+		"detail": detail,
+		"status": statusCode,
+	})
+}
@@ -1,6 +1,6 @@
 // MIT License
 //
-// (C) Copyright [2019-2021] Hewlett Packard Enterprise Development LP
+// (C) Copyright [2019-2021,2026] Hewlett Packard Enterprise Development LP
 //
 // Permission is hereby granted, free of charge, to any person obtaining a
 // copy of this software and associated documentation files (the "Software"),
@@ -58,6 +58,14 @@ func (credStore *PDUCredentialStore) StoreDefaultPDUCredentails(cred DefaultCred
 	return credStore.SecureStorage.Store(key, cred)
 }
 
+// GetPDUCredentails gets the credentials stored for a PDU, an empty Device is returned if there are none.
+func (credStore *PDUCredentialStore) GetPDUCredentails(xname string) (cred Device, err error) {
+	key := path.Join(credStore.KeyPath, xname)
+	err = credStore.SecureStorage.Lookup(key, &cred)
+
+	return
+}
+
 func (credStore *PDUCredentialStore) StorePDUCredentails(cred Device) error {
 	if cred.Xname == "" {
 		return errors.New("empty xname")
@@ -74,3 +82,12 @@ func (credStore *PDUCredentialStore) StorePDUCredentails(cred Device) error {
 	key := path.Join(credStore.KeyPath, cred.Xname)
 	return credStore.SecureStorage.Store(key, cred)
 }
+
+func (credStore *PDUCredentialStore) DeletePDUCredentails(xname string) error {
+	if xname == "" {
+		return errors.New("empty xname")
+	}
+
+	key := path.Join(credStore.KeyPath, xname)
+	return credStore.SecureStorage.Delete(key)
+}
@@ -0,0 +1,115 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package plan
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Diff renders the plan for a person to read. Each mutation is shown as its operation, xname and phase, prefixed with
+// "+" when it creates something, "~" when it changes something and "-" when it removes something, followed by the fields it would set with the same
+// markers for each field.
+func (plan Plan) Diff() string {
+	var builder strings.Builder
+
+	if len(plan.Mutations) == 0 {
+		builder.WriteString("No changes.\n")
+		return builder.String()
+	}
+
+	for _, mutation := range plan.Mutations {
+		if Removed(mutation.After) {
+			fmt.Fprintf(&builder, "- %s %s (%s)\n", mutation.Operation, mutation.Xname, mutation.Phase)
+			continue
+		}
+
+		marker := "+"
+		if mutation.Before != nil {
+			marker = "~"
+		}
+		fmt.Fprintf(&builder, "%s %s %s (%s)\n", marker, mutation.Operation, mutation.Xname, mutation.Phase)
+
+		before := map[string]interface{}{}
+		after := map[string]interface{}{}
+		_ = json.Unmarshal(mutation.Before, &before)
+		if err := json.Unmarshal(mutation.After, &after); err != nil {
+			// Not an object, just show it as is.
+			fmt.Fprintf(&builder, "    %s\n", string(mutation.After))
+			continue
+		}
+
+		var fields []string
+		for field := range after {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+
+		for _, field := range fields {
+			newValue := renderValue(after[field])
+			if source, isSecret := mutation.Secrets[field]; isSecret {
+				newValue = fmt.Sprintf("%s (from %s)", newValue, source)
+			}
+
+			oldValue, existed := before[field]
+			switch {
+			case !existed:
+				fmt.Fprintf(&builder, "    + %s: %s\n", field, newValue)
+			case renderValue(oldValue) != renderValue(after[field]):
+				fmt.Fprintf(&builder, "    ~ %s: %s -> %s\n", field, renderValue(oldValue), newValue)
+			default:
+				fmt.Fprintf(&builder, "      %s: %s\n", field, newValue)
+			}
+		}
+	}
+
+	fmt.Fprintf(&builder, "\n%d change(s).\n", len(plan.Mutations))
+	return builder.String()
+}
+
+func renderValue(value interface{}) string {
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+
+	return strings.TrimSuffix(buffer.String(), "\n")
+}
+
+// WriteFile saves the plan as JSON.
+func (plan Plan) WriteFile(path string) error {
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(plan); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, buffer.Bytes(), 0644)
+}
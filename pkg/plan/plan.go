@@ -0,0 +1,171 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package plan
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Operations discovery can perform against HSM and Vault.
+const (
+	OpStoreCompCredentials  = "vault.store_comp_credentials"
+	OpStorePDUCredentials   = "vault.store_pdu_credentials"
+	OpStoreSwitchCommunity  = "vault.store_switch_community"
+	OpAddEthernetInterface  = "hsm.add_ethernet_interface"
+	OpCreateRedfishEndpoint = "hsm.create_redfish_endpoint"
+	OpCreateComponent       = "hsm.create_component"
+	OpRediscover            = "hsm.rediscover"
+)
+
+// Redacted replaces secrets in the values recorded in a plan. The Secrets of a mutation say where each redacted
+// value comes from.
+const Redacted = "<REDACTED>"
+
+// Mutation is a single write discovery would make to HSM or Vault.
+type Mutation struct {
+	Phase     string `json:"Phase"`
+	Operation string `json:"Operation"`
+	Xname     string `json:"Xname"`
+
+	// Before is what is there today, if anything, and After is what would be written.
+	Before json.RawMessage `json:"Before,omitempty"`
+	After  json.RawMessage `json:"After"`
+
+	// Secrets maps each redacted field in After to the location the real value is read from, so plans never hold
+	// passwords.
+	Secrets map[string]string `json:"Secrets,omitempty"`
+
+	// BeforeSecretsDigest is a SecretsDigest of the secrets redacted from Before, so a secret that changes between
+	// planning and applying is still noticed.
+	BeforeSecretsDigest string `json:"BeforeSecretsDigest,omitempty"`
+}
+
+// Plan is every mutation a run of discovery would make.
+type Plan struct {
+	RunID   string    `json:"RunID"`
+	Created time.Time `json:"Created"`
+
+	// SLSFingerprint identifies the SLS hardware the plan was built from.
+	SLSFingerprint string `json:"SLSFingerprint,omitempty"`
+
+	Mutations []Mutation `json:"Mutations"`
+}
+
+// Removed reports whether after, the After of a mutation or journal entry, removes what was there rather than writing
+// a new value, as rolling back a write that created something does.
+func Removed(after json.RawMessage) bool {
+	return string(bytes.TrimSpace(after)) == "null"
+}
+
+// NewMutation builds a mutation, marshaling before and after into JSON. A nil before is left out, and a nil after
+// removes what is there.
+func NewMutation(phase, operation, xname string, before, after interface{},
+	secrets map[string]string) (Mutation, error) {
+	mutation := Mutation{
+		Phase:     phase,
+		Operation: operation,
+		Xname:     xname,
+		Secrets:   secrets,
+	}
+
+	var err error
+	if before != nil {
+		if mutation.Before, err = json.Marshal(before); err != nil {
+			return mutation, fmt.Errorf("failed to marshal current value: %w", err)
+		}
+	}
+
+	if mutation.After, err = json.Marshal(after); err != nil {
+		return mutation, fmt.Errorf("failed to marshal new value: %w", err)
+	}
+
+	return mutation, nil
+}
+
+// SecretsDigest hashes secrets, salted with the ID of the run that planned them, so that they can be compared without
+// the plan holding them.
+func SecretsDigest(runID string, secrets ...string) string {
+	hash := sha256.New()
+	hash.Write([]byte(runID))
+	for _, secret := range secrets {
+		// Each secret is length prefixed so moving characters from one to the next changes the digest.
+		fmt.Fprintf(hash, "\x00%d:%s", len(secret), secret)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// Recorder collects the mutations from phases that may be running concurrently.
+type Recorder struct {
+	lock sync.Mutex
+	plan Plan
+}
+
+func NewRecorder(runID string) *Recorder {
+	return &Recorder{
+		plan: Plan{
+			RunID:     runID,
+			Created:   time.Now(),
+			Mutations: []Mutation{},
+		},
+	}
+}
+
+func (recorder *Recorder) SetSLSFingerprint(fingerprint string) {
+	recorder.lock.Lock()
+	defer recorder.lock.Unlock()
+
+	recorder.plan.SLSFingerprint = fingerprint
+}
+
+func (recorder *Recorder) RunID() string {
+	recorder.lock.Lock()
+	defer recorder.lock.Unlock()
+
+	return recorder.plan.RunID
+}
+
+// Add records a mutation.
+func (recorder *Recorder) Add(mutation Mutation) {
+	recorder.lock.Lock()
+	defer recorder.lock.Unlock()
+
+	recorder.plan.Mutations = append(recorder.plan.Mutations, mutation)
+}
+
+// Plan returns a copy of everything recorded so far.
+func (recorder *Recorder) Plan() Plan {
+	recorder.lock.Lock()
+	defer recorder.lock.Unlock()
+
+	planCopy := recorder.plan
+	planCopy.Mutations = append([]Mutation{}, recorder.plan.Mutations...)
+
+	return planCopy
+}
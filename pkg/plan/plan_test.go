@@ -0,0 +1,53 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package plan
+
+import "testing"
+
+// TestSecretsDigest checks that the digest only stays the same when the run and every secret do.
+func TestSecretsDigest(t *testing.T) {
+	digest := SecretsDigest("run", "password", "auth", "priv")
+
+	tests := []struct {
+		name     string
+		runID    string
+		secrets  []string
+		wantSame bool
+	}{
+		{name: "same secrets", runID: "run", secrets: []string{"password", "auth", "priv"}, wantSame: true},
+		{name: "rotated password", runID: "run", secrets: []string{"rotated", "auth", "priv"}},
+		{name: "secret cleared", runID: "run", secrets: []string{"password", "", "priv"}},
+		{name: "characters moved between secrets", runID: "run", secrets: []string{"passwordauth", "", "priv"}},
+		{name: "different run", runID: "other", secrets: []string{"password", "auth", "priv"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := SecretsDigest(test.runID, test.secrets...)
+			if (got == digest) != test.wantSame {
+				t.Errorf("SecretsDigest(%q, %q) = %s, same as original = %v, want %v",
+					test.runID, test.secrets, got, got == digest, test.wantSame)
+			}
+		})
+	}
+}
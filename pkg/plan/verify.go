@@ -0,0 +1,194 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package plan
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Where the secrets of a mutation can be read from when it is applied.
+const (
+	SecretSourceVault = "vault"
+	SecretSourceSLS   = "sls"
+)
+
+// SecretRef says where a secret left out of a plan lives, for example "vault:reds-creds/defaults#Cray.password" is
+// the password field of the Cray entry stored under reds-creds/defaults in Vault, and
+// "sls:x3000c0w14#SNMPAuthPassword" is the SNMPAuthPassword extra property of that switch in SLS.
+type SecretRef struct {
+	Source   string
+	Location string
+	Field    string
+}
+
+func ParseSecretRef(ref string) (SecretRef, error) {
+	source, rest, foundSource := strings.Cut(ref, ":")
+	location, field, foundField := strings.Cut(rest, "#")
+	if !foundSource || !foundField || location == "" || field == "" {
+		return SecretRef{}, fmt.Errorf("malformed secret reference: %s", ref)
+	}
+
+	switch source {
+	case SecretSourceVault, SecretSourceSLS:
+	default:
+		return SecretRef{}, fmt.Errorf("unknown secret source %q in reference: %s", source, ref)
+	}
+
+	return SecretRef{Source: source, Location: location, Field: field}, nil
+}
+
+// Lookup finds the field of the secret in a decoded JSON object, following dots into nested objects.
+func (ref SecretRef) Lookup(object map[string]interface{}) (string, error) {
+	var value interface{} = object
+	for _, key := range strings.Split(ref.Field, ".") {
+		nested, isObject := value.(map[string]interface{})
+		if !isObject {
+			return "", fmt.Errorf("%s is not an object", key)
+		}
+
+		// Secrets written through the secure storage adapter are keyed by Go field name while those written by other
+		// tools tend to use the JSON names, so match keys the same case insensitive way the adapter reads them.
+		value = nil
+		for nestedKey, nestedValue := range nested {
+			if strings.EqualFold(nestedKey, key) {
+				value = nestedValue
+				if nestedKey == key {
+					break
+				}
+			}
+		}
+	}
+
+	secret, isString := value.(string)
+	if !isString || secret == "" {
+		return "", fmt.Errorf("no value for %s at %s:%s", ref.Field, ref.Source, ref.Location)
+	}
+
+	return secret, nil
+}
+
+// ReadFile loads a plan saved with WriteFile.
+func ReadFile(path string) (Plan, error) {
+	var plan Plan
+
+	planBytes, err := os.ReadFile(path)
+	if err != nil {
+		return plan, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(planBytes))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&plan); err != nil {
+		return plan, fmt.Errorf("failed to decode plan: %w", err)
+	}
+
+	return plan, nil
+}
+
+// Fingerprint is a hash of the JSON encoding of value, used to tell whether the state a plan was built from has
+// changed. Maps are encoded with sorted keys, so slices should be sorted by the caller.
+func Fingerprint(value interface{}) (string, error) {
+	valueBytes, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(valueBytes)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Unchanged reports whether current, the value there is now, still matches the Before of the mutation. A nil current
+// means nothing is there. Only the fields the mutation sets are compared, minus any ignored fields, so unrelated
+// changes such as a component's power state don't invalidate a plan.
+func (mutation Mutation) Unchanged(current interface{}, ignoredFields ...string) (bool, error) {
+	if current == nil || mutation.Before == nil {
+		return current == nil && mutation.Before == nil, nil
+	}
+
+	return FieldsMatch(mutation.After, mutation.Before, current, ignoredFields...)
+}
+
+// FieldsMatch reports whether expected and current, both JSON objects, have the same value for every field of
+// fields other than the ignored ones.
+func FieldsMatch(fields, expected json.RawMessage, current interface{}, ignoredFields ...string) (bool, error) {
+	currentBytes, err := json.Marshal(current)
+	if err != nil {
+		return false, err
+	}
+
+	var fieldValues, expectedValues, currentValues map[string]interface{}
+	if err := json.Unmarshal(fields, &fieldValues); err != nil {
+		return false, err
+	}
+	if err := json.Unmarshal(expected, &expectedValues); err != nil {
+		return false, err
+	}
+	if err := json.Unmarshal(currentBytes, &currentValues); err != nil {
+		return false, err
+	}
+
+	for _, field := range ignoredFields {
+		delete(fieldValues, field)
+	}
+
+	for field := range fieldValues {
+		if renderValue(expectedValues[field]) != renderValue(currentValues[field]) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// ResolveSecrets returns the After of the mutation with every redacted secret filled in by resolve.
+func (mutation Mutation) ResolveSecrets(resolve func(ref SecretRef) (string, error)) (json.RawMessage, error) {
+	if len(mutation.Secrets) == 0 {
+		return mutation.After, nil
+	}
+
+	var after map[string]interface{}
+	if err := json.Unmarshal(mutation.After, &after); err != nil {
+		return nil, err
+	}
+
+	for field, rawRef := range mutation.Secrets {
+		ref, err := ParseSecretRef(rawRef)
+		if err != nil {
+			return nil, err
+		}
+
+		secret, err := resolve(ref)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve secret for %s: %w", field, err)
+		}
+		after[field] = secret
+	}
+
+	return json.Marshal(after)
+}
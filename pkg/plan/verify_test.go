@@ -0,0 +1,150 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package plan
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+type testComponent struct {
+	ID      string
+	State   string
+	Flag    string
+	Role    string
+	NID     int  `json:",omitempty"`
+	Enabled bool `json:",omitempty"`
+}
+
+func TestFieldsMatch(t *testing.T) {
+	tests := []struct {
+		name          string
+		fields        string
+		expected      string
+		current       interface{}
+		ignoredFields []string
+		want          bool
+		wantErr       bool
+	}{
+		{
+			name:     "same",
+			fields:   `{"ID": "x3000c0s9b0n0", "Role": "Compute"}`,
+			expected: `{"ID": "x3000c0s9b0n0", "Role": "Compute", "State": "On"}`,
+			current:  testComponent{ID: "x3000c0s9b0n0", Role: "Compute", State: "On"},
+			want:     true,
+		},
+		{
+			name:     "field that isn't set changed",
+			fields:   `{"ID": "x3000c0s9b0n0", "Role": "Compute"}`,
+			expected: `{"ID": "x3000c0s9b0n0", "Role": "Compute", "State": "On"}`,
+			current:  testComponent{ID: "x3000c0s9b0n0", Role: "Compute", State: "Off"},
+			want:     true,
+		},
+		{
+			name:     "field that is set changed",
+			fields:   `{"ID": "x3000c0s9b0n0", "Role": "Compute"}`,
+			expected: `{"ID": "x3000c0s9b0n0", "Role": "Compute"}`,
+			current:  testComponent{ID: "x3000c0s9b0n0", Role: "Application"},
+		},
+		{
+			name:          "ignored field changed",
+			fields:        `{"ID": "x3000c0s9b0n0", "State": "Populated", "Flag": "OK"}`,
+			expected:      `{"ID": "x3000c0s9b0n0", "State": "On", "Flag": "OK"}`,
+			current:       testComponent{ID: "x3000c0s9b0n0", State: "Off", Flag: "Warning"},
+			ignoredFields: []string{"State", "Flag"},
+			want:          true,
+		},
+		{
+			name:     "numbers compared by value",
+			fields:   `{"NID": 1}`,
+			expected: `{"NID": 1.0}`,
+			current:  testComponent{NID: 1},
+			want:     true,
+		},
+		{
+			name:     "field removed",
+			fields:   `{"Enabled": true}`,
+			expected: `{"Enabled": true}`,
+			current:  testComponent{},
+		},
+		{
+			name:     "field that wasn't there still isn't",
+			fields:   `{"Enabled": true}`,
+			expected: `{}`,
+			current:  testComponent{},
+			want:     true,
+		},
+		{
+			name:     "not an object",
+			fields:   `["ID"]`,
+			expected: `{}`,
+			current:  testComponent{},
+			wantErr:  true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := FieldsMatch(json.RawMessage(test.fields), json.RawMessage(test.expected), test.current,
+				test.ignoredFields...)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("FieldsMatch() error = %v, wantErr %v", err, test.wantErr)
+			}
+			if got != test.want {
+				t.Errorf("FieldsMatch() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestUnchanged(t *testing.T) {
+	after := json.RawMessage(`{"ID": "x3000c0s9b0n0", "Role": "Compute"}`)
+
+	tests := []struct {
+		name    string
+		before  json.RawMessage
+		current interface{}
+		want    bool
+	}{
+		{name: "still nothing there", before: nil, current: nil, want: true},
+		{name: "created since", before: nil, current: testComponent{ID: "x3000c0s9b0n0"}},
+		{name: "removed since", before: json.RawMessage(`{"ID": "x3000c0s9b0n0", "Role": "Compute"}`),
+			current: nil},
+		{name: "still there", before: json.RawMessage(`{"ID": "x3000c0s9b0n0", "Role": "Compute"}`),
+			current: testComponent{ID: "x3000c0s9b0n0", Role: "Compute", State: "Ready"}, want: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mutation := Mutation{Operation: OpCreateComponent, Xname: "x3000c0s9b0n0", Before: test.before,
+				After: after}
+			got, err := mutation.Unchanged(test.current)
+			if err != nil {
+				t.Fatalf("Unchanged() error = %v", err)
+			}
+			if got != test.want {
+				t.Errorf("Unchanged() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
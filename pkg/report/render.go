@@ -0,0 +1,116 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package report
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Formats a report can be rendered in.
+const (
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+	FormatCSV  = "csv"
+)
+
+var csvHeader = []string{
+	"Phase", "MACAddress", "Source", "IPAddress", "SwitchXname", "SwitchPort", "SwitchLAG", "Xname", "PDUType",
+	"CredentialSource", "RedfishProbe", "Action", "Failed", "Error",
+}
+
+// Render turns the report into the given format. The CSV rendering only includes the devices as it is meant to be
+// pulled into a spreadsheet.
+func (report Report) Render(format string) ([]byte, error) {
+	switch format {
+	case FormatJSON:
+		return json.MarshalIndent(report, "", "  ")
+	case FormatYAML:
+		return yaml.Marshal(report)
+	case FormatCSV:
+		var buffer bytes.Buffer
+		writer := csv.NewWriter(&buffer)
+
+		if err := writer.Write(csvHeader); err != nil {
+			return nil, err
+		}
+		for _, device := range report.Devices {
+			err := writer.Write([]string{
+				device.Phase, device.MACAddress, device.Source, device.IPAddress, device.SwitchXname,
+				device.SwitchPort, device.SwitchLAG, device.Xname, device.PDUType, device.CredentialSource,
+				device.RedfishProbe, device.Action, strconv.FormatBool(device.Failed), device.Error,
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		writer.Flush()
+		return buffer.Bytes(), writer.Error()
+	default:
+		return nil, fmt.Errorf("unknown report format (%s)", format)
+	}
+}
+
+// WriteFile renders the report into the given file.
+func (report Report) WriteFile(path string, format string) error {
+	rendered, err := report.Render(format)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, rendered, 0644)
+}
+
+// Post sends the JSON rendering of the report to an HTTP sink.
+func (report Report) Post(client *http.Client, url string) error {
+	payload, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed to marshal report: %w", err)
+	}
+
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	response, err := client.Post(url, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to POST report: %w", err)
+	}
+	defer base.DrainAndCloseResponseBody(response)
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("unexpected status code from report sink: %d", response.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package report
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+)
+
+// TestRenderCSV checks every column of a device ends up under the right heading.
+func TestRenderCSV(t *testing.T) {
+	tests := []struct {
+		name   string
+		device Device
+		want   map[string]string
+	}{
+		{
+			name: "found in switch MAC address table",
+			device: Device{Phase: "river", MACAddress: "b42e993b7028", SwitchXname: "x3000c0w14",
+				SwitchPort: "1/1/28", Xname: "x3000c0s9b0", Action: ActionCreatedEndpoint},
+			want: map[string]string{"Source": "", "SwitchPort": "1/1/28", "SwitchLAG": "",
+				"Action": ActionCreatedEndpoint, "Failed": "false"},
+		},
+		{
+			name: "found in switch ARP table on a LAG",
+			device: Device{Phase: "river", MACAddress: "b42e993b7029", Source: SourceSwitchARP,
+				IPAddress: "10.254.1.5", SwitchXname: "x3000c0w14", SwitchPort: "1/1/2", SwitchLAG: "lag100",
+				Xname: "x3000c0s11b0", Action: ActionFailed, Failed: true, Error: "no Redfish"},
+			want: map[string]string{"Source": SourceSwitchARP, "IPAddress": "10.254.1.5", "SwitchPort": "1/1/2",
+				"SwitchLAG": "lag100", "Xname": "x3000c0s11b0", "Failed": "true", "Error": "no Redfish"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rendered, err := Report{Devices: []Device{test.device}}.Render(FormatCSV)
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+
+			records, err := csv.NewReader(bytes.NewReader(rendered)).ReadAll()
+			if err != nil {
+				t.Fatalf("failed to read rendered CSV: %v", err)
+			}
+			if len(records) != 2 || len(records[1]) != len(records[0]) {
+				t.Fatalf("Render() = %q, want a header and one row of the same width", records)
+			}
+
+			for column, heading := range records[0] {
+				if want, checked := test.want[heading]; checked && records[1][column] != want {
+					t.Errorf("%s = %q, want %q", heading, records[1][column], want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,251 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package report
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Actions recorded against a device once discovery is done with it.
+const (
+	ActionDiscovered         = "discovered"
+	ActionInformedRTS        = "informed_rts"
+	ActionNotFoundInSwitches = "not_found_in_switches"
+	ActionUnresolved         = "unresolved_in_sls"
+	ActionRedfishUnreachable = "redfish_unreachable"
+	ActionCreatedEndpoint    = "created_redfish_endpoint"
+	ActionCreatedComponent   = "created_component"
+	ActionStoredCredentials  = "stored_credentials"
+	ActionAlreadyPresent     = "already_present"
+	ActionRediscovery        = "rediscovery_requested"
+	ActionRolledBack         = "rolled_back"
+	ActionSkipped            = "skipped"
+	ActionFailed             = "failed"
+)
+
+// Where the credentials used for a device came from.
+const (
+	CredentialSourceVault   = "vault"
+	CredentialSourceDefault = "default"
+	CredentialSourceSLS     = "sls"
+)
+
+// Where a device that wasn't in HSM was found.
+const (
+	SourceSwitchARP = "switch_arp"
+)
+
+// Phase is the outcome of a single discovery phase.
+type Phase struct {
+	Name     string    `json:"Name" yaml:"Name"`
+	Started  time.Time `json:"Started" yaml:"Started"`
+	Finished time.Time `json:"Finished" yaml:"Finished"`
+	Duration string    `json:"Duration" yaml:"Duration"`
+	Error    string    `json:"Error,omitempty" yaml:"Error,omitempty"`
+}
+
+// Device is everything a phase found out about, and did to, one piece of hardware.
+type Device struct {
+	Phase            string `json:"Phase" yaml:"Phase"`
+	MACAddress       string `json:"MACAddress,omitempty" yaml:"MACAddress,omitempty"`
+	Source           string `json:"Source,omitempty" yaml:"Source,omitempty"`
+	IPAddress        string `json:"IPAddress,omitempty" yaml:"IPAddress,omitempty"`
+	SwitchXname      string `json:"SwitchXname,omitempty" yaml:"SwitchXname,omitempty"`
+	SwitchPort       string `json:"SwitchPort,omitempty" yaml:"SwitchPort,omitempty"`
+	SwitchLAG        string `json:"SwitchLAG,omitempty" yaml:"SwitchLAG,omitempty"`
+	Xname            string `json:"Xname,omitempty" yaml:"Xname,omitempty"`
+	PDUType          string `json:"PDUType,omitempty" yaml:"PDUType,omitempty"`
+	CredentialSource string `json:"CredentialSource,omitempty" yaml:"CredentialSource,omitempty"`
+	RedfishProbe     string `json:"RedfishProbe,omitempty" yaml:"RedfishProbe,omitempty"`
+	Action           string `json:"Action" yaml:"Action"`
+	Failed           bool   `json:"Failed" yaml:"Failed"`
+	Error            string `json:"Error,omitempty" yaml:"Error,omitempty"`
+}
+
+// Failure marks the device as failed because of err.
+func (device Device) Failure(err error) Device {
+	device.Action = ActionFailed
+	device.Failed = true
+	device.Error = err.Error()
+
+	return device
+}
+
+// Switch is how collecting the MAC address table of a management switch went, along with what the switch said it is
+// and any ways that differs from SLS.
+type Switch struct {
+	Xname      string    `json:"Xname" yaml:"Xname"`
+	Started    time.Time `json:"Started" yaml:"Started"`
+	Duration   string    `json:"Duration" yaml:"Duration"`
+	Profile    string    `json:"Profile,omitempty" yaml:"Profile,omitempty"`
+	Vendor     string    `json:"Vendor,omitempty" yaml:"Vendor,omitempty"`
+	Model      string    `json:"Model,omitempty" yaml:"Model,omitempty"`
+	SysDescr   string    `json:"SysDescr,omitempty" yaml:"SysDescr,omitempty"`
+	SLSBrand   string    `json:"SLSBrand,omitempty" yaml:"SLSBrand,omitempty"`
+	SLSModel   string    `json:"SLSModel,omitempty" yaml:"SLSModel,omitempty"`
+	Mismatches []string  `json:"Mismatches,omitempty" yaml:"Mismatches,omitempty"`
+
+	MatchedConnectors   []Connector `json:"MatchedConnectors,omitempty" yaml:"MatchedConnectors,omitempty"`
+	UnmatchedConnectors []Connector `json:"UnmatchedConnectors,omitempty" yaml:"UnmatchedConnectors,omitempty"`
+	InterSwitchPorts    []Neighbor  `json:"InterSwitchPorts,omitempty" yaml:"InterSwitchPorts,omitempty"`
+	LAGs                []LAG       `json:"LAGs,omitempty" yaml:"LAGs,omitempty"`
+
+	MACs       int    `json:"MACs" yaml:"MACs"`
+	ErrorClass string `json:"ErrorClass,omitempty" yaml:"ErrorClass,omitempty"`
+	UplinkMACs int    `json:"UplinkMACs,omitempty" yaml:"UplinkMACs,omitempty"`
+	ARPEntries int    `json:"ARPEntries,omitempty" yaml:"ARPEntries,omitempty"`
+	Error      string `json:"Error,omitempty" yaml:"Error,omitempty"`
+}
+
+// Connector is a switch connector SLS has for a management switch, and the port of the switch it matched.
+type Connector struct {
+	Xname      string `json:"Xname" yaml:"Xname"`
+	VendorName string `json:"VendorName" yaml:"VendorName"`
+	Port       string `json:"Port,omitempty" yaml:"Port,omitempty"`
+}
+
+// Neighbor is a switch heard with LLDP on a port of a management switch.
+type Neighbor struct {
+	Port      string `json:"Port" yaml:"Port"`
+	SysName   string `json:"SysName,omitempty" yaml:"SysName,omitempty"`
+	ChassisID string `json:"ChassisID,omitempty" yaml:"ChassisID,omitempty"`
+	PortID    string `json:"PortID,omitempty" yaml:"PortID,omitempty"`
+}
+
+// LAG is a link aggregation on a management switch, with its member ports and what it is cabled to.
+type LAG struct {
+	Port    string   `json:"Port" yaml:"Port"`
+	Members []string `json:"Members" yaml:"Members"`
+	Class   string   `json:"Class" yaml:"Class"`
+}
+
+// Report is the machine-readable summary of a discovery run.
+type Report struct {
+	RunID    string    `json:"RunID" yaml:"RunID"`
+	Started  time.Time `json:"Started" yaml:"Started"`
+	Finished time.Time `json:"Finished" yaml:"Finished"`
+	Phases   []Phase   `json:"Phases" yaml:"Phases"`
+	Devices  []Device  `json:"Devices" yaml:"Devices"`
+	Switches []Switch  `json:"Switches" yaml:"Switches"`
+}
+
+// Failed returns true if any phase or device in the report failed.
+func (report Report) Failed() bool {
+	for _, phase := range report.Phases {
+		if phase.Error != "" {
+			return true
+		}
+	}
+
+	for _, device := range report.Devices {
+		if device.Failed {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NewRunID returns an ID for a run that sorts by when the run started.
+func NewRunID() string {
+	return fmt.Sprintf("%s-%04x", time.Now().UTC().Format("20060102T150405Z"), rand.Intn(0x10000))
+}
+
+// Recorder collects a report from phases that may be running concurrently.
+type Recorder struct {
+	lock   sync.Mutex
+	report Report
+}
+
+func NewRecorder(runID string) *Recorder {
+	return &Recorder{
+		report: Report{
+			RunID:    runID,
+			Started:  time.Now(),
+			Phases:   []Phase{},
+			Devices:  []Device{},
+			Switches: []Switch{},
+		},
+	}
+}
+
+// RunID returns the ID of the run being recorded.
+func (recorder *Recorder) RunID() string {
+	recorder.lock.Lock()
+	defer recorder.lock.Unlock()
+
+	return recorder.report.RunID
+}
+
+// AddPhase records the outcome of a phase.
+func (recorder *Recorder) AddPhase(name string, started, finished time.Time, err error) {
+	phase := Phase{
+		Name:     name,
+		Started:  started,
+		Finished: finished,
+		Duration: finished.Sub(started).String(),
+	}
+	if err != nil {
+		phase.Error = err.Error()
+	}
+
+	recorder.lock.Lock()
+	defer recorder.lock.Unlock()
+
+	recorder.report.Phases = append(recorder.report.Phases, phase)
+}
+
+// AddDevice records what happened to a device.
+func (recorder *Recorder) AddDevice(device Device) {
+	recorder.lock.Lock()
+	defer recorder.lock.Unlock()
+
+	recorder.report.Devices = append(recorder.report.Devices, device)
+}
+
+// AddSwitch records how collecting the MAC address table of a switch went.
+func (recorder *Recorder) AddSwitch(managementSwitch Switch, started, finished time.Time, err error) {
+	managementSwitch.Started = started
+	managementSwitch.Duration = finished.Sub(started).String()
+	if err != nil {
+		managementSwitch.Error = err.Error()
+	}
+
+	recorder.lock.Lock()
+	defer recorder.lock.Unlock()
+
+	recorder.report.Switches = append(recorder.report.Switches, managementSwitch)
+}
+
+// Finish stamps the end of the run and returns the finished report.
+func (recorder *Recorder) Finish() Report {
+	recorder.lock.Lock()
+	defer recorder.lock.Unlock()
+
+	recorder.report.Finished = time.Now()
+
+	return recorder.report
+}
@@ -0,0 +1,141 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+// Package sls is a client for the System Layout Service that can load all of the hardware in SLS at once into a
+// Snapshot, so the many lookups made during discovery don't each have to go back to SLS.
+package sls
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+	sls_common "github.com/Cray-HPE/hms-sls/v2/pkg/sls-common"
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+type Client struct {
+	baseURL    string
+	httpClient *retryablehttp.Client
+
+	lock     sync.Mutex
+	snapshot *Snapshot
+}
+
+// NewClient makes a client for the SLS at baseURL, such as http://cray-sls.
+func NewClient(baseURL string, httpClient *retryablehttp.Client) *Client {
+	return &Client{
+		baseURL:    baseURL,
+		httpClient: httpClient,
+	}
+}
+
+func (client *Client) get(ctx context.Context, path string, query url.Values, result interface{}) error {
+	requestURL := fmt.Sprintf("%s/%s", client.baseURL, path)
+	if len(query) > 0 {
+		requestURL = fmt.Sprintf("%s?%s", requestURL, query.Encode())
+	}
+
+	req, err := retryablehttp.NewRequestWithContext(ctx, "GET", requestURL, nil)
+	if err != nil {
+		return errors.Join(fmt.Errorf("failed to build GET request"), err)
+	}
+
+	response, err := client.httpClient.Do(req)
+	defer base.DrainAndCloseResponseBody(response)
+	if err != nil {
+		return errors.Join(fmt.Errorf("failed to perform GET request against SLS"), err)
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d expected 200", response.StatusCode)
+	}
+
+	if err := json.NewDecoder(response.Body).Decode(result); err != nil {
+		return errors.Join(fmt.Errorf("failed to decode response from SLS"), err)
+	}
+
+	return nil
+}
+
+// GetHardware returns every piece of hardware in SLS.
+func (client *Client) GetHardware(ctx context.Context) ([]sls_common.GenericHardware, error) {
+	var hardware []sls_common.GenericHardware
+	if err := client.get(ctx, "v1/hardware", nil, &hardware); err != nil {
+		return nil, err
+	}
+
+	return hardware, nil
+}
+
+// SearchHardware asks SLS for the hardware matching params, such as type, class, parent or extra_properties.Role.
+func (client *Client) SearchHardware(ctx context.Context, params map[string]string) ([]sls_common.GenericHardware,
+	error) {
+	query := url.Values{}
+	for key, value := range params {
+		query.Set(key, value)
+	}
+
+	var hardware []sls_common.GenericHardware
+	if err := client.get(ctx, "v1/search/hardware", query, &hardware); err != nil {
+		return nil, err
+	}
+
+	return hardware, nil
+}
+
+// Snapshot returns every piece of hardware in SLS, only fetching it from SLS the first time it is asked for or after
+// Invalidate has been called.
+func (client *Client) Snapshot(ctx context.Context) (*Snapshot, error) {
+	client.lock.Lock()
+	defer client.lock.Unlock()
+
+	if client.snapshot != nil {
+		return client.snapshot, nil
+	}
+
+	hardware, err := client.GetHardware(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	client.snapshot = NewSnapshot(hardware)
+	return client.snapshot, nil
+}
+
+// SetSnapshot replaces the cached snapshot, for example with one read from a file.
+func (client *Client) SetSnapshot(snapshot *Snapshot) {
+	client.lock.Lock()
+	defer client.lock.Unlock()
+
+	client.snapshot = snapshot
+}
+
+// Invalidate drops the cached snapshot so the next call to Snapshot gets the current contents of SLS.
+func (client *Client) Invalidate() {
+	client.SetSnapshot(nil)
+}
@@ -0,0 +1,267 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package sls
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	sls_common "github.com/Cray-HPE/hms-sls/v2/pkg/sls-common"
+	"github.com/Cray-HPE/hms-xname/xnametypes"
+)
+
+// Snapshot is the hardware in SLS at a point in time, indexed for the lookups discovery makes. It is never modified
+// once made, so it is safe to share between goroutines.
+type Snapshot struct {
+	hardware map[string]sls_common.GenericHardware
+
+	byType   map[sls_common.HMSStringType][]string
+	byClass  map[sls_common.CabinetType][]string
+	byParent map[string][]string
+
+	// Switch connectors by the switch they are on and the name the switch vendor gives the port.
+	switchPorts map[switchPort]string
+	// Switch connectors by the xnames of the NICs cabled to them.
+	byNodeNic map[string][]string
+}
+
+type switchPort struct {
+	switchXname string
+	vendorName  string
+}
+
+// NewSnapshot indexes the given hardware.
+func NewSnapshot(hardware []sls_common.GenericHardware) *Snapshot {
+	snapshot := &Snapshot{
+		hardware:    map[string]sls_common.GenericHardware{},
+		byType:      map[sls_common.HMSStringType][]string{},
+		byClass:     map[sls_common.CabinetType][]string{},
+		byParent:    map[string][]string{},
+		switchPorts: map[switchPort]string{},
+		byNodeNic:   map[string][]string{},
+	}
+
+	// Sort first so every index is in xname order no matter what order SLS returned things in.
+	sorted := append([]sls_common.GenericHardware(nil), hardware...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Xname < sorted[j].Xname
+	})
+
+	for _, item := range sorted {
+		snapshot.hardware[item.Xname] = item
+		snapshot.byType[item.Type] = append(snapshot.byType[item.Type], item.Xname)
+		snapshot.byClass[item.Class] = append(snapshot.byClass[item.Class], item.Xname)
+		snapshot.byParent[item.Parent] = append(snapshot.byParent[item.Parent], item.Xname)
+
+		if item.Type != sls_common.MgmtSwitchConnector {
+			continue
+		}
+
+		var connector sls_common.ComptypeMgmtSwitchConnector
+		if err := DecodeExtraProperties(item, &connector); err != nil {
+			continue
+		}
+
+		snapshot.switchPorts[switchPort{item.Parent, connector.VendorName}] = item.Xname
+		for _, nodeNic := range connector.NodeNics {
+			snapshot.byNodeNic[nodeNic] = append(snapshot.byNodeNic[nodeNic], item.Xname)
+		}
+	}
+
+	return snapshot
+}
+
+func (snapshot *Snapshot) lookup(xnames []string) []sls_common.GenericHardware {
+	hardware := make([]sls_common.GenericHardware, 0, len(xnames))
+	for _, xname := range xnames {
+		hardware = append(hardware, snapshot.hardware[xname])
+	}
+
+	return hardware
+}
+
+// Get returns the hardware with the given xname.
+func (snapshot *Snapshot) Get(xname string) (sls_common.GenericHardware, bool) {
+	hardware, found := snapshot.hardware[xname]
+	return hardware, found
+}
+
+// All returns every piece of hardware in xname order.
+func (snapshot *Snapshot) All() []sls_common.GenericHardware {
+	xnames := make([]string, 0, len(snapshot.hardware))
+	for xname := range snapshot.hardware {
+		xnames = append(xnames, xname)
+	}
+	sort.Strings(xnames)
+
+	return snapshot.lookup(xnames)
+}
+
+// ByType returns all hardware of the given type in xname order.
+func (snapshot *Snapshot) ByType(hardwareType sls_common.HMSStringType) []sls_common.GenericHardware {
+	return snapshot.lookup(snapshot.byType[hardwareType])
+}
+
+// ByClass returns all hardware of the given class in xname order.
+func (snapshot *Snapshot) ByClass(class sls_common.CabinetType) []sls_common.GenericHardware {
+	return snapshot.lookup(snapshot.byClass[class])
+}
+
+// ByParent returns all hardware with the given parent in xname order.
+func (snapshot *Snapshot) ByParent(parent string) []sls_common.GenericHardware {
+	return snapshot.lookup(snapshot.byParent[parent])
+}
+
+// SwitchConnector returns the switch connector for the port on a management switch, using the name the switch
+// vendor gives the port such as ethernet1/1/1.
+func (snapshot *Snapshot) SwitchConnector(switchXname, vendorName string) (sls_common.GenericHardware, bool) {
+	xname, found := snapshot.switchPorts[switchPort{switchXname, vendorName}]
+	if !found {
+		return sls_common.GenericHardware{}, false
+	}
+
+	return snapshot.hardware[xname], true
+}
+
+// SwitchConnectorsForNodeNic returns the switch connectors a NIC, usually a BMC, is cabled to.
+func (snapshot *Snapshot) SwitchConnectorsForNodeNic(xname string) []sls_common.GenericHardware {
+	return snapshot.lookup(snapshot.byNodeNic[xname])
+}
+
+// Search returns the hardware matching every field set in query, in xname order.
+func (snapshot *Snapshot) Search(query Query) []sls_common.GenericHardware {
+	var candidates []sls_common.GenericHardware
+	switch {
+	case query.Parent != "":
+		candidates = snapshot.ByParent(query.Parent)
+	case query.Type != "":
+		candidates = snapshot.ByType(query.Type)
+	case query.Class != "":
+		candidates = snapshot.ByClass(query.Class)
+	default:
+		candidates = snapshot.All()
+	}
+
+	var matches []sls_common.GenericHardware
+	for _, hardware := range candidates {
+		if query.matches(hardware) {
+			matches = append(matches, hardware)
+		}
+	}
+
+	return matches
+}
+
+// Query picks out hardware the same way the SLS search API does, fields left empty match everything.
+type Query struct {
+	Type   sls_common.HMSStringType
+	Class  sls_common.CabinetType
+	Parent string
+
+	// ExtraProperties must all be equal to the top level extra property with the same name, such as Role.
+	ExtraProperties map[string]string
+}
+
+func (query Query) matches(hardware sls_common.GenericHardware) bool {
+	if query.Type != "" && hardware.Type != query.Type {
+		return false
+	}
+	if query.Class != "" && hardware.Class != query.Class {
+		return false
+	}
+	if query.Parent != "" && hardware.Parent != query.Parent {
+		return false
+	}
+
+	if len(query.ExtraProperties) == 0 {
+		return true
+	}
+
+	var extraProperties map[string]interface{}
+	if err := DecodeExtraProperties(hardware, &extraProperties); err != nil {
+		return false
+	}
+
+	for key, value := range query.ExtraProperties {
+		if fmt.Sprint(extraProperties[key]) != value {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ParseQueries parses queries by type and class written as type:class,type such as
+// comptype_mgmt_switch:River,comptype_cdu_mgmt_switch. A type without a class matches every class.
+func ParseQueries(spec string) ([]Query, error) {
+	var queries []Query
+	for _, entry := range strings.Split(spec, ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+
+		hardwareType, class, _ := strings.Cut(entry, ":")
+		query := Query{Type: sls_common.HMSStringType(strings.TrimSpace(hardwareType))}
+		if sls_common.HMSStringTypeToHMSType(query.Type) == xnametypes.HMSTypeInvalid {
+			return nil, fmt.Errorf("unknown SLS type: %q", query.Type)
+		}
+
+		if class = strings.TrimSpace(class); class != "" {
+			for _, validClass := range []sls_common.CabinetType{
+				sls_common.ClassRiver, sls_common.ClassMountain, sls_common.ClassHill,
+			} {
+				if strings.EqualFold(class, string(validClass)) {
+					query.Class = validClass
+				}
+			}
+			if query.Class == "" {
+				return nil, fmt.Errorf("unknown SLS class: %q", class)
+			}
+		}
+
+		queries = append(queries, query)
+	}
+
+	return queries, nil
+}
+
+// DecodeExtraProperties decodes the extra properties of a piece of hardware into output, such as a
+// sls_common.ComptypeMgmtSwitch.
+func DecodeExtraProperties(hardware sls_common.GenericHardware, output interface{}) error {
+	if hardware.ExtraPropertiesRaw == nil {
+		return nil
+	}
+
+	extraPropertiesBytes, err := json.Marshal(hardware.ExtraPropertiesRaw)
+	if err != nil {
+		return fmt.Errorf("failed to encode extra properties of %s: %w", hardware.Xname, err)
+	}
+
+	if err := json.Unmarshal(extraPropertiesBytes, output); err != nil {
+		return fmt.Errorf("failed to decode extra properties of %s: %w", hardware.Xname, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,262 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package sls
+
+import (
+	"slices"
+	"testing"
+
+	sls_common "github.com/Cray-HPE/hms-sls/v2/pkg/sls-common"
+)
+
+// testHardware is deliberately out of xname order so the tests catch anything that relies on the order SLS returns.
+var testHardware = []sls_common.GenericHardware{
+	{
+		Xname: "x3000c0w22j10", Parent: "x3000c0w22", Type: sls_common.MgmtSwitchConnector,
+		Class: sls_common.ClassRiver,
+		ExtraPropertiesRaw: map[string]interface{}{
+			"NodeNics": []interface{}{"x3000c0s9b0"}, "VendorName": "ethernet1/1/10",
+		},
+	},
+	{
+		Xname: "x3000c0w22", Parent: "x3000c0", Type: sls_common.MgmtSwitch, Class: sls_common.ClassRiver,
+		ExtraPropertiesRaw: map[string]interface{}{"IP4addr": "10.254.0.2", "Brand": "Dell"},
+	},
+	{
+		Xname: "x3000c0s9b0n0", Parent: "x3000c0s9b0", Type: sls_common.Node, Class: sls_common.ClassRiver,
+		ExtraPropertiesRaw: map[string]interface{}{"Role": "Compute", "NID": 1},
+	},
+	{
+		Xname: "x3000c0s7b0n0", Parent: "x3000c0s7b0", Type: sls_common.Node, Class: sls_common.ClassRiver,
+		ExtraPropertiesRaw: map[string]interface{}{"Role": "Management", "SubRole": "Master"},
+	},
+	{
+		Xname: "x3000c0w22j9", Parent: "x3000c0w22", Type: sls_common.MgmtSwitchConnector,
+		Class: sls_common.ClassRiver,
+		ExtraPropertiesRaw: map[string]interface{}{
+			"NodeNics": []interface{}{"x3000c0s7b0", "x3000c0s8b0"}, "VendorName": "ethernet1/1/9",
+		},
+	},
+	{
+		Xname: "x5000c0w1", Parent: "x5000c0", Type: sls_common.MgmtSwitch, Class: sls_common.ClassHill,
+	},
+	{
+		Xname: "x5000c0w1j1", Parent: "x5000c0w1", Type: sls_common.MgmtSwitchConnector,
+		Class:              sls_common.ClassHill,
+		ExtraPropertiesRaw: map[string]interface{}{"VendorName": "1/1/1"},
+	},
+}
+
+func xnames(hardware []sls_common.GenericHardware) []string {
+	var names []string
+	for _, item := range hardware {
+		names = append(names, item.Xname)
+	}
+
+	return names
+}
+
+// TestNewSnapshot checks every index of the snapshot, each lookup should come back in xname order.
+func TestNewSnapshot(t *testing.T) {
+	snapshot := NewSnapshot(testHardware)
+
+	tests := []struct {
+		name   string
+		lookup func() []sls_common.GenericHardware
+		want   []string
+	}{
+		{
+			name:   "all",
+			lookup: snapshot.All,
+			want: []string{
+				"x3000c0s7b0n0", "x3000c0s9b0n0", "x3000c0w22", "x3000c0w22j10", "x3000c0w22j9", "x5000c0w1",
+				"x5000c0w1j1",
+			},
+		},
+		{
+			name:   "by type",
+			lookup: func() []sls_common.GenericHardware { return snapshot.ByType(sls_common.MgmtSwitch) },
+			want:   []string{"x3000c0w22", "x5000c0w1"},
+		},
+		{
+			name:   "by class",
+			lookup: func() []sls_common.GenericHardware { return snapshot.ByClass(sls_common.ClassHill) },
+			want:   []string{"x5000c0w1", "x5000c0w1j1"},
+		},
+		{
+			name:   "by parent",
+			lookup: func() []sls_common.GenericHardware { return snapshot.ByParent("x3000c0w22") },
+			want:   []string{"x3000c0w22j10", "x3000c0w22j9"},
+		},
+		{
+			name:   "by parent with no children",
+			lookup: func() []sls_common.GenericHardware { return snapshot.ByParent("x3000c0s9b0n0") },
+		},
+		{
+			name: "switch connector by vendor name",
+			lookup: func() []sls_common.GenericHardware {
+				connector, found := snapshot.SwitchConnector("x3000c0w22", "ethernet1/1/9")
+				if !found {
+					return nil
+				}
+				return []sls_common.GenericHardware{connector}
+			},
+			want: []string{"x3000c0w22j9"},
+		},
+		{
+			name: "switch connector on another switch",
+			lookup: func() []sls_common.GenericHardware {
+				connector, found := snapshot.SwitchConnector("x5000c0w1", "ethernet1/1/9")
+				if !found {
+					return nil
+				}
+				return []sls_common.GenericHardware{connector}
+			},
+		},
+		{
+			name: "switch connectors for a NIC",
+			lookup: func() []sls_common.GenericHardware {
+				return snapshot.SwitchConnectorsForNodeNic("x3000c0s8b0")
+			},
+			want: []string{"x3000c0w22j9"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := xnames(test.lookup()); !slices.Equal(got, test.want) {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+
+	if _, found := snapshot.Get("x3000c0s9b0n0"); !found {
+		t.Errorf("Get() didn't find x3000c0s9b0n0")
+	}
+	if _, found := snapshot.Get("x9000c0s0b0n0"); found {
+		t.Errorf("Get() found x9000c0s0b0n0, which isn't in SLS")
+	}
+}
+
+func TestSearch(t *testing.T) {
+	snapshot := NewSnapshot(testHardware)
+
+	tests := []struct {
+		name  string
+		query Query
+		want  []string
+	}{
+		{
+			name:  "type",
+			query: Query{Type: sls_common.MgmtSwitchConnector},
+			want:  []string{"x3000c0w22j10", "x3000c0w22j9", "x5000c0w1j1"},
+		},
+		{
+			name:  "type and class",
+			query: Query{Type: sls_common.MgmtSwitchConnector, Class: sls_common.ClassRiver},
+			want:  []string{"x3000c0w22j10", "x3000c0w22j9"},
+		},
+		{
+			name:  "parent and type",
+			query: Query{Type: sls_common.MgmtSwitchConnector, Parent: "x5000c0w1"},
+			want:  []string{"x5000c0w1j1"},
+		},
+		{
+			name:  "extra property",
+			query: Query{Type: sls_common.Node, ExtraProperties: map[string]string{"Role": "Management"}},
+			want:  []string{"x3000c0s7b0n0"},
+		},
+		{
+			name:  "numeric extra property",
+			query: Query{ExtraProperties: map[string]string{"NID": "1"}},
+			want:  []string{"x3000c0s9b0n0"},
+		},
+		{
+			name:  "every extra property must match",
+			query: Query{ExtraProperties: map[string]string{"Role": "Management", "SubRole": "Worker"}},
+		},
+		{
+			name:  "nothing matches",
+			query: Query{Type: sls_common.CDUMgmtSwitch},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := xnames(snapshot.Search(test.query)); !slices.Equal(got, test.want) {
+				t.Errorf("Search(%+v) = %v, want %v", test.query, got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseQueries(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		want    []Query
+		wantErr bool
+	}{
+		{
+			name: "empty",
+			spec: "",
+		},
+		{
+			name: "types",
+			spec: "comptype_mgmt_switch,comptype_cdu_mgmt_switch",
+			want: []Query{{Type: sls_common.MgmtSwitch}, {Type: sls_common.CDUMgmtSwitch}},
+		},
+		{
+			name: "type and class in any case",
+			spec: " comptype_mgmt_switch:hill , comptype_hl_switch:River,",
+			want: []Query{
+				{Type: sls_common.MgmtSwitch, Class: sls_common.ClassHill},
+				{Type: sls_common.MgmtHLSwitch, Class: sls_common.ClassRiver},
+			},
+		},
+		{
+			name:    "unknown type",
+			spec:    "comptype_mgmt_switch,comptype_toaster",
+			wantErr: true,
+		},
+		{
+			name:    "unknown class",
+			spec:    "comptype_mgmt_switch:Lake",
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := ParseQueries(test.spec)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("ParseQueries(%q) error = %v, wantErr %v", test.spec, err, test.wantErr)
+			}
+			if !slices.EqualFunc(got, test.want, func(a, b Query) bool {
+				return a.Type == b.Type && a.Class == b.Class && a.Parent == b.Parent
+			}) {
+				t.Errorf("ParseQueries(%q) = %+v, want %+v", test.spec, got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package snmp_utilities
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// The OID of ipNetToPhysicalPhysAddress from IP-MIB, the MAC address of each IP address the switch has resolved,
+// indexed by ifIndex.ipNetToPhysicalNetAddressType.ipNetToPhysicalNetAddress.
+var OIDIpNetToPhysicalPhysAddress = "1.3.6.1.2.1.4.35.1.4"
+
+// The OID of ipNetToMediaPhysAddress from RFC1213-MIB, the older IPv4 only ARP table, indexed by
+// ifIndex.ipNetToMediaNetAddress.
+var OIDIpNetToMediaPhysAddress = "1.3.6.1.2.1.4.22.1.2"
+
+// The InetAddressType of an IPv4 address.
+const inetAddressTypeIPv4 = 1
+
+// walkARPTable gets the IPv4 address of every MAC address in the ARP table of the switch, keyed by MAC address
+// without punctuation like the MAC address tables. ipNetToPhysicalTable is used where the switch has it, otherwise
+// ipNetToMediaTable. Switches that don't route have nothing in either.
+func walkARPTable(walker Walker) (arpTable map[string]string, err error) {
+	arpTable, physicalErr := walkARPColumn(walker, OIDIpNetToPhysicalPhysAddress, ipNetToPhysicalAddress)
+	if physicalErr == nil && len(arpTable) > 0 {
+		return
+	}
+
+	mediaARPTable, mediaErr := walkARPColumn(walker, OIDIpNetToMediaPhysAddress, ipNetToMediaAddress)
+	switch {
+	case mediaErr == nil:
+		arpTable = mediaARPTable
+	case physicalErr != nil:
+		err = fmt.Errorf("%w, and %w", physicalErr, mediaErr)
+	}
+
+	return
+}
+
+func walkARPColumn(walker Walker, oid string,
+	address func(index []string) (net.IP, bool)) (arpTable map[string]string, err error) {
+	result, err := walker.Walk(oid)
+	if err != nil {
+		err = fmt.Errorf("failed to get ARP table: %w", err)
+		return
+	}
+
+	arpTable = map[string]string{}
+	for _, res := range result {
+		index := strings.Split(strings.TrimPrefix(res.OID, oid+"."), ".")
+		ip, ok := address(index)
+		if !ok {
+			continue
+		}
+
+		physAddress := res.octets()
+		if len(physAddress) != 6 || net.HardwareAddr(physAddress).String() == "00:00:00:00:00:00" {
+			continue
+		}
+
+		arpTable[hex.EncodeToString(physAddress)] = ip.String()
+	}
+
+	return
+}
+
+// ipNetToPhysicalAddress is the IPv4 address an ipNetToPhysicalTable row is for, the index after the ifIndex being
+// the address type, the length of the address and the address.
+func ipNetToPhysicalAddress(index []string) (net.IP, bool) {
+	if len(index) != 7 || index[1] != strconv.Itoa(inetAddressTypeIPv4) || index[2] != "4" {
+		return nil, false
+	}
+
+	return ipFromOIDParts(index[3:])
+}
+
+// ipNetToMediaAddress is the IPv4 address an ipNetToMediaTable row is for.
+func ipNetToMediaAddress(index []string) (net.IP, bool) {
+	if len(index) != 5 {
+		return nil, false
+	}
+
+	return ipFromOIDParts(index[1:])
+}
+
+func ipFromOIDParts(parts []string) (net.IP, bool) {
+	ip := net.ParseIP(strings.Join(parts, ".")).To4()
+
+	return ip, ip != nil
+}
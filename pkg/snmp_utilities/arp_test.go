@@ -0,0 +1,91 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package snmp_utilities
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWalkARPTable(t *testing.T) {
+	// ipNetToPhysicalTable rows for an IPv4 address, an IPv6 address and an incomplete entry.
+	physical := rows(OIDIpNetToPhysicalPhysAddress, "OctetString",
+		"5.1.4.10.254.1.5", "b4:2e:99:3b:70:28",
+		"5.2.16.254.128.0.0.0.0.0.0.182.46.153.255.254.59.112.41", "b4:2e:99:3b:70:29",
+		"5.1.4.10.254.1.7", "00:00:00:00:00:00")
+	media := rows(OIDIpNetToMediaPhysAddress, "OctetString",
+		"5.10.254.1.6", "b4:2e:99:3b:70:30",
+		"5.10.254.1", "b4:2e:99:3b:70:31")
+
+	tests := []struct {
+		name    string
+		walks   map[string]FixtureWalk
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name:  "ipNetToPhysicalTable",
+			walks: map[string]FixtureWalk{OIDIpNetToPhysicalPhysAddress: physical, OIDIpNetToMediaPhysAddress: media},
+			want:  map[string]string{"b42e993b7028": "10.254.1.5"},
+		},
+		{
+			name:  "ipNetToMediaTable when ipNetToPhysicalTable is empty",
+			walks: map[string]FixtureWalk{OIDIpNetToPhysicalPhysAddress: {}, OIDIpNetToMediaPhysAddress: media},
+			want:  map[string]string{"b42e993b7030": "10.254.1.6"},
+		},
+		{
+			name: "ipNetToMediaTable when ipNetToPhysicalTable isn't supported",
+			walks: map[string]FixtureWalk{
+				OIDIpNetToPhysicalPhysAddress: {Error: "NoSuchObject"}, OIDIpNetToMediaPhysAddress: media,
+			},
+			want: map[string]string{"b42e993b7030": "10.254.1.6"},
+		},
+		{
+			name: "switch that doesn't route",
+			walks: map[string]FixtureWalk{
+				OIDIpNetToPhysicalPhysAddress: {}, OIDIpNetToMediaPhysAddress: {Error: "NoSuchObject"},
+			},
+			want: map[string]string{},
+		},
+		{
+			name: "neither table",
+			walks: map[string]FixtureWalk{
+				OIDIpNetToPhysicalPhysAddress: {Error: "NoSuchObject"},
+				OIDIpNetToMediaPhysAddress:    {Error: "NoSuchObject"},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := walkARPTable(replayWalks(test.walks))
+			if (err != nil) != test.wantErr {
+				t.Fatalf("walkARPTable() error = %v, wantErr %v", err, test.wantErr)
+			}
+			if !test.wantErr && !reflect.DeepEqual(got, test.want) {
+				t.Errorf("walkARPTable() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
@@ -1,6 +1,6 @@
 // MIT License
 //
-// (C) Copyright [2021] Hewlett Packard Enterprise Development LP
+// (C) Copyright [2021,2026] Hewlett Packard Enterprise Development LP
 //
 // Permission is hereby granted, free of charge, to any person obtaining a
 // copy of this software and associated documentation files (the "Software"),
@@ -25,15 +25,21 @@ package snmp_utilities
 import (
 	"errors"
 	"fmt"
-	"github.com/k-sone/snmpgo"
-	"github.com/Cray-HPE/hms-discovery/pkg/switches"
+	"net"
 	"strconv"
 	"strings"
+	"time"
+
+	"github.com/Cray-HPE/hms-discovery/pkg/switches"
+	"github.com/gosnmp/gosnmp"
 )
 
 // The OID which has the model number of the switch
 var OIDModelNumber string = "1.3.6.1.2.1.47.1.1.1.1.13.2"
 
+// The OID of entPhysicalModelName, the model of each physical part of the switch.
+var OIDEntPhysicalModelName = "1.3.6.1.2.1.47.1.1.1.1.13"
+
 // The OID which maps ifIndexes to human-readable names
 var OIDifIndexPortNameMap string = "1.3.6.1.2.1.31.1.1.1.1"
 
@@ -61,87 +67,96 @@ var OIDAuthFailure string = "1.3.6.1.6.3.15.1.1.5.0"
 //a switches software operating-system and networking software.
 var OIDSysDescr string = "1.3.6.1.2.1.1.1.0"
 
-func GetSNMPOjbect(managementSwitch switches.ManagementSwitch) (snmp *snmpgo.SNMP, err error) {
-	// Check that the address ends in a port number (required by goSNMP).
+// Number of times to retry SNMP requests.
+var Retries uint = 5
+
+// How long to wait for a response to each SNMP request.
+var Timeout = 5 * time.Second
+
+// Number of table rows to ask for in each request of a bulk walk.
+var MaxRepetitions = 10
+
+// GetSNMPOjbect sets up the SNMP session for a management switch, it is connected the first time it is walked.
+func GetSNMPOjbect(managementSwitch switches.ManagementSwitch) (snmp *gosnmp.GoSNMP, err error) {
+	// Check that the address ends in a port number.
 	if !strings.Contains(managementSwitch.Address, ":") {
 		managementSwitch.Address = fmt.Sprintf("%s:161", managementSwitch.Address)
 	}
 
-	var securityLevel snmpgo.SecurityLevel
-	var authType snmpgo.AuthProtocol
-	var privType snmpgo.PrivProtocol
-
-	if strings.ToLower(managementSwitch.SNMPAuthProtocol) == "none" {
-		securityLevel = snmpgo.NoAuthNoPriv
-	} else if strings.ToLower(managementSwitch.SNMPPrivProtocol) == "none" {
-		securityLevel = snmpgo.AuthNoPriv
-	} else {
-		securityLevel = snmpgo.AuthPriv
+	host, portString, err := net.SplitHostPort(managementSwitch.Address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid switch address: %w", err)
 	}
-
-	if securityLevel != snmpgo.NoAuthNoPriv {
-		if strings.ToLower(managementSwitch.SNMPAuthProtocol) == "md5" {
-			authType = snmpgo.Md5
-		} else if strings.ToLower(managementSwitch.SNMPAuthProtocol) == "sha" {
-			authType = snmpgo.Sha
-		}
+	port, err := strconv.ParseUint(portString, 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("invalid switch port: %w", err)
 	}
 
-	if securityLevel == snmpgo.AuthPriv {
-		if strings.ToLower(managementSwitch.SNMPPrivProtocol) == "aes" {
-			privType = snmpgo.Aes
-		} else if strings.ToLower(managementSwitch.SNMPPrivProtocol) == "des" {
-			privType = snmpgo.Des
-		}
+	snmp = &gosnmp.GoSNMP{
+		Target:         host,
+		Port:           uint16(port),
+		Transport:      "udp",
+		Retries:        int(Retries),
+		Timeout:        Timeout,
+		MaxOids:        gosnmp.MaxOids,
+		MaxRepetitions: uint32(MaxRepetitions),
 	}
 
-	snmp, err = snmpgo.NewSNMP(snmpgo.SNMPArguments{
-		Version:       snmpgo.V3,
-		Address:       managementSwitch.Address,
-		Retries:       5,
-		UserName:      managementSwitch.SNMPUser,
-		SecurityLevel: securityLevel,
-		AuthPassword:  managementSwitch.SNMPAuthPassword,
-		AuthProtocol:  authType,
-		PrivPassword:  managementSwitch.SNMPPrivPassword,
-		PrivProtocol:  privType,
-	})
+	if managementSwitch.SNMPVersion == switches.SNMPVersion2c {
+		snmp.Version = gosnmp.Version2c
+		snmp.Community = managementSwitch.SNMPCommunity
 
-	return
-}
-
-func snmpGetBulk(snmp *snmpgo.SNMP, oid string) (snmpgo.Pdu, error) {
-	oids, err := snmpgo.NewOids([]string{oid})
-	if err != nil {
-		return nil, err
+		return snmp, nil
 	}
 
-	err = snmp.Open()
+	authProtocol, err := ParseAuthProtocol(managementSwitch.SNMPAuthProtocol)
 	if err != nil {
 		return nil, err
 	}
-	defer snmp.Close()
 
-	result, err := snmp.GetBulkWalk(oids, 0, 10)
-	if err != nil {
-		return nil, err
+	var msgFlags gosnmp.SnmpV3MsgFlags
+	privProtocol := gosnmp.NoPriv
+	if authProtocol == gosnmp.NoAuth {
+		msgFlags = gosnmp.NoAuthNoPriv
+	} else {
+		privProtocol, err = ParsePrivProtocol(managementSwitch.SNMPPrivProtocol)
+		if err != nil {
+			return nil, err
+		}
+
+		if privProtocol == gosnmp.NoPriv {
+			msgFlags = gosnmp.AuthNoPriv
+		} else {
+			msgFlags = gosnmp.AuthPriv
+		}
 	}
 
-	if result.ErrorStatus() != snmpgo.NoError {
-		return nil, errors.New(result.ErrorStatus().String())
+	snmp.Version = gosnmp.Version3
+	snmp.SecurityModel = gosnmp.UserSecurityModel
+	snmp.MsgFlags = msgFlags
+	snmp.SecurityParameters = &gosnmp.UsmSecurityParameters{
+		UserName:                 managementSwitch.SNMPUser,
+		AuthenticationProtocol:   authProtocol,
+		AuthenticationPassphrase: managementSwitch.SNMPAuthPassword,
+		PrivacyProtocol:          privProtocol,
+		PrivacyPassphrase:        managementSwitch.SNMPPrivPassword,
 	}
 
-	return result, nil
+	return snmp, nil
 }
 
-func getDynamicMacs(snmp *snmpgo.SNMP, useVLANs bool) (macPortMap map[string]int, err error) {
+func getDynamicMacs(walker Walker, fdbTable FDBTable) (macPortMap map[string]int, err error) {
 	var portSrc string
-	if useVLANs {
+	switch fdbTable {
+	case FDBTableDot1q:
 		portSrc = OIDMacAddressesWithVLAN
-	} else {
+	case FDBTableDot1d:
 		portSrc = OIDMACAddressesNoVLAN
+	default:
+		err = fmt.Errorf("unknown FDB table: %s", fdbTable)
+		return
 	}
-	port, bulkErr := snmpGetBulk(snmp, portSrc)
+	port, bulkErr := walker.Walk(portSrc)
 	if bulkErr != nil {
 		err = fmt.Errorf("unable to get port src: %w", bulkErr)
 		return
@@ -149,22 +164,23 @@ func getDynamicMacs(snmp *snmpgo.SNMP, useVLANs bool) (macPortMap map[string]int
 
 	// Process the MAC->port list into a map.
 	macPortMap = make(map[string]int)
-	for _, portEntry := range port.VarBinds() {
-		portMac, conversionErr := MacAddressFromOID(portEntry.Oid.String())
+	for _, portEntry := range port {
+		portMac, conversionErr := MacAddressFromOID(portEntry.OID)
 		if conversionErr != nil {
 			err = fmt.Errorf("failed to parse OID (%s) into MAC address: %w",
-				portEntry.Oid.String(), conversionErr)
+				portEntry.OID, conversionErr)
 			continue
 		}
 
-		portNum, conversionErr := portEntry.Variable.BigInt()
+		portNum, conversionErr := portEntry.Int()
 		if conversionErr != nil {
 			err = fmt.Errorf("failed to turn port number (%s) into an integer: %w",
-				portEntry.Variable.String(), conversionErr)
+				portEntry.Value, conversionErr)
+			continue
 		}
 
-		if int((*portNum).Int64()) != 0 {
-			macPortMap[portMac] = int((*portNum).Int64())
+		if portNum != 0 {
+			macPortMap[portMac] = portNum
 		}
 	}
 
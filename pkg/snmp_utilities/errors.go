@@ -0,0 +1,157 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package snmp_utilities
+
+import (
+	"errors"
+	"net"
+	"os"
+	"strings"
+	"syscall"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// ErrorClass is the kind of problem walking a switch ran into, so that a switch with the wrong credentials can be
+// told apart from one that is down.
+type ErrorClass string
+
+const (
+	ErrorClassNone ErrorClass = ""
+	// ErrorClassConfiguration is a switch that can't be walked with what SLS and Vault have for it, such as an
+	// unsupported protocol or a bad address.
+	ErrorClassConfiguration ErrorClass = "configuration"
+	// ErrorClassTimeout is a switch that didn't answer. SNMPv2c switches don't answer a wrong community either.
+	ErrorClassTimeout ErrorClass = "timeout"
+	// ErrorClassUnreachable is a switch that couldn't be sent anything, or refused it.
+	ErrorClassUnreachable ErrorClass = "unreachable"
+	// ErrorClassCredentials is a switch that doesn't know the SNMPv3 user, or has different passwords or protocols.
+	ErrorClassCredentials ErrorClass = "credentials"
+	// ErrorClassUnknownEngineID is a switch whose SNMPv3 engine ID or engine time couldn't be agreed on.
+	ErrorClassUnknownEngineID ErrorClass = "unknown_engine_id"
+	// ErrorClassEmptyTable is a switch that answered but had nothing in a table it must have something in, which is
+	// usually an SNMP view that doesn't include it.
+	ErrorClassEmptyTable ErrorClass = "empty_table"
+	ErrorClassOther      ErrorClass = "other"
+)
+
+// ErrEmptyTable is returned for tables every switch has rows in that came back empty.
+var ErrEmptyTable = errors.New("table is empty")
+
+// The usmStats counters a switch reports instead of answering an SNMPv3 request it can't accept, OIDAuthFailure
+// being usmStatsWrongDigests.
+var (
+	OIDUnsupportedSecLevel = "1.3.6.1.6.3.15.1.1.1.0"
+	OIDNotInTimeWindow     = "1.3.6.1.6.3.15.1.1.2.0"
+	OIDUnknownUserName     = "1.3.6.1.6.3.15.1.1.3.0"
+	OIDUnknownEngineID     = "1.3.6.1.6.3.15.1.1.4.0"
+	OIDDecryptionError     = "1.3.6.1.6.3.15.1.1.6.0"
+)
+
+var usmStatsErrors = map[string]error{
+	OIDUnsupportedSecLevel: gosnmp.ErrUnknownSecurityLevel,
+	OIDNotInTimeWindow:     gosnmp.ErrNotInTimeWindow,
+	OIDUnknownUserName:     gosnmp.ErrUnknownUsername,
+	OIDUnknownEngineID:     gosnmp.ErrUnknownEngineID,
+	OIDAuthFailure:         gosnmp.ErrWrongDigest,
+	OIDDecryptionError:     gosnmp.ErrDecryption,
+}
+
+// reportError is the error a walk that came back with nothing but a usmStats counter should have failed with.
+// gosnmp returns these as errors itself, but walks recorded with the library used before have them as results.
+func reportError(varBinds []VarBind) error {
+	if len(varBinds) != 1 {
+		return nil
+	}
+
+	return usmStatsErrors[varBinds[0].OID]
+}
+
+var errorClasses = []struct {
+	class  ErrorClass
+	errors []error
+}{
+	{ErrorClassEmptyTable, []error{ErrEmptyTable}},
+	{ErrorClassCredentials, []error{gosnmp.ErrUnknownUsername, gosnmp.ErrWrongDigest, gosnmp.ErrDecryption,
+		gosnmp.ErrUnknownSecurityLevel}},
+	{ErrorClassUnknownEngineID, []error{gosnmp.ErrUnknownEngineID, gosnmp.ErrNotInTimeWindow}},
+	{ErrorClassTimeout, []error{os.ErrDeadlineExceeded}},
+	{ErrorClassUnreachable, []error{syscall.ECONNREFUSED, syscall.EHOSTUNREACH, syscall.ENETUNREACH}},
+}
+
+// Messages that give away the class of errors that have been turned into text, such as those played back from a
+// fixture, and of the errors gosnmp makes up itself.
+var errorClassMessages = []struct {
+	class    ErrorClass
+	messages []string
+}{
+	{ErrorClassTimeout, []string{"timeout"}},
+	{ErrorClassUnreachable, []string{"connection refused", "no route to host", "network is unreachable",
+		"no such host"}},
+}
+
+// ClassifyError works out what kind of problem err is.
+func ClassifyError(err error) ErrorClass {
+	if err == nil {
+		return ErrorClassNone
+	}
+
+	for _, errorClass := range errorClasses {
+		for _, classErr := range errorClass.errors {
+			if errors.Is(err, classErr) || strings.Contains(err.Error(), classErr.Error()) {
+				return errorClass.class
+			}
+		}
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return ErrorClassTimeout
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return ErrorClassUnreachable
+	}
+
+	for _, errorClass := range errorClassMessages {
+		for _, message := range errorClass.messages {
+			if strings.Contains(err.Error(), message) {
+				return errorClass.class
+			}
+		}
+	}
+
+	return ErrorClassOther
+}
+
+// Unusable is whether a switch that had the error can't be walked at all, so there is no point walking the rest of its
+// tables.
+func (class ErrorClass) Unusable() bool {
+	switch class {
+	case ErrorClassTimeout, ErrorClassUnreachable, ErrorClassCredentials, ErrorClassUnknownEngineID:
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,110 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package snmp_utilities
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name         string
+		err          error
+		want         ErrorClass
+		wantUnusable bool
+	}{
+		{name: "no error", err: nil, want: ErrorClassNone},
+		{name: "empty table", err: fmt.Errorf("ifName %w", ErrEmptyTable), want: ErrorClassEmptyTable},
+		{name: "wrong digest", err: fmt.Errorf("failed to get ifName: %w", gosnmp.ErrWrongDigest),
+			want: ErrorClassCredentials, wantUnusable: true},
+		{name: "unknown user played back as text", err: errors.New(gosnmp.ErrUnknownUsername.Error()),
+			want: ErrorClassCredentials, wantUnusable: true},
+		{name: "unknown engine ID", err: gosnmp.ErrUnknownEngineID, want: ErrorClassUnknownEngineID,
+			wantUnusable: true},
+		{name: "not in time window", err: gosnmp.ErrNotInTimeWindow, want: ErrorClassUnknownEngineID,
+			wantUnusable: true},
+		{name: "deadline exceeded", err: fmt.Errorf("walk: %w", os.ErrDeadlineExceeded), want: ErrorClassTimeout,
+			wantUnusable: true},
+		{name: "gosnmp timeout", err: errors.New("request timeout (after 3 retries)"), want: ErrorClassTimeout,
+			wantUnusable: true},
+		{name: "connection refused", err: &net.OpError{Op: "read", Net: "udp", Err: syscall.ECONNREFUSED},
+			want: ErrorClassUnreachable, wantUnusable: true},
+		{name: "unknown host", err: &net.DNSError{Err: "server misbehaving", Name: "sw-leaf-bmc-001"},
+			want: ErrorClassUnreachable, wantUnusable: true},
+		{name: "DNS timeout", err: &net.DNSError{Err: "i/o", Name: "sw-leaf-bmc-001", IsTimeout: true},
+			want: ErrorClassTimeout, wantUnusable: true},
+		{name: "anything else", err: errors.New("NoSuchName"), want: ErrorClassOther},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := ClassifyError(test.err)
+			if got != test.want {
+				t.Errorf("ClassifyError(%v) = %q, want %q", test.err, got, test.want)
+			}
+			if got.Unusable() != test.wantUnusable {
+				t.Errorf("%q.Unusable() = %v, want %v", got, got.Unusable(), test.wantUnusable)
+			}
+		})
+	}
+}
+
+// TestReplayedErrorClass checks walks played back from a fixture are classified the same as when they were recorded.
+func TestReplayedErrorClass(t *testing.T) {
+	replay := replayWalks(map[string]FixtureWalk{
+		OIDifIndexPortNameMap: rows(OIDifIndexPortNameMap, "OctetString", "1", "1/1/1"),
+		OIDifType:             {Error: "request timeout (after 3 retries)"},
+		OIDSysDescr:           {VarBinds: []VarBind{{OID: OIDAuthFailure, Type: "Counter32", Value: "12"}}},
+	})
+
+	tests := []struct {
+		name      string
+		oid       string
+		wantClass ErrorClass
+	}{
+		{name: "recorded rows", oid: OIDifIndexPortNameMap, wantClass: ErrorClassNone},
+		{name: "recorded error", oid: OIDifType, wantClass: ErrorClassTimeout},
+		{name: "usmStats report", oid: OIDSysDescr, wantClass: ErrorClassCredentials},
+		{name: "never walked", oid: OIDifStackStatus, wantClass: ErrorClassOther},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := replay.Walk(test.oid)
+			if class := ClassifyError(err); class != test.wantClass {
+				t.Errorf("Walk(%s) error = %v, class %q, want %q", test.oid, err, class, test.wantClass)
+			}
+		})
+	}
+
+	if _, err := replay.Walk(OIDSysDescr); err != gosnmp.ErrWrongDigest {
+		t.Errorf("usmStatsWrongDigests report played back as %v, want %v", err, gosnmp.ErrWrongDigest)
+	}
+}
@@ -0,0 +1,260 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package snmp_utilities
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+/*
+A fixture is every raw walk made of one switch, kept in <directory>/<switch xname>.json. RecordingSNMP captures them
+from a real switch and ReplaySNMP plays them back, so a capture from a site can be run through discovery anywhere:
+
+	{
+	  "Version": 1,
+	  "Switch": "x3000c0w14",
+	  "Recorded": "2026-01-02T03:04:05Z",
+	  "Walks": {
+	    "1.3.6.1.2.1.31.1.1.1.1": {
+	      "VarBinds": [{"OID": "1.3.6.1.2.1.31.1.1.1.1.1", "Type": "OctetString", "Value": "1/1/1"}]
+	    },
+	    "1.3.6.1.2.1.17.4.3.1.2": {
+	      "Error": "NoSuchName"
+	    }
+	  }
+	}
+
+Walks that failed are kept along with their error so replaying them fails the same way.
+*/
+
+// FixtureVersion is the version of the fixture format written by RecordingSNMP, and the newest ReplaySNMP can read.
+const FixtureVersion = 1
+
+// FixtureWalk is what came back from one walk.
+type FixtureWalk struct {
+	VarBinds []VarBind `json:"VarBinds,omitempty"`
+	Error    string    `json:"Error,omitempty"`
+}
+
+// Fixture is every walk made of one switch.
+type Fixture struct {
+	Version  int                    `json:"Version"`
+	Switch   string                 `json:"Switch"`
+	Recorded time.Time              `json:"Recorded"`
+	Walks    map[string]FixtureWalk `json:"Walks"`
+}
+
+// FixturePath is where the fixture for a switch is kept in directory.
+func FixturePath(directory, switchXname string) string {
+	return filepath.Join(directory, switchXname+".json")
+}
+
+// ReadFixture reads the fixture for a switch from directory.
+func ReadFixture(directory, switchXname string) (fixture Fixture, err error) {
+	fixtureBytes, err := os.ReadFile(FixturePath(directory, switchXname))
+	if err != nil {
+		return
+	}
+
+	if err = json.Unmarshal(fixtureBytes, &fixture); err != nil {
+		err = fmt.Errorf("failed to decode SNMP fixture for %s: %w", switchXname, err)
+		return
+	}
+
+	if fixture.Version < 1 || fixture.Version > FixtureVersion {
+		err = fmt.Errorf("SNMP fixture for %s is version %d, only versions up to %d are supported",
+			switchXname, fixture.Version, FixtureVersion)
+	}
+
+	return
+}
+
+// WriteFile writes the fixture into directory, replacing the file in one go so a reader never sees half of it.
+func (fixture Fixture) WriteFile(directory string) error {
+	fixtureBytes, err := json.MarshalIndent(fixture, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(directory, 0755); err != nil {
+		return err
+	}
+
+	path := FixturePath(directory, fixture.Switch)
+	tempPath := path + ".tmp"
+	if err := os.WriteFile(tempPath, fixtureBytes, 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tempPath, path)
+}
+
+// RecordingSNMP passes every walk through to Walker, writing what it got back to the switch's fixture in Directory
+// as it goes.
+type RecordingSNMP struct {
+	Walker    Walker
+	Directory string
+
+	lock    sync.Mutex
+	fixture Fixture
+}
+
+func NewRecordingSNMP(walker Walker, switchXname, directory string) *RecordingSNMP {
+	return &RecordingSNMP{
+		Walker:    walker,
+		Directory: directory,
+		fixture: Fixture{
+			Version:  FixtureVersion,
+			Switch:   switchXname,
+			Recorded: time.Now().UTC(),
+			Walks:    map[string]FixtureWalk{},
+		},
+	}
+}
+
+// Walk does the walk, records it and returns the result unchanged. Failing to record it is only an error when the
+// walk itself worked.
+func (snmpInterface *RecordingSNMP) Walk(oid string) ([]VarBind, error) {
+	varBinds, walkErr := snmpInterface.Walker.Walk(oid)
+
+	walk := FixtureWalk{VarBinds: varBinds}
+	if walkErr != nil {
+		walk.Error = walkErr.Error()
+	}
+
+	snmpInterface.lock.Lock()
+	snmpInterface.fixture.Walks[oid] = walk
+	writeErr := snmpInterface.fixture.WriteFile(snmpInterface.Directory)
+	snmpInterface.lock.Unlock()
+
+	if writeErr != nil {
+		writeErr = fmt.Errorf("failed to record SNMP walk of %s: %w", oid, writeErr)
+	}
+	if walkErr != nil {
+		return nil, walkErr
+	}
+
+	return varBinds, writeErr
+}
+
+// Close closes Walker if it needs to be.
+func (snmpInterface *RecordingSNMP) Close() error {
+	if closer, ok := snmpInterface.Walker.(io.Closer); ok {
+		return closer.Close()
+	}
+
+	return nil
+}
+
+func (snmpInterface *RecordingSNMP) GetPortMap() (portMap map[int]string, err error) {
+	return walkPortMap(snmpInterface)
+}
+
+func (snmpInterface *RecordingSNMP) GetPortNumberMap() (portNumberMap map[int]int, err error) {
+	return walkPortNumberMap(snmpInterface)
+}
+
+func (snmpInterface *RecordingSNMP) GetMACPortNameTable(portNumberIfIndexMap map[int]int,
+	ifIndexPortNameMap map[int]string, fdbTables []FDBTable) (macPortMap map[string]string, err error) {
+	return walkMACPortNameTable(snmpInterface, portNumberIfIndexMap, ifIndexPortNameMap, fdbTables)
+}
+
+func (snmpInterface *RecordingSNMP) GetLLDPNeighbors(ifIndexPortNameMap map[int]string) (
+	neighbors []LLDPNeighbor, err error) {
+	return walkLLDPNeighbors(snmpInterface, ifIndexPortNameMap)
+}
+
+func (snmpInterface *RecordingSNMP) GetLAGMembers(ifIndexPortNameMap map[int]string) (
+	lagMembers map[string][]string, err error) {
+	return walkLAGMembers(snmpInterface, ifIndexPortNameMap)
+}
+
+func (snmpInterface *RecordingSNMP) GetARPTable() (arpTable map[string]string, err error) {
+	return walkARPTable(snmpInterface)
+}
+
+// ReplaySNMP plays back the walks recorded for a switch instead of contacting it.
+type ReplaySNMP struct {
+	Fixture Fixture
+}
+
+// NewReplaySNMP reads the fixture for a switch from directory to play back.
+func NewReplaySNMP(switchXname, directory string) (ReplaySNMP, error) {
+	fixture, err := ReadFixture(directory, switchXname)
+	if err != nil {
+		return ReplaySNMP{}, err
+	}
+
+	return ReplaySNMP{Fixture: fixture}, nil
+}
+
+func (snmpInterface ReplaySNMP) Walk(oid string) ([]VarBind, error) {
+	walk, found := snmpInterface.Fixture.Walks[oid]
+	if !found {
+		return nil, fmt.Errorf("no walk of %s recorded for %s", oid, snmpInterface.Fixture.Switch)
+	}
+
+	if walk.Error != "" {
+		return nil, errors.New(walk.Error)
+	}
+
+	if err := reportError(walk.VarBinds); err != nil {
+		return nil, err
+	}
+
+	return walk.VarBinds, nil
+}
+
+func (snmpInterface ReplaySNMP) GetPortMap() (portMap map[int]string, err error) {
+	return walkPortMap(snmpInterface)
+}
+
+func (snmpInterface ReplaySNMP) GetPortNumberMap() (portNumberMap map[int]int, err error) {
+	return walkPortNumberMap(snmpInterface)
+}
+
+func (snmpInterface ReplaySNMP) GetMACPortNameTable(portNumberIfIndexMap map[int]int,
+	ifIndexPortNameMap map[int]string, fdbTables []FDBTable) (macPortMap map[string]string, err error) {
+	return walkMACPortNameTable(snmpInterface, portNumberIfIndexMap, ifIndexPortNameMap, fdbTables)
+}
+
+func (snmpInterface ReplaySNMP) GetLLDPNeighbors(ifIndexPortNameMap map[int]string) (neighbors []LLDPNeighbor,
+	err error) {
+	return walkLLDPNeighbors(snmpInterface, ifIndexPortNameMap)
+}
+
+func (snmpInterface ReplaySNMP) GetLAGMembers(ifIndexPortNameMap map[int]string) (lagMembers map[string][]string,
+	err error) {
+	return walkLAGMembers(snmpInterface, ifIndexPortNameMap)
+}
+
+func (snmpInterface ReplaySNMP) GetARPTable() (arpTable map[string]string, err error) {
+	return walkARPTable(snmpInterface)
+}
@@ -0,0 +1,118 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package snmp_utilities
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// replayWalks plays back walks as though they were recorded from a switch.
+func replayWalks(walks map[string]FixtureWalk) ReplaySNMP {
+	return ReplaySNMP{Fixture: Fixture{Version: FixtureVersion, Switch: "x3000c0w14", Walks: walks}}
+}
+
+// rows builds the VarBinds of a walk of oid, each row being the index after oid and the value.
+func rows(oid, varBindType string, indexValues ...string) FixtureWalk {
+	var walk FixtureWalk
+	for i := 0; i+1 < len(indexValues); i += 2 {
+		walk.VarBinds = append(walk.VarBinds, VarBind{
+			OID:   oid + "." + indexValues[i],
+			Type:  varBindType,
+			Value: indexValues[i+1],
+		})
+	}
+
+	return walk
+}
+
+// TestReplaySNMPWalk checks walks fail on replay the same way they did when they were recorded.
+func TestReplaySNMPWalk(t *testing.T) {
+	replay := replayWalks(map[string]FixtureWalk{
+		OIDifIndexPortNameMap: rows(OIDifIndexPortNameMap, "OctetString", "1", "1/1/1"),
+		OIDPortNumberifIndex:  {Error: "request timeout (after 3 retries)"},
+	})
+
+	tests := []struct {
+		name     string
+		oid      string
+		wantRows int
+		wantErr  string
+	}{
+		{name: "recorded rows", oid: OIDifIndexPortNameMap, wantRows: 1},
+		{name: "recorded error", oid: OIDPortNumberifIndex, wantErr: "request timeout (after 3 retries)"},
+		{name: "never walked", oid: OIDModelNumber, wantErr: "no walk of " + OIDModelNumber},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			varBinds, err := replay.Walk(test.oid)
+			if len(varBinds) != test.wantRows {
+				t.Errorf("Walk(%s) returned %d rows, want %d", test.oid, len(varBinds), test.wantRows)
+			}
+
+			var errText string
+			if err != nil {
+				errText = err.Error()
+			}
+			if !strings.HasPrefix(errText, test.wantErr) || (err == nil) != (test.wantErr == "") {
+				t.Errorf("Walk(%s) error = %v, want %q", test.oid, err, test.wantErr)
+			}
+		})
+	}
+}
+
+// TestRecordingSNMP records walks of a switch to a fixture and checks they play back the same.
+func TestRecordingSNMP(t *testing.T) {
+	directory := t.TempDir()
+	walks := map[string]FixtureWalk{
+		OIDifIndexPortNameMap: rows(OIDifIndexPortNameMap, "OctetString", "1", "1/1/1", "2", "1/1/2"),
+		OIDPortNumberifIndex:  {Error: "request timeout (after 3 retries)"},
+	}
+
+	recording := NewRecordingSNMP(replayWalks(walks), "x3000c0w14", directory)
+	for oid := range walks {
+		recording.Walk(oid)
+	}
+
+	replay, err := NewReplaySNMP("x3000c0w14", directory)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for oid, walk := range walks {
+		varBinds, err := replay.Walk(oid)
+		if !slices.Equal(varBinds, walk.VarBinds) {
+			t.Errorf("Walk(%s) played back %v, want %v", oid, varBinds, walk.VarBinds)
+		}
+
+		var errText string
+		if err != nil {
+			errText = err.Error()
+		}
+		if errText != walk.Error {
+			t.Errorf("Walk(%s) played back error %q, want %q", oid, errText, walk.Error)
+		}
+	}
+}
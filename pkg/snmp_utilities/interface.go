@@ -1,6 +1,6 @@
 // MIT License
 //
-// (C) Copyright [2021] Hewlett Packard Enterprise Development LP
+// (C) Copyright [2021,2026] Hewlett Packard Enterprise Development LP
 //
 // Permission is hereby granted, free of charge, to any person obtaining a
 // copy of this software and associated documentation files (the "Software"),
@@ -22,21 +22,28 @@
 
 package snmp_utilities
 
-import "github.com/k-sone/snmpgo"
+import "github.com/gosnmp/gosnmp"
 
 // Generic mock interface for testing.
 type MockSNMP struct {
 	SwitchXname string
+	// Directory the canned switch data is read from, configs when empty.
+	Directory string
 }
 
 // Real interface.
 type RealSNMP struct {
-	SNMP *snmpgo.SNMP
+	SNMP *gosnmp.GoSNMP
 }
 
+// SNMPInterface gets the tables needed to work out which port of a switch each MAC address is on. Implementations
+// that hold a session with the switch also implement io.Closer, to be closed once the tables have been gotten.
 type SNMPInterface interface {
 	GetPortMap() (portMap map[int]string, err error)
 	GetPortNumberMap() (portNumberMap map[int]int, err error)
-	GetMACPortNameTable(portNumberIfIndexMap map[int]int, ifIndexPortNameMap map[int]string) (
-		macPortMap map[string]string, err error)
+	GetMACPortNameTable(portNumberIfIndexMap map[int]int, ifIndexPortNameMap map[int]string,
+		fdbTables []FDBTable) (macPortMap map[string]string, err error)
+	GetLLDPNeighbors(ifIndexPortNameMap map[int]string) (neighbors []LLDPNeighbor, err error)
+	GetLAGMembers(ifIndexPortNameMap map[int]string) (lagMembers map[string][]string, err error)
+	GetARPTable() (arpTable map[string]string, err error)
 }
@@ -0,0 +1,184 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package snmp_utilities
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// The OID of dot3adAggPortAttachedAggID from IEEE8023-LAG-MIB, the ifIndex of the aggregator each port is a member
+// of, indexed by the ifIndex of the port.
+var OIDDot3adAggPortAttachedAggID = "1.2.840.10006.300.43.1.2.1.1.13"
+
+// The OID of ifType from IF-MIB.
+var OIDifType = "1.3.6.1.2.1.2.2.1.3"
+
+// The OID of ifStackStatus from IF-MIB, indexed by ifStackHigherLayer.ifStackLowerLayer.
+var OIDifStackStatus = "1.3.6.1.2.1.31.1.2.1.3"
+
+// The ifType of link aggregations, ieee8023adLag.
+const ifTypeLAG = 161
+
+// The ifStackStatus of a layer that is in use, active.
+const ifStackStatusActive = 1
+
+// walkLAGMembers gets the member ports of every link aggregation on the switch, keyed by the name of the
+// aggregation. IEEE8023-LAG-MIB is used where the switch has it, otherwise the aggregations are found by ifType and
+// their members in the ifStackTable, which is how Aruba AOS-CX and some NX-OS releases make them available.
+func walkLAGMembers(walker Walker, ifIndexPortNameMap map[int]string) (lagMembers map[string][]string, err error) {
+	members, lagErr := walkDot3adAggMembers(walker)
+	if lagErr != nil || len(members) == 0 {
+		members, err = walkIfStackLAGMembers(walker)
+		if err != nil {
+			if lagErr != nil {
+				err = fmt.Errorf("%w, and %w", lagErr, err)
+			}
+			return
+		}
+	}
+
+	lagMembers = map[string][]string{}
+	for memberIfIndex, lagIfIndex := range members {
+		lagName, found := ifIndexPortNameMap[lagIfIndex]
+		if !found {
+			err = fmt.Errorf("failed to map LAG ifIndex (%d) to port name", lagIfIndex)
+			return
+		}
+
+		memberName, found := ifIndexPortNameMap[memberIfIndex]
+		if !found {
+			err = fmt.Errorf("failed to map LAG member ifIndex (%d) to port name", memberIfIndex)
+			return
+		}
+
+		lagMembers[lagName] = append(lagMembers[lagName], memberName)
+	}
+
+	for _, memberNames := range lagMembers {
+		sort.Strings(memberNames)
+	}
+
+	return
+}
+
+// walkDot3adAggMembers maps the ifIndex of every port attached to an aggregator to the ifIndex of the aggregator.
+func walkDot3adAggMembers(walker Walker) (members map[int]int, err error) {
+	result, err := walker.Walk(OIDDot3adAggPortAttachedAggID)
+	if err != nil {
+		err = fmt.Errorf("failed to get dot3adAggPortAttachedAggID: %w", err)
+		return
+	}
+
+	members = map[int]int{}
+	for _, res := range result {
+		memberIfIndex, convertErr := res.lastOIDPart()
+		if convertErr != nil {
+			err = fmt.Errorf("failed to convert dot3adAggPortIndex to integer: %w", convertErr)
+			return
+		}
+
+		lagIfIndex, convertErr := res.Int()
+		if convertErr != nil {
+			err = convertErr
+			return
+		}
+
+		// Ports that aren't attached to an aggregator have it as 0.
+		if lagIfIndex != 0 {
+			members[memberIfIndex] = lagIfIndex
+		}
+	}
+
+	return
+}
+
+// walkIfStackLAGMembers maps the ifIndex of every port stacked under an interface with the LAG ifType to the ifIndex
+// of that interface.
+func walkIfStackLAGMembers(walker Walker) (members map[int]int, err error) {
+	ifTypes, err := walker.Walk(OIDifType)
+	if err != nil {
+		err = fmt.Errorf("failed to get ifType: %w", err)
+		return
+	}
+
+	lagIfIndexes := map[int]bool{}
+	for _, res := range ifTypes {
+		ifIndex, convertErr := res.lastOIDPart()
+		if convertErr != nil {
+			err = fmt.Errorf("failed to convert ifIndex to integer: %w", convertErr)
+			return
+		}
+
+		if ifType, convertErr := res.Int(); convertErr == nil && ifType == ifTypeLAG {
+			lagIfIndexes[ifIndex] = true
+		}
+	}
+
+	stack, err := walker.Walk(OIDifStackStatus)
+	if err != nil {
+		err = fmt.Errorf("failed to get ifStackStatus: %w", err)
+		return
+	}
+
+	members = map[int]int{}
+	for _, res := range stack {
+		higherIfIndex, lowerIfIndex, convertErr := ifStackLayers(res.OID)
+		if convertErr != nil {
+			err = convertErr
+			return
+		}
+
+		if status, convertErr := res.Int(); convertErr != nil || status != ifStackStatusActive {
+			continue
+		}
+
+		if lagIfIndexes[higherIfIndex] && lowerIfIndex != 0 {
+			members[lowerIfIndex] = higherIfIndex
+		}
+	}
+
+	return
+}
+
+// ifStackLayers are the ifStackHigherLayer and ifStackLowerLayer an ifStackTable row is indexed by.
+func ifStackLayers(oid string) (higherIfIndex, lowerIfIndex int, err error) {
+	oidParts := strings.Split(oid, ".")
+	if len(oidParts) < 2 {
+		err = fmt.Errorf("OID (%s) is not an ifStackTable row", oid)
+		return
+	}
+
+	if higherIfIndex, err = strconv.Atoi(oidParts[len(oidParts)-2]); err != nil {
+		err = fmt.Errorf("failed to convert ifStackHigherLayer to integer: %w", err)
+		return
+	}
+
+	if lowerIfIndex, err = strconv.Atoi(oidParts[len(oidParts)-1]); err != nil {
+		err = fmt.Errorf("failed to convert ifStackLowerLayer to integer: %w", err)
+	}
+
+	return
+}
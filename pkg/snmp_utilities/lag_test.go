@@ -0,0 +1,108 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package snmp_utilities
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWalkLAGMembers(t *testing.T) {
+	ifIndexPortNameMap := map[int]string{1: "1/1/1", 2: "1/1/2", 3: "1/1/3", 100: "lag100", 200: "vlan200"}
+
+	// An AOS-CX style ifTable and ifStackTable, with lag100 on 1/1/1 and 1/1/2 and the ports stacked under a VLAN
+	// that isn't a LAG.
+	ifTypes := rows(OIDifType, "Integer", "1", "6", "2", "6", "3", "6", "100", "161", "200", "136")
+	ifStack := rows(OIDifStackStatus, "Integer",
+		"100.1", "1", "100.2", "1", "100.3", "2", "100.0", "1", "200.1", "1", "200.3", "1", "0.100", "1")
+
+	tests := []struct {
+		name    string
+		walks   map[string]FixtureWalk
+		want    map[string][]string
+		wantErr bool
+	}{
+		{
+			name: "IEEE8023-LAG-MIB",
+			walks: map[string]FixtureWalk{
+				OIDDot3adAggPortAttachedAggID: rows(OIDDot3adAggPortAttachedAggID, "Integer",
+					"2", "100", "1", "100", "3", "0"),
+			},
+			want: map[string][]string{"lag100": {"1/1/1", "1/1/2"}},
+		},
+		{
+			name: "ifStackTable when IEEE8023-LAG-MIB is empty",
+			walks: map[string]FixtureWalk{
+				OIDDot3adAggPortAttachedAggID: {},
+				OIDifType:                     ifTypes,
+				OIDifStackStatus:              ifStack,
+			},
+			want: map[string][]string{"lag100": {"1/1/1", "1/1/2"}},
+		},
+		{
+			name: "ifStackTable when IEEE8023-LAG-MIB isn't supported",
+			walks: map[string]FixtureWalk{
+				OIDDot3adAggPortAttachedAggID: {Error: "NoSuchObject"},
+				OIDifType:                     ifTypes,
+				OIDifStackStatus:              ifStack,
+			},
+			want: map[string][]string{"lag100": {"1/1/1", "1/1/2"}},
+		},
+		{
+			name: "no LAGs",
+			walks: map[string]FixtureWalk{
+				OIDDot3adAggPortAttachedAggID: rows(OIDDot3adAggPortAttachedAggID, "Integer", "1", "0"),
+				OIDifType:                     rows(OIDifType, "Integer", "1", "6"),
+				OIDifStackStatus:              {},
+			},
+			want: map[string][]string{},
+		},
+		{
+			name: "neither table",
+			walks: map[string]FixtureWalk{
+				OIDDot3adAggPortAttachedAggID: {Error: "NoSuchObject"},
+				OIDifType:                     {Error: "request timeout (after 3 retries)"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "member with no port name",
+			walks: map[string]FixtureWalk{
+				OIDDot3adAggPortAttachedAggID: rows(OIDDot3adAggPortAttachedAggID, "Integer", "1", "100", "9", "100"),
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := walkLAGMembers(replayWalks(test.walks), ifIndexPortNameMap)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("walkLAGMembers() error = %v, wantErr %v", err, test.wantErr)
+			}
+			if !test.wantErr && !reflect.DeepEqual(got, test.want) {
+				t.Errorf("walkLAGMembers() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,228 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package snmp_utilities
+
+import (
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// The OID of lldpLocPortId from LLDP-MIB, the ID each local port is advertised with, indexed by lldpLocPortNum.
+var OIDLldpLocPortID = "1.0.8802.1.1.2.1.3.7.1.3"
+
+// The OIDs of lldpRemTable from LLDP-MIB, the neighbors heard on each port. Rows are indexed by
+// lldpRemTimeMark.lldpRemLocalPortNum.lldpRemIndex.
+var (
+	OIDLldpRemChassisID     = "1.0.8802.1.1.2.1.4.1.1.5"
+	OIDLldpRemPortID        = "1.0.8802.1.1.2.1.4.1.1.7"
+	OIDLldpRemSysName       = "1.0.8802.1.1.2.1.4.1.1.9"
+	OIDLldpRemSysCapEnabled = "1.0.8802.1.1.2.1.4.1.1.12"
+)
+
+// The LldpSystemCapabilitiesMap bits, in the order they are numbered.
+var lldpCapabilities = []string{"other", "repeater", "bridge", "wlanAccessPoint", "router", "telephone",
+	"docsisCableDevice", "stationOnly"}
+
+// PortClass is what is on the other end of a switch port.
+type PortClass string
+
+const (
+	// PortClassEdge ports are cabled to nodes, BMCs and the like, the MAC addresses learned on them are theirs.
+	PortClassEdge PortClass = "edge"
+	// PortClassInterSwitch ports are cabled to other switches, the MAC addresses learned on them are of devices
+	// cabled to those switches.
+	PortClassInterSwitch PortClass = "inter-switch"
+)
+
+// LLDPNeighbor is a device heard advertising itself with LLDP on a port of a switch.
+type LLDPNeighbor struct {
+	Port         string   `json:"Port"`
+	SysName      string   `json:"SysName,omitempty"`
+	ChassisID    string   `json:"ChassisID,omitempty"`
+	PortID       string   `json:"PortID,omitempty"`
+	Capabilities []string `json:"Capabilities,omitempty"`
+}
+
+// IsSwitch is whether the neighbor has bridging or routing enabled, which nodes and BMCs don't.
+func (neighbor LLDPNeighbor) IsSwitch() bool {
+	for _, capability := range neighbor.Capabilities {
+		if capability == "bridge" || capability == "router" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Class is what the port the neighbor was heard on is cabled to.
+func (neighbor LLDPNeighbor) Class() PortClass {
+	if neighbor.IsSwitch() {
+		return PortClassInterSwitch
+	}
+
+	return PortClassEdge
+}
+
+var hexOctets = regexp.MustCompile(`^[0-9a-fA-F]{2}(:[0-9a-fA-F]{2})*$`)
+
+// octets are the bytes of an octet string VarBind, which is given in hex when it isn't text.
+func (varBind VarBind) octets() []byte {
+	if hexOctets.MatchString(varBind.Value) {
+		octets, err := hex.DecodeString(strings.ReplaceAll(varBind.Value, ":", ""))
+		if err == nil {
+			return octets
+		}
+	}
+
+	return []byte(varBind.Value)
+}
+
+// decodeCapabilities turns an LldpSystemCapabilitiesMap into the names of the bits that are set, the first bit being
+// the most significant bit of the first octet.
+func decodeCapabilities(capabilitiesMap []byte) (capabilities []string) {
+	for bit, capability := range lldpCapabilities {
+		if bit/8 < len(capabilitiesMap) && capabilitiesMap[bit/8]&(0x80>>(bit%8)) != 0 {
+			capabilities = append(capabilities, capability)
+		}
+	}
+
+	return
+}
+
+// lldpRemoteKey is the lldpRemLocalPortNum.lldpRemIndex part of an lldpRemTable row OID.
+func lldpRemoteKey(oid string) (localPortNum int, key string, err error) {
+	oidParts := strings.Split(oid, ".")
+	if len(oidParts) < 3 {
+		err = fmt.Errorf("OID (%s) is not an lldpRemTable row", oid)
+		return
+	}
+
+	localPortNum, err = strconv.Atoi(oidParts[len(oidParts)-2])
+	key = strings.Join(oidParts[len(oidParts)-2:], ".")
+
+	return
+}
+
+// lldpLocalPortNames works out the name of each local LLDP port. A port advertised with the ifName of an interface
+// is that interface, otherwise lldpLocPortNum is taken to be the ifIndex as it is on most switches.
+func lldpLocalPortNames(walker Walker, ifIndexPortNameMap map[int]string) (map[int]string, error) {
+	portNamesByLowerName := map[string]string{}
+	for _, portName := range ifIndexPortNameMap {
+		portNamesByLowerName[strings.ToLower(portName)] = portName
+	}
+
+	localPortIDs, err := walker.Walk(OIDLldpLocPortID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get lldpLocPortId: %w", err)
+	}
+
+	localPortNames := map[int]string{}
+	for _, localPortID := range localPortIDs {
+		localPortNum, convertErr := localPortID.lastOIDPart()
+		if convertErr != nil {
+			return nil, fmt.Errorf("failed to convert lldpLocPortNum to integer: %w", convertErr)
+		}
+
+		if portName, found := portNamesByLowerName[strings.ToLower(strings.TrimSpace(localPortID.Value))]; found {
+			localPortNames[localPortNum] = portName
+		}
+	}
+
+	return localPortNames, nil
+}
+
+func walkLLDPNeighbors(walker Walker, ifIndexPortNameMap map[int]string) (neighbors []LLDPNeighbor, err error) {
+	localPortNames, err := lldpLocalPortNames(walker, ifIndexPortNameMap)
+	if err != nil {
+		return
+	}
+
+	neighborsByKey := map[string]*LLDPNeighbor{}
+	columns := []struct {
+		name string
+		oid  string
+		set  func(neighbor *LLDPNeighbor, varBind VarBind)
+	}{
+		{"lldpRemSysName", OIDLldpRemSysName, func(neighbor *LLDPNeighbor, varBind VarBind) {
+			neighbor.SysName = strings.TrimSpace(varBind.Value)
+		}},
+		{"lldpRemChassisId", OIDLldpRemChassisID, func(neighbor *LLDPNeighbor, varBind VarBind) {
+			neighbor.ChassisID = varBind.Value
+		}},
+		{"lldpRemPortId", OIDLldpRemPortID, func(neighbor *LLDPNeighbor, varBind VarBind) {
+			neighbor.PortID = varBind.Value
+		}},
+		{"lldpRemSysCapEnabled", OIDLldpRemSysCapEnabled, func(neighbor *LLDPNeighbor, varBind VarBind) {
+			neighbor.Capabilities = decodeCapabilities(varBind.octets())
+		}},
+	}
+
+	for _, column := range columns {
+		result, walkErr := walker.Walk(column.oid)
+		if walkErr != nil {
+			err = fmt.Errorf("failed to get %s: %w", column.name, walkErr)
+			return
+		}
+
+		for _, res := range result {
+			localPortNum, key, keyErr := lldpRemoteKey(res.OID)
+			if keyErr != nil {
+				err = fmt.Errorf("failed to convert lldpRemLocalPortNum to integer: %w", keyErr)
+				return
+			}
+
+			neighbor, found := neighborsByKey[key]
+			if !found {
+				portName, named := localPortNames[localPortNum]
+				if !named {
+					portName, named = ifIndexPortNameMap[localPortNum]
+				}
+				if !named {
+					// Nothing to tie the neighbor to a port of the switch with.
+					continue
+				}
+
+				neighbor = &LLDPNeighbor{Port: portName}
+				neighborsByKey[key] = neighbor
+			}
+
+			column.set(neighbor, res)
+		}
+	}
+
+	for _, neighbor := range neighborsByKey {
+		neighbors = append(neighbors, *neighbor)
+	}
+	sort.Slice(neighbors, func(i, j int) bool {
+		if neighbors[i].Port != neighbors[j].Port {
+			return neighbors[i].Port < neighbors[j].Port
+		}
+		return neighbors[i].SysName < neighbors[j].SysName
+	})
+
+	return
+}
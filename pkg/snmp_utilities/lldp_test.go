@@ -0,0 +1,102 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package snmp_utilities
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWalkLLDPNeighbors(t *testing.T) {
+	ifIndexPortNameMap := map[int]string{1: "1/1/1", 2: "1/1/2", 3: "1/1/3"}
+
+	// lldpLocPortNum isn't the ifIndex for 1/1/1 and 1/1/2, it is for 1/1/3. Nothing can be found for 99.
+	remoteWalks := map[string]FixtureWalk{
+		OIDLldpLocPortID: rows(OIDLldpLocPortID, "OctetString", "11", "1/1/1", "12", "1/1/2"),
+		OIDLldpRemSysName: rows(OIDLldpRemSysName, "OctetString",
+			"0.11.1", "sw-spine-001 ", "0.12.1", "ncn-w001", "0.3.1", "x3000c0s9b0", "0.99.1", "lost"),
+		OIDLldpRemChassisID: rows(OIDLldpRemChassisID, "OctetString", "0.11.1", "b4:2e:99:3b:70:28"),
+		OIDLldpRemPortID:    rows(OIDLldpRemPortID, "OctetString", "0.11.1", "1/1/48"),
+		OIDLldpRemSysCapEnabled: rows(OIDLldpRemSysCapEnabled, "OctetString",
+			"0.11.1", "28:00", "0.12.1", "80", "0.3.1", "01"),
+	}
+	withWalk := func(oid string, walk FixtureWalk) map[string]FixtureWalk {
+		walks := map[string]FixtureWalk{}
+		for walkOID, recorded := range remoteWalks {
+			walks[walkOID] = recorded
+		}
+		walks[oid] = walk
+
+		return walks
+	}
+
+	tests := []struct {
+		name    string
+		walks   map[string]FixtureWalk
+		want    []LLDPNeighbor
+		wantErr bool
+	}{
+		{
+			name:  "neighbors",
+			walks: remoteWalks,
+			want: []LLDPNeighbor{
+				{Port: "1/1/1", SysName: "sw-spine-001", ChassisID: "b4:2e:99:3b:70:28", PortID: "1/1/48",
+					Capabilities: []string{"bridge", "router"}},
+				{Port: "1/1/2", SysName: "ncn-w001", Capabilities: []string{"other"}},
+				{Port: "1/1/3", SysName: "x3000c0s9b0", Capabilities: []string{"stationOnly"}},
+			},
+		},
+		{
+			name:  "no neighbors",
+			walks: withWalk(OIDLldpRemSysName, FixtureWalk{}),
+			want: []LLDPNeighbor{
+				{Port: "1/1/1", ChassisID: "b4:2e:99:3b:70:28", PortID: "1/1/48",
+					Capabilities: []string{"bridge", "router"}},
+				{Port: "1/1/2", Capabilities: []string{"other"}},
+				{Port: "1/1/3", Capabilities: []string{"stationOnly"}},
+			},
+		},
+		{
+			name:    "LLDP-MIB not supported",
+			walks:   withWalk(OIDLldpLocPortID, FixtureWalk{Error: "NoSuchObject"}),
+			wantErr: true,
+		},
+		{
+			name:    "remote table fails",
+			walks:   withWalk(OIDLldpRemPortID, FixtureWalk{Error: "request timeout (after 3 retries)"}),
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := walkLLDPNeighbors(replayWalks(test.walks), ifIndexPortNameMap)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("walkLLDPNeighbors() error = %v, wantErr %v", err, test.wantErr)
+			}
+			if !test.wantErr && !reflect.DeepEqual(got, test.want) {
+				t.Errorf("walkLLDPNeighbors() = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
@@ -1,6 +1,6 @@
 // MIT License
 //
-// (C) Copyright [2021] Hewlett Packard Enterprise Development LP
+// (C) Copyright [2021,2026] Hewlett Packard Enterprise Development LP
 //
 // Permission is hereby granted, free of charge, to any person obtaining a
 // copy of this software and associated documentation files (the "Software"),
@@ -27,13 +27,23 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 )
 
 // This just exists to make development easier. Testing with a real switch is a pain, so it's nice to just capture
 // the output from a switch one, dump it into some files, and then just pass those files right back when testing.
 
+func (snmpInterface MockSNMP) path(name string) string {
+	directory := snmpInterface.Directory
+	if directory == "" {
+		directory = "configs"
+	}
+
+	return filepath.Join(directory, name)
+}
+
 func (snmpInterface MockSNMP) GetPortMap() (portMap map[int]string, err error) {
-	jsonFile, err := os.Open("configs/portMap.json")
+	jsonFile, err := os.Open(snmpInterface.path("portMap.json"))
 	if err != nil {
 		return
 	}
@@ -61,7 +71,7 @@ func (snmpInterface MockSNMP) GetPortMap() (portMap map[int]string, err error) {
 }
 
 func (snmpInterface MockSNMP) GetPortNumberMap() (portNumberMap map[int]int, err error) {
-	jsonFile, err := os.Open("configs/portNumberMap.json")
+	jsonFile, err := os.Open(snmpInterface.path("portNumberMap.json"))
 	if err != nil {
 		return
 	}
@@ -88,9 +98,9 @@ func (snmpInterface MockSNMP) GetPortNumberMap() (portNumberMap map[int]int, err
 	return
 }
 
-func (snmpInterface MockSNMP) GetMACPortNameTable(map[int]int, map[int]string) (macPortMap map[string]string,
-	err error) {
-	jsonFile, err := os.Open("configs/macPortMap.json")
+func (snmpInterface MockSNMP) GetMACPortNameTable(map[int]int, map[int]string, []FDBTable) (
+	macPortMap map[string]string, err error) {
+	jsonFile, err := os.Open(snmpInterface.path("macPortMap.json"))
 	if err != nil {
 		return
 	}
@@ -116,3 +126,67 @@ func (snmpInterface MockSNMP) GetMACPortNameTable(map[int]int, map[int]string) (
 
 	return
 }
+
+// GetLLDPNeighbors is the one table that doesn't have to be mocked, switches that don't have it in
+// lldpNeighbors.json have no neighbors.
+func (snmpInterface MockSNMP) GetLLDPNeighbors(map[int]string) (neighbors []LLDPNeighbor, err error) {
+	jsonBytes, err := os.ReadFile(snmpInterface.path("lldpNeighbors.json"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return
+	}
+
+	var mockNeighbors map[string][]LLDPNeighbor
+	err = json.Unmarshal(jsonBytes, &mockNeighbors)
+	if err != nil {
+		return
+	}
+
+	neighbors = mockNeighbors[snmpInterface.SwitchXname]
+
+	return
+}
+
+// GetLAGMembers doesn't have to be mocked either, switches that don't have their LAGs in lagMembers.json have none.
+func (snmpInterface MockSNMP) GetLAGMembers(map[int]string) (lagMembers map[string][]string, err error) {
+	jsonBytes, err := os.ReadFile(snmpInterface.path("lagMembers.json"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return
+	}
+
+	var mockLAGMembers map[string]map[string][]string
+	err = json.Unmarshal(jsonBytes, &mockLAGMembers)
+	if err != nil {
+		return
+	}
+
+	lagMembers = mockLAGMembers[snmpInterface.SwitchXname]
+
+	return
+}
+
+// GetARPTable doesn't have to be mocked either, switches that don't have their ARP table in arpTable.json don't route.
+func (snmpInterface MockSNMP) GetARPTable() (arpTable map[string]string, err error) {
+	jsonBytes, err := os.ReadFile(snmpInterface.path("arpTable.json"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return
+	}
+
+	var mockARPTable map[string]map[string]string
+	err = json.Unmarshal(jsonBytes, &mockARPTable)
+	if err != nil {
+		return
+	}
+
+	arpTable = mockARPTable[snmpInterface.SwitchXname]
+
+	return
+}
@@ -0,0 +1,295 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package snmp_utilities
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// FDBTable is a table a switch keeps the MAC addresses it has learned in.
+type FDBTable string
+
+const (
+	// FDBTableDot1d is dot1dTpFdbTable from BRIDGE-MIB, which doesn't know about VLANs.
+	FDBTableDot1d FDBTable = "dot1dTpFdb"
+	// FDBTableDot1q is dot1qTpFdbTable from Q-BRIDGE-MIB, with an entry per VLAN.
+	FDBTableDot1q FDBTable = "dot1qTpFdb"
+)
+
+// SwitchIdentity is what a switch says it is.
+type SwitchIdentity struct {
+	SysDescr string `json:"SysDescr,omitempty"`
+	Model    string `json:"Model,omitempty"`
+}
+
+// Known is whether the switch said anything about itself.
+func (identity SwitchIdentity) Known() bool {
+	return identity.SysDescr != "" || identity.Model != ""
+}
+
+type portNameRule struct {
+	pattern     *regexp.Regexp
+	replacement string
+}
+
+// VendorProfile is how the MAC address tables of a vendor's switches are walked and interpreted.
+type VendorProfile struct {
+	// Name identifies the profile in logs and the run report.
+	Name string
+	// Vendor is what the SLS Brand of a switch using the profile should be, empty for the generic profile.
+	Vendor string
+	// FDBTables are walked in order, a MAC address in more than one of them is taken to be on the port in the first.
+	FDBTables []FDBTable
+	// PortPrefixes are the interface type prefixes, such as ethernet, ignored when comparing port names.
+	PortPrefixes []string
+
+	identity      *regexp.Regexp
+	portNameRules []portNameRule
+	uplinkPorts   *regexp.Regexp
+}
+
+// NormalizePortName turns the name a switch gives a port into the form SLS uses for the vendor.
+func (profile VendorProfile) NormalizePortName(portName string) string {
+	portName = strings.TrimSpace(portName)
+	for _, rule := range profile.portNameRules {
+		portName = rule.pattern.ReplaceAllString(portName, rule.replacement)
+	}
+
+	return portName
+}
+
+// Leading zeros of every number in a port name, other than a number that is just 0.
+var portNameLeadingZeros = regexp.MustCompile(`(^|[^0-9])0+([0-9])`)
+
+// CanonicalPortName is the form port names are compared in, so the ifName of a port and the VendorName SLS has for it
+// match when they are written differently. Case, whitespace, the profile's port prefixes and leading zeros are
+// ignored, so Ethernet1/1/01:2 and 1/1/1:2 are the same port.
+func (profile VendorProfile) CanonicalPortName(portName string) string {
+	canonical := strings.ToLower(strings.Join(strings.Fields(portName), ""))
+
+	// Longest first so ethernet isn't mistaken for eth followed by ernet.
+	prefixes := append([]string(nil), profile.PortPrefixes...)
+	sort.Slice(prefixes, func(i, j int) bool {
+		return len(prefixes[i]) > len(prefixes[j])
+	})
+	for _, prefix := range prefixes {
+		rest := strings.TrimPrefix(canonical, strings.ToLower(prefix))
+		if rest != canonical && rest != "" && rest[0] >= '0' && rest[0] <= '9' {
+			canonical = rest
+			break
+		}
+	}
+
+	return portNameLeadingZeros.ReplaceAllString(canonical, "$1$2")
+}
+
+// IsUplink is whether a port connects to other switches or the switch itself, rather than to a node or BMC, so the
+// MAC addresses learned on it aren't of anything cabled to the switch.
+func (profile VendorProfile) IsUplink(portName string) bool {
+	return profile.uplinkPorts.MatchString(portName)
+}
+
+// GenericProfile is used for switches that don't match any other profile. Both FDB tables are walked and port names
+// are left alone, which is how every switch used to be handled.
+var GenericProfile = VendorProfile{
+	Name:         "generic",
+	FDBTables:    []FDBTable{FDBTableDot1d, FDBTableDot1q},
+	PortPrefixes: []string{"ethernet", "eth"},
+	uplinkPorts:  regexp.MustCompile(`(?i)^(port-channel|lag|vlan|mgmt|loopback)\s*\d`),
+}
+
+// VendorProfiles are tried in order against what a switch says it is.
+var VendorProfiles = []VendorProfile{
+	{
+		// Aruba AOS-CX names ports like 1/1/28.
+		Name:         "aruba-aoscx",
+		Vendor:       "Aruba",
+		FDBTables:    []FDBTable{FDBTableDot1q},
+		PortPrefixes: []string{"ethernet", "eth", "port"},
+		identity:     regexp.MustCompile(`(?i)aruba|aos-cx`),
+		uplinkPorts:  regexp.MustCompile(`(?i)^(lag|vlan|mgmt|loopback)\s*\d`),
+	},
+	{
+		// Dell OS10 names ports like ethernet1/1/28, only walking dot1dTpFdb if enable-dot1d-mibwalk is configured.
+		Name:         "dell-os10",
+		Vendor:       "Dell",
+		FDBTables:    []FDBTable{FDBTableDot1q},
+		PortPrefixes: []string{"ethernet", "eth"},
+		identity:     regexp.MustCompile(`(?i)os10|dell`),
+		portNameRules: []portNameRule{
+			{regexp.MustCompile(`(?i)^ethernet\s*`), "ethernet"},
+		},
+		uplinkPorts: regexp.MustCompile(`(?i)^(port-channel|vlan|mgmt|loopback)\s*\d`),
+	},
+	{
+		// Mellanox Onyx names ports like Eth1/28, MLAG port channels are mpo.
+		Name:         "mellanox-onyx",
+		Vendor:       "Mellanox",
+		FDBTables:    []FDBTable{FDBTableDot1q},
+		PortPrefixes: []string{"ethernet", "eth"},
+		identity:     regexp.MustCompile(`(?i)mellanox|onyx|mlnx-os|^msn`),
+		portNameRules: []portNameRule{
+			{regexp.MustCompile(`(?i)^eth(ernet)?\s*`), "Eth"},
+		},
+		uplinkPorts: regexp.MustCompile(`(?i)^(po|mpo|vlan|mgmt|loopback)\s*\d`),
+	},
+	{
+		// Cisco NX-OS names ports like Ethernet1/28 and keeps most of its MAC addresses in dot1dTpFdb.
+		Name:         "cisco-nxos",
+		Vendor:       "Cisco",
+		FDBTables:    []FDBTable{FDBTableDot1d, FDBTableDot1q},
+		PortPrefixes: []string{"ethernet", "eth", "e"},
+		identity:     regexp.MustCompile(`(?i)cisco|nx-os`),
+		portNameRules: []portNameRule{
+			{regexp.MustCompile(`(?i)^eth(ernet)?\s*`), "Ethernet"},
+		},
+		uplinkPorts: regexp.MustCompile(`(?i)^(port-channel|po|vlan|mgmt|loopback)\s*\d`),
+	},
+}
+
+// ParsePortNamePrefixes parses port prefixes for profiles written as profile=prefix,prefix;profile=prefix. A profile
+// can be given no prefixes to compare the port names with their prefixes.
+func ParsePortNamePrefixes(spec string) (map[string][]string, error) {
+	profileNames := map[string]bool{GenericProfile.Name: true}
+	for _, profile := range VendorProfiles {
+		profileNames[profile.Name] = true
+	}
+
+	portPrefixes := map[string][]string{}
+	for _, entry := range strings.Split(spec, ";") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+
+		name, prefixes, found := strings.Cut(entry, "=")
+		name = strings.TrimSpace(name)
+		if !found {
+			return nil, fmt.Errorf("port name prefixes %q are not in the form profile=prefix,prefix", entry)
+		}
+		if !profileNames[name] {
+			return nil, fmt.Errorf("unknown vendor profile: %s", name)
+		}
+
+		portPrefixes[name] = []string{}
+		for _, prefix := range strings.Split(prefixes, ",") {
+			if prefix = strings.TrimSpace(prefix); prefix != "" {
+				portPrefixes[name] = append(portPrefixes[name], prefix)
+			}
+		}
+	}
+
+	return portPrefixes, nil
+}
+
+// SetPortNamePrefixes replaces the port prefixes of the profiles named in spec, see ParsePortNamePrefixes.
+func SetPortNamePrefixes(spec string) error {
+	portPrefixes, err := ParsePortNamePrefixes(spec)
+	if err != nil {
+		return err
+	}
+
+	if prefixes, found := portPrefixes[GenericProfile.Name]; found {
+		GenericProfile.PortPrefixes = prefixes
+	}
+	for i, profile := range VendorProfiles {
+		if prefixes, found := portPrefixes[profile.Name]; found {
+			VendorProfiles[i].PortPrefixes = prefixes
+		}
+	}
+
+	return nil
+}
+
+// SelectProfile picks the profile for a switch from what it says it is. When it didn't say, which is always the
+// case with MockSNMP, the profile for its SLS brand is used instead.
+func SelectProfile(identity SwitchIdentity, brand string) VendorProfile {
+	for _, profile := range VendorProfiles {
+		if identity.Known() {
+			if profile.identity.MatchString(identity.SysDescr) || profile.identity.MatchString(identity.Model) {
+				return profile
+			}
+		} else if brand != "" && strings.EqualFold(profile.Vendor, brand) {
+			return profile
+		}
+	}
+
+	return GenericProfile
+}
+
+// CompareWithSLS lists the ways a switch isn't what SLS says it is, which can only be told when the switch
+// identified itself.
+func CompareWithSLS(identity SwitchIdentity, profile VendorProfile, brand, model string) (mismatches []string) {
+	if !identity.Known() {
+		return
+	}
+
+	if brand != "" && profile.Vendor != "" && !strings.EqualFold(brand, profile.Vendor) {
+		mismatches = append(mismatches, fmt.Sprintf("SLS Brand is %s but the switch is %s", brand, profile.Vendor))
+	}
+
+	if model != "" && identity.Model != "" &&
+		!strings.Contains(strings.ToLower(identity.Model), strings.ToLower(model)) &&
+		!strings.Contains(strings.ToLower(model), strings.ToLower(identity.Model)) {
+		mismatches = append(mismatches, fmt.Sprintf("SLS Model is %s but the switch is %s", model, identity.Model))
+	}
+
+	return
+}
+
+// Identify asks a switch what it is. The model is that of the physical entity at OIDModelNumber, or the first one
+// with a model when that doesn't have one.
+func Identify(walker Walker) (identity SwitchIdentity, err error) {
+	sysDescr, err := walker.Walk(OIDSysDescr)
+	if err != nil {
+		err = fmt.Errorf("failed to get sysDescr: %w", err)
+		return
+	}
+	if len(sysDescr) > 0 {
+		identity.SysDescr = strings.TrimSpace(sysDescr[0].Value)
+	}
+
+	models, err := walker.Walk(OIDEntPhysicalModelName)
+	if err != nil {
+		err = fmt.Errorf("failed to get entPhysicalModelName: %w", err)
+		return
+	}
+	for _, model := range models {
+		value := strings.TrimSpace(model.Value)
+		if value == "" {
+			continue
+		}
+
+		if model.OID == OIDModelNumber {
+			identity.Model = value
+			break
+		}
+		if identity.Model == "" {
+			identity.Model = value
+		}
+	}
+
+	return
+}
@@ -0,0 +1,120 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package snmp_utilities
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSelectProfile(t *testing.T) {
+	tests := []struct {
+		name     string
+		identity SwitchIdentity
+		brand    string
+		want     string
+	}{
+		{name: "Aruba sysDescr", identity: SwitchIdentity{SysDescr: "Aruba JL635A GL.10.06.0010"}, brand: "Dell",
+			want: "aruba-aoscx"},
+		{name: "Dell sysDescr", identity: SwitchIdentity{SysDescr: "Dell SmartFabric OS10 Enterprise"},
+			want: "dell-os10"},
+		{name: "Mellanox model", identity: SwitchIdentity{Model: "MSN2100"}, want: "mellanox-onyx"},
+		{name: "Cisco sysDescr", identity: SwitchIdentity{SysDescr: "Cisco NX-OS(tm) n9000"}, want: "cisco-nxos"},
+		{name: "unknown switch ignores brand", identity: SwitchIdentity{SysDescr: "Juniper Networks"},
+			brand: "Aruba", want: "generic"},
+		{name: "silent switch uses brand", brand: "mellanox", want: "mellanox-onyx"},
+		{name: "silent switch without brand", want: "generic"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := SelectProfile(test.identity, test.brand); got.Name != test.want {
+				t.Errorf("SelectProfile(%+v, %q) = %s, want %s", test.identity, test.brand, got.Name, test.want)
+			}
+		})
+	}
+}
+
+func TestCompareWithSLS(t *testing.T) {
+	aruba := SelectProfile(SwitchIdentity{}, "Aruba")
+
+	tests := []struct {
+		name     string
+		identity SwitchIdentity
+		brand    string
+		model    string
+		want     []string
+	}{
+		{name: "matches", identity: SwitchIdentity{SysDescr: "Aruba", Model: "JL635A 8325"}, brand: "Aruba",
+			model: "8325"},
+		{name: "silent switch", brand: "Dell", model: "S3048-ON"},
+		{name: "wrong brand", identity: SwitchIdentity{SysDescr: "Aruba"}, brand: "Dell",
+			want: []string{"SLS Brand is Dell but the switch is Aruba"}},
+		{name: "wrong model", identity: SwitchIdentity{SysDescr: "Aruba", Model: "JL635A 8325"}, model: "6300M",
+			want: []string{"SLS Model is 6300M but the switch is JL635A 8325"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := CompareWithSLS(test.identity, aruba, test.brand, test.model)
+			if !slices.Equal(got, test.want) {
+				t.Errorf("CompareWithSLS() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestCanonicalPortName(t *testing.T) {
+	tests := []struct {
+		name     string
+		prefixes []string
+		portName string
+		want     string
+	}{
+		{name: "already canonical", prefixes: []string{"ethernet", "eth"}, portName: "1/1/1", want: "1/1/1"},
+		{name: "prefix and case", prefixes: []string{"ethernet", "eth"}, portName: "Ethernet1/1/28",
+			want: "1/1/28"},
+		{name: "whitespace", prefixes: []string{"ethernet", "eth"}, portName: " ethernet 1/1/28 ", want: "1/1/28"},
+		{name: "leading zeros", prefixes: []string{"ethernet", "eth"}, portName: "Ethernet1/1/01:2",
+			want: "1/1/1:2"},
+		{name: "zero port", prefixes: []string{"ethernet", "eth"}, portName: "1/1/00", want: "1/1/0"},
+		{name: "shorter prefix", prefixes: []string{"ethernet", "eth"}, portName: "Eth1/28", want: "1/28"},
+		{name: "longest prefix wins", prefixes: []string{"e", "eth", "ethernet"}, portName: "ethernet1/28",
+			want: "1/28"},
+		{name: "single letter prefix", prefixes: []string{"ethernet", "eth", "e"}, portName: "e1/28", want: "1/28"},
+		{name: "prefix not followed by a number", prefixes: []string{"ethernet", "eth"}, portName: "ethernet",
+			want: "ethernet"},
+		{name: "other interface type", prefixes: []string{"ethernet", "eth"}, portName: "port-channel100",
+			want: "port-channel100"},
+		{name: "prefixes turned off", prefixes: nil, portName: "Ethernet1/1/1", want: "ethernet1/1/1"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			profile := VendorProfile{Name: "test", PortPrefixes: test.prefixes}
+			if got := profile.CanonicalPortName(test.portName); got != test.want {
+				t.Errorf("CanonicalPortName(%q) = %q, want %q", test.portName, got, test.want)
+			}
+		})
+	}
+}
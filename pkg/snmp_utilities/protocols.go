@@ -0,0 +1,82 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package snmp_utilities
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// The SNMPv3 auth protocols a switch can be configured with in SLS, sha is SHA-1.
+var authProtocols = map[string]gosnmp.SnmpV3AuthProtocol{
+	"none":   gosnmp.NoAuth,
+	"md5":    gosnmp.MD5,
+	"sha":    gosnmp.SHA,
+	"sha1":   gosnmp.SHA,
+	"sha224": gosnmp.SHA224,
+	"sha256": gosnmp.SHA256,
+	"sha384": gosnmp.SHA384,
+	"sha512": gosnmp.SHA512,
+}
+
+// The SNMPv3 privacy protocols a switch can be configured with in SLS. AES-192/256 use the key extension from the
+// Blumenthal draft, the ones ending in c use the Reeder draft that Cisco switches implement.
+var privProtocols = map[string]gosnmp.SnmpV3PrivProtocol{
+	"none":    gosnmp.NoPriv,
+	"des":     gosnmp.DES,
+	"aes":     gosnmp.AES,
+	"aes128":  gosnmp.AES,
+	"aes192":  gosnmp.AES192,
+	"aes256":  gosnmp.AES256,
+	"aes192c": gosnmp.AES192C,
+	"aes256c": gosnmp.AES256C,
+}
+
+// normalizeProtocol lets protocols be written the way switch vendors tend to, such as SHA-256 or AES_256.
+func normalizeProtocol(protocol string) string {
+	return strings.NewReplacer("-", "", "_", "", " ", "").Replace(strings.ToLower(protocol))
+}
+
+// ParseAuthProtocol turns the SNMPAuthProtocol of a switch into the protocol to use, anything not known is an error
+// rather than a session that can never authenticate.
+func ParseAuthProtocol(protocol string) (gosnmp.SnmpV3AuthProtocol, error) {
+	authProtocol, found := authProtocols[normalizeProtocol(protocol)]
+	if !found {
+		return 0, fmt.Errorf("unsupported SNMP auth protocol: %q", protocol)
+	}
+
+	return authProtocol, nil
+}
+
+// ParsePrivProtocol turns the SNMPPrivProtocol of a switch into the protocol to use, anything not known is an error
+// rather than a session that can never decrypt a response.
+func ParsePrivProtocol(protocol string) (gosnmp.SnmpV3PrivProtocol, error) {
+	privProtocol, found := privProtocols[normalizeProtocol(protocol)]
+	if !found {
+		return 0, fmt.Errorf("unsupported SNMP privacy protocol: %q", protocol)
+	}
+
+	return privProtocol, nil
+}
@@ -1,6 +1,6 @@
 // MIT License
 //
-// (C) Copyright [2021] Hewlett Packard Enterprise Development LP
+// (C) Copyright [2021,2026] Hewlett Packard Enterprise Development LP
 //
 // Permission is hereby granted, free of charge, to any person obtaining a
 // copy of this software and associated documentation files (the "Software"),
@@ -24,103 +24,130 @@ package snmp_utilities
 
 import (
 	"fmt"
-	"strconv"
 	"strings"
-)
 
-func (snmpInterface RealSNMP) GetPortMap() (portMap map[int]string, err error) {
-	result, bulkErr := snmpGetBulk(snmpInterface.SNMP, OIDifIndexPortNameMap)
-	if bulkErr != nil {
-		err = fmt.Errorf("failed to perform bulk get: %w", bulkErr)
-		return
-	}
+	"github.com/gosnmp/gosnmp"
+)
 
-	portMap = make(map[int]string)
+// The type names k-sone/snmpgo, which switches used to be walked with, gave each value. Walks are still recorded with
+// these names so that fixtures recorded before read the same.
+var varBindTypes = map[gosnmp.Asn1BER]string{
+	gosnmp.Integer:          "Integer",
+	gosnmp.OctetString:      "OctetString",
+	gosnmp.Null:             "Null",
+	gosnmp.ObjectIdentifier: "Oid",
+	gosnmp.IPAddress:        "Ipaddress",
+	gosnmp.Counter32:        "Counter32",
+	gosnmp.Gauge32:          "Gauge32",
+	gosnmp.TimeTicks:        "TimeTicks",
+	gosnmp.Opaque:           "Opaque",
+	gosnmp.Counter64:        "Counter64",
+	gosnmp.NoSuchObject:     "NoSucheObject",
+	gosnmp.NoSuchInstance:   "NoSucheInstance",
+	gosnmp.EndOfMibView:     "EndOfMibView",
+}
 
-	for _, res := range result.VarBinds() {
-		oidParts := strings.Split(res.Oid.String(), ".")
-		strIndex := oidParts[len(oidParts)-1]
-		ifIndex, convertErr := strconv.Atoi(strIndex)
-		if convertErr != nil {
-			err = fmt.Errorf("failed to convert ifIndex to integer: %w", convertErr)
-			return
+// printable is whether an octet string is text, otherwise it is shown in hex.
+func printable(value []byte) bool {
+	for _, c := range value {
+		if (c < 0x20 || c > 0x7e) && (c < 0x09 || c > 0x0d) {
+			return false
 		}
-
-		portMap[ifIndex] = res.Variable.String()
 	}
 
-	return
+	return true
 }
 
-func (snmpInterface RealSNMP) GetPortNumberMap() (portNumberMap map[int]int, err error) {
-	result, bulkErr := snmpGetBulk(snmpInterface.SNMP, OIDPortNumberifIndex)
-	if bulkErr != nil {
-		err = fmt.Errorf("failed to perform bulk get: %w", bulkErr)
-		return
+func newVarBind(pdu gosnmp.SnmpPDU) VarBind {
+	varBind := VarBind{
+		OID:  strings.TrimPrefix(pdu.Name, "."),
+		Type: varBindTypes[pdu.Type],
+	}
+	if varBind.Type == "" {
+		varBind.Type = pdu.Type.String()
 	}
 
-	portNumberMap = make(map[int]int)
-
-	for _, res := range result.VarBinds() {
-		oidParts := strings.Split(res.Oid.String(), ".")
-		strPortID := oidParts[len(oidParts)-1]
-		portID, convertErr := strconv.Atoi(strPortID)
-		if convertErr != nil {
-			err = fmt.Errorf("failed to convert port ID to integer: %w", convertErr)
-			return
+	switch value := pdu.Value.(type) {
+	case nil:
+	case []byte:
+		if pdu.Type == gosnmp.OctetString && printable(value) {
+			varBind.Value = string(value)
+		} else {
+			hexParts := make([]string, len(value))
+			for i, b := range value {
+				hexParts[i] = fmt.Sprintf("%02x", b)
+			}
+			varBind.Value = strings.Join(hexParts, ":")
 		}
+	case string:
+		varBind.Value = strings.TrimPrefix(value, ".")
+	case float32, float64:
+		varBind.Value = fmt.Sprint(value)
+	default:
+		varBind.Value = gosnmp.ToBigInt(value).String()
+	}
+
+	return varBind
+}
 
-		keyBI, err := res.Variable.BigInt()
-		if err != nil {
+// Walk does a bulk walk of oid on the switch.
+func (snmpInterface RealSNMP) Walk(oid string) ([]VarBind, error) {
+	// The session is only opened the first time, every walk of a switch goes over it until it is closed.
+	if snmpInterface.SNMP.Conn == nil {
+		if err := snmpInterface.SNMP.Connect(); err != nil {
 			return nil, err
 		}
+	}
+
+	result, err := snmpInterface.SNMP.BulkWalkAll(oid)
+	if err != nil {
+		return nil, err
+	}
 
-		key := int(keyBI.Int64())
+	var varBinds []VarBind
+	for _, pdu := range result {
+		varBinds = append(varBinds, newVarBind(pdu))
+	}
 
-		portNumberMap[key] = portID
+	if err := reportError(varBinds); err != nil {
+		return nil, err
 	}
 
-	return
+	return varBinds, nil
 }
 
-func (snmpInterface RealSNMP) GetMACPortNameTable(portNumberIfIndexMap map[int]int,
-	ifIndexPortNameMap map[int]string) (macPortMap map[string]string, err error) {
-	portMap, portMapErr := getDynamicMacs(snmpInterface.SNMP, false)
-	if portMapErr != nil {
-		err = fmt.Errorf("failed to get non-VLAN MAC port map: %w", portMapErr)
-		return
+// Close ends the session with the switch once it is done with.
+func (snmpInterface RealSNMP) Close() error {
+	if snmpInterface.SNMP.Conn == nil {
+		return nil
 	}
 
-	tempPortMap, portMapErr := getDynamicMacs(snmpInterface.SNMP, true)
-	if portMapErr != nil {
-		err = fmt.Errorf("failed to get VLAN MAC port map: %w", portMapErr)
-		return
-	}
+	return snmpInterface.SNMP.Conn.Close()
+}
 
-	// Combine both the VLAN and non-VLAN port maps into a single map.
-	for key, val := range tempPortMap {
-		if _, ok := portMap[key]; !ok {
-			portMap[key] = val
-		}
-	}
+func (snmpInterface RealSNMP) GetPortMap() (portMap map[int]string, err error) {
+	return walkPortMap(snmpInterface)
+}
 
-	macPortMap = make(map[string]string)
-	for key, value := range portMap {
-		ifIndex, ok := portNumberIfIndexMap[value]
-		if !ok {
-			err = fmt.Errorf("failed to map port (%d) to ifIndex", value)
-			return
-		}
+func (snmpInterface RealSNMP) GetPortNumberMap() (portNumberMap map[int]int, err error) {
+	return walkPortNumberMap(snmpInterface)
+}
 
-		name, ok := ifIndexPortNameMap[ifIndex]
-		if !ok {
-			err = fmt.Errorf("failed to map ifIndex (%d) to port name", ifIndex)
-			return
-		}
+func (snmpInterface RealSNMP) GetMACPortNameTable(portNumberIfIndexMap map[int]int,
+	ifIndexPortNameMap map[int]string, fdbTables []FDBTable) (macPortMap map[string]string, err error) {
+	return walkMACPortNameTable(snmpInterface, portNumberIfIndexMap, ifIndexPortNameMap, fdbTables)
+}
 
-		macPortMap[key] = name
-	}
+func (snmpInterface RealSNMP) GetLLDPNeighbors(ifIndexPortNameMap map[int]string) (neighbors []LLDPNeighbor,
+	err error) {
+	return walkLLDPNeighbors(snmpInterface, ifIndexPortNameMap)
+}
 
-	return
+func (snmpInterface RealSNMP) GetLAGMembers(ifIndexPortNameMap map[int]string) (lagMembers map[string][]string,
+	err error) {
+	return walkLAGMembers(snmpInterface, ifIndexPortNameMap)
 }
 
+func (snmpInterface RealSNMP) GetARPTable() (arpTable map[string]string, err error) {
+	return walkARPTable(snmpInterface)
+}
@@ -0,0 +1,154 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package snmp_utilities
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// VarBind is one result of an SNMP walk, with the value as it would be printed.
+type VarBind struct {
+	OID   string `json:"OID"`
+	Type  string `json:"Type"`
+	Value string `json:"Value"`
+}
+
+// Int is the value of an integer VarBind.
+func (varBind VarBind) Int() (int, error) {
+	value, ok := new(big.Int).SetString(varBind.Value, 10)
+	if !ok {
+		return 0, fmt.Errorf("%s value %q is not an integer", varBind.Type, varBind.Value)
+	}
+
+	return int(value.Int64()), nil
+}
+
+// lastOIDPart is the index of the table row the VarBind is from.
+func (varBind VarBind) lastOIDPart() (int, error) {
+	oidParts := strings.Split(varBind.OID, ".")
+	return strconv.Atoi(oidParts[len(oidParts)-1])
+}
+
+// Walker does the raw SNMP walks the switch tables are built from. Every SNMPInterface other than MockSNMP is a Walker
+// with the tables built from its walks the same way, so a recorded walk is interpreted exactly as a live one.
+type Walker interface {
+	Walk(oid string) ([]VarBind, error)
+}
+
+func walkPortMap(walker Walker) (portMap map[int]string, err error) {
+	result, bulkErr := walker.Walk(OIDifIndexPortNameMap)
+	if bulkErr != nil {
+		err = fmt.Errorf("failed to perform bulk get: %w", bulkErr)
+		return
+	}
+	if len(result) == 0 {
+		err = fmt.Errorf("ifName %w", ErrEmptyTable)
+		return
+	}
+
+	portMap = make(map[int]string)
+
+	for _, res := range result {
+		ifIndex, convertErr := res.lastOIDPart()
+		if convertErr != nil {
+			err = fmt.Errorf("failed to convert ifIndex to integer: %w", convertErr)
+			return
+		}
+
+		portMap[ifIndex] = res.Value
+	}
+
+	return
+}
+
+func walkPortNumberMap(walker Walker) (portNumberMap map[int]int, err error) {
+	result, bulkErr := walker.Walk(OIDPortNumberifIndex)
+	if bulkErr != nil {
+		err = fmt.Errorf("failed to perform bulk get: %w", bulkErr)
+		return
+	}
+	if len(result) == 0 {
+		err = fmt.Errorf("dot1dBasePortIfIndex %w", ErrEmptyTable)
+		return
+	}
+
+	portNumberMap = make(map[int]int)
+
+	for _, res := range result {
+		portID, convertErr := res.lastOIDPart()
+		if convertErr != nil {
+			err = fmt.Errorf("failed to convert port ID to integer: %w", convertErr)
+			return
+		}
+
+		key, err := res.Int()
+		if err != nil {
+			return nil, err
+		}
+
+		portNumberMap[key] = portID
+	}
+
+	return
+}
+
+func walkMACPortNameTable(walker Walker, portNumberIfIndexMap map[int]int,
+	ifIndexPortNameMap map[int]string, fdbTables []FDBTable) (macPortMap map[string]string, err error) {
+	// Combine the FDB tables into a single map, the first table a MAC address is in wins.
+	portMap := make(map[string]int)
+	for _, fdbTable := range fdbTables {
+		tablePortMap, portMapErr := getDynamicMacs(walker, fdbTable)
+		if portMapErr != nil {
+			err = fmt.Errorf("failed to get %s MAC port map: %w", fdbTable, portMapErr)
+			return
+		}
+
+		for key, val := range tablePortMap {
+			if _, ok := portMap[key]; !ok {
+				portMap[key] = val
+			}
+		}
+	}
+
+	macPortMap = make(map[string]string)
+	for key, value := range portMap {
+		ifIndex, ok := portNumberIfIndexMap[value]
+		if !ok {
+			err = fmt.Errorf("failed to map port (%d) to ifIndex", value)
+			return
+		}
+
+		name, ok := ifIndexPortNameMap[ifIndex]
+		if !ok {
+			err = fmt.Errorf("failed to map ifIndex (%d) to port name", ifIndex)
+			return
+		}
+
+		macPortMap[key] = name
+	}
+
+	return
+}
@@ -1,6 +1,6 @@
 // MIT License
 //
-// (C) Copyright [2021] Hewlett Packard Enterprise Development LP
+// (C) Copyright [2021,2026] Hewlett Packard Enterprise Development LP
 //
 // Permission is hereby granted, free of charge, to any person obtaining a
 // copy of this software and associated documentation files (the "Software"),
@@ -22,23 +22,78 @@
 
 package switches
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+
+	sls_common "github.com/Cray-HPE/hms-sls/v2/pkg/sls-common"
+)
+
+// SNMP versions a management switch can be walked with.
+const (
+	SNMPVersion2c = "v2c"
+	SNMPVersion3  = "v3"
+)
+
+// ParseSNMPVersion normalizes the SNMP version of a switch, anything unset is v3 as that is all we used to support.
+func ParseSNMPVersion(version string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(version)) {
+	case "", "3", "v3":
+		return SNMPVersion3, nil
+	case "2c", "v2c":
+		return SNMPVersion2c, nil
+	}
+
+	return "", fmt.Errorf("unsupported SNMP version: %s", version)
+}
+
+// SwitchProperties are the SLS extra properties of a management switch, including the SNMP version and v2c community
+// that sls_common.ComptypeMgmtSwitch doesn't know about yet.
+type SwitchProperties struct {
+	sls_common.ComptypeMgmtSwitch
+
+	SNMPVersion   string `json:"SNMPVersion,omitempty"`
+	SNMPCommunity string `json:"SNMPCommunity,omitempty"`
+}
 
 type ManagementSwitch struct {
 	Xname            string
 	Aliases          []string
 	Address          string
+	SNMPVersion      string
 	SNMPUser         string
 	SNMPAuthPassword string
 	SNMPAuthProtocol string
 	SNMPPrivPassword string
 	SNMPPrivProtocol string
+	SNMPCommunity    string
+	Brand            string
 	Model            string
 }
 
 func (s ManagementSwitch) String() string {
-	return fmt.Sprintf("{Xname: %s, Aliases: %s, Model: %s, Address: %s, SNMP User: %s, "+
+	return fmt.Sprintf("{Xname: %s, Aliases: %s, Brand: %s, Model: %s, Address: %s, SNMP Version: %s, SNMP User: %s, "+
 		"SNMP Auth Password: <REDACTED>, SNMP Auth Protocol: %s, "+
-		"SNMP Priv Password: <REDACTED>, SNMP Priv Protocol: %s}",
-		s.Xname, s.Aliases, s.Model, s.Address, s.SNMPUser, s.SNMPAuthProtocol, s.SNMPPrivProtocol)
+		"SNMP Priv Password: <REDACTED>, SNMP Priv Protocol: %s, SNMP Community: <REDACTED>}",
+		s.Xname, s.Aliases, s.Brand, s.Model, s.Address, s.SNMPVersion, s.SNMPUser, s.SNMPAuthProtocol, s.SNMPPrivProtocol)
+}
+
+// HasName is whether the switch goes by name, which is its xname or one of its aliases. Only the host part of a fully
+// qualified name is compared, and case doesn't matter.
+func (s ManagementSwitch) HasName(name string) bool {
+	host, _, _ := strings.Cut(strings.TrimSpace(name), ".")
+	if host == "" {
+		return false
+	}
+
+	if strings.EqualFold(host, s.Xname) {
+		return true
+	}
+	for _, alias := range s.Aliases {
+		if strings.EqualFold(host, alias) {
+			return true
+		}
+	}
+
+	return false
 }
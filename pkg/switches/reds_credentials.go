@@ -1,6 +1,6 @@
 // MIT License
 //
-// (C) Copyright [2021] Hewlett Packard Enterprise Development LP
+// (C) Copyright [2021,2026] Hewlett Packard Enterprise Development LP
 //
 // Permission is hereby granted, free of charge, to any person obtaining a
 // copy of this software and associated documentation files (the "Software"),
@@ -38,15 +38,29 @@ type RedsCredStore struct {
 	SS     securestorage.SecureStorage
 }
 
+// SwitchCredentials are the default SNMP credentials for management switches. The per switch credentials are kept
+// in a compcredentials.CompCredentials, where the SNMPv2c community goes in Password as it isn't otherwise used for
+// switches.
 type SwitchCredentials struct {
 	SNMPUsername     string
 	SNMPAuthPassword string
 	SNMPPrivPassword string
+	SNMPCommunity    string
 }
 
 func (switchCredentials SwitchCredentials) String() string {
-	return fmt.Sprintf("SNMPUsername: %s, SNMPAuthPassword: <REDACTED>, SNMPPrivPassword: <REDACTED>",
-		switchCredentials.SNMPUsername)
+	return fmt.Sprintf("SNMPUsername: %s, SNMPAuthPassword: <REDACTED>, SNMPPrivPassword: <REDACTED>, "+
+		"SNMPCommunity: <REDACTED>", switchCredentials.SNMPUsername)
+}
+
+// SwitchCommunity is the SNMPv2c community of a single management switch, for when it shouldn't use the default one.
+type SwitchCommunity struct {
+	Xname         string `json:"xname"`
+	SNMPCommunity string `json:"SNMPCommunity"`
+}
+
+func (switchCommunity SwitchCommunity) String() string {
+	return fmt.Sprintf("Xname: %s, SNMPCommunity: <REDACTED>", switchCommunity.Xname)
 }
 
 type RedsCredentials struct {
@@ -102,3 +116,34 @@ func (ccs *RedsCredStore) StoreDefaultSwitchCredentials(credentials SwitchCreden
 	}
 	return nil
 }
+
+func (ccs *RedsCredStore) switchCommunityKey(xname string) string {
+	return ccs.CCPath + "/switch_communities/" + xname
+}
+
+func (ccs *RedsCredStore) GetSwitchCommunity(xname string) (community SwitchCommunity, err error) {
+	err = ccs.SS.Lookup(ccs.switchCommunityKey(xname), &community)
+
+	return
+}
+
+func (ccs *RedsCredStore) StoreSwitchCommunity(community SwitchCommunity) error {
+	if community.Xname == "" {
+		return errors.New("empty xname")
+	}
+
+	err := ccs.SS.Store(ccs.switchCommunityKey(community.Xname), community)
+
+	if err != nil {
+		return errors.New("unable to store switch community: " + err.Error())
+	}
+	return nil
+}
+
+func (ccs *RedsCredStore) DeleteSwitchCommunity(xname string) error {
+	if xname == "" {
+		return errors.New("empty xname")
+	}
+
+	return ccs.SS.Delete(ccs.switchCommunityKey(xname))
+}